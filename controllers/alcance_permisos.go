@@ -0,0 +1,123 @@
+package controllers
+
+import (
+	"net/http"
+	"v1_prefabricadas/configs"
+	"v1_prefabricadas/models"
+	"v1_prefabricadas/services"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Permisos del usuario (o API key) autenticado. Normalmente ya los cargó RequirePermission en el contexto.
+// El super_administrador tiene todos los permisos y para él se devuelve nil.
+func permisosPropios(c *gin.Context) (map[string]bool, error) {
+	if esSuperAdministrador(c) {
+		return nil, nil
+	}
+	if permisos, ok := c.Get("permisos"); ok {
+		return permisos.(map[string]bool), nil
+	}
+	permisos, err := services.ObtenerPermisosUsuario(c.GetUint("usuarioID"))
+	if err != nil {
+		return nil, err
+	}
+	c.Set("permisos", permisos)
+	return permisos, nil
+}
+
+// Verificar que el usuario autenticado tenga todos los permisos indicados, para que no pueda otorgar
+// a otros (ni a sí mismo, a través de un rol) permisos que no tiene. Si no, responde 403 y devuelve false.
+func puedeOtorgarPermisos(c *gin.Context, permisos []string) bool {
+	propios, err := permisosPropios(c)
+	if err != nil {
+		HandleError(c, err, http.StatusInternalServerError, "No se pudo obtener los permisos")
+		return false
+	}
+	if propios == nil {
+		return true
+	}
+	for _, permiso := range permisos {
+		if !propios[permiso] {
+			HandleError(c, nil, http.StatusForbidden, "Acceso denegado: no puede otorgar el permiso "+permiso+" porque no lo tiene")
+			return false
+		}
+	}
+	return true
+}
+
+// Verificar que el usuario autenticado pueda asignar, quitar o modificar los roles indicados:
+// debe tener todos sus permisos y solo el super_administrador gestiona el rol super_administrador.
+// Si no, responde 403 y devuelve false.
+func puedeOtorgarRoles(c *gin.Context, rolIDs ...uint) bool {
+	if esSuperAdministrador(c) {
+		return true
+	}
+	permisos, superAdministrador, err := services.ObtenerPermisosRolesID(rolIDs)
+	if err != nil {
+		HandleError(c, err, http.StatusInternalServerError, "No se pudo obtener los permisos del Rol")
+		return false
+	}
+	if superAdministrador {
+		HandleError(c, nil, http.StatusForbidden, "Acceso denegado: solo un super_administrador puede gestionar ese Rol")
+		return false
+	}
+	return puedeOtorgarPermisos(c, permisos)
+}
+
+// Verificar que el usuario indicado pertenezca a la Empresa del usuario autenticado y no tenga roles
+// por encima de los suyos (por ejemplo, que un administrador no cambie la contraseña de un super_administrador).
+// Si no, responde 404 o 403 y devuelve false.
+func puedeGestionarUsuario(c *gin.Context, usuarioID uint) bool {
+	if esSuperAdministrador(c) {
+		return true
+	}
+	var count int64
+	if err := configs.DB.Model(&models.Usuario{}).
+		Where("id = ? AND empresa_id = ? AND deleted_at IS NULL", usuarioID, c.GetUint("empresaID")).
+		Count(&count).Error; err != nil {
+		HandleError(c, err, http.StatusInternalServerError, "Error al verificar el Usuario")
+		return false
+	}
+	if count == 0 {
+		HandleError(c, nil, http.StatusNotFound, "Usuario no encontrado")
+		return false
+	}
+	rolIDs, err := services.ObtenerRolesUsuario(usuarioID)
+	if err != nil {
+		HandleError(c, err, http.StatusInternalServerError, "No se pudo obtener los roles del Usuario")
+		return false
+	}
+	permisos, superAdministrador, err := services.ObtenerPermisosRolesID(rolIDs)
+	if err != nil {
+		HandleError(c, err, http.StatusInternalServerError, "No se pudo obtener los permisos del Usuario")
+		return false
+	}
+	propios, err := permisosPropios(c)
+	if err != nil {
+		HandleError(c, err, http.StatusInternalServerError, "No se pudo obtener los permisos")
+		return false
+	}
+	if superAdministrador {
+		HandleError(c, nil, http.StatusForbidden, "Acceso denegado: el Usuario tiene un rol superior al suyo")
+		return false
+	}
+	for _, permiso := range permisos {
+		if !propios[permiso] {
+			HandleError(c, nil, http.StatusForbidden, "Acceso denegado: el Usuario tiene un rol superior al suyo")
+			return false
+		}
+	}
+	return true
+}
+
+// Limitar una consulta sobre roles_usuarios a las asignaciones de usuarios de la Empresa del usuario
+// autenticado. El super_administrador ve las de todas las empresas.
+func rolesUsuariosDeEmpresa(c *gin.Context, query *gorm.DB) *gorm.DB {
+	if esSuperAdministrador(c) {
+		return query
+	}
+	return query.Where("usuario_id IN (?)", configs.DB.Model(&models.Usuario{}).
+		Select("id").Where("empresa_id = ?", c.GetUint("empresaID")))
+}
@@ -0,0 +1,151 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"v1_prefabricadas/configs"
+	"v1_prefabricadas/dto"
+	"v1_prefabricadas/models"
+	"v1_prefabricadas/services"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Función para crear una API key de la Empresa, la llave completa solo se muestra en esta respuesta
+func CrearApiKey(c *gin.Context) {
+	var request dto.CrearApiKeyRequest
+
+	idParamEmpresa := c.Param("empresaID")
+	empresaID, err := strconv.ParseUint(idParamEmpresa, 10, 64)
+	if err != nil {
+		HandleError(c, nil, http.StatusBadRequest, "ID Empresa inválido")
+		return
+	}
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		HandleError(c, err, http.StatusBadRequest, "Error de datos "+err.Error())
+		return
+	}
+
+	scopes, err := services.ValidarScopes(request.Scopes)
+	if err != nil {
+		HandleError(c, nil, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if request.ExpiresAt != nil && request.ExpiresAt.Before(time.Now()) {
+		HandleError(c, nil, http.StatusBadRequest, "La fecha de expiración debe ser futura")
+		return
+	}
+
+	apiKey := models.Api_key{
+		Nombre:      request.Nombre,
+		ExpiresAt:   request.ExpiresAt,
+		CreadoPorID: c.GetUint("usuarioID"),
+		EmpresaID:   uint(empresaID),
+	}
+	llave, err := services.CrearApiKey(configs.DB.WithContext(c), &apiKey, scopes)
+	if err != nil {
+		HandleError(c, err, http.StatusInternalServerError, "Error, no se pudo crear la API key")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "API key creada con éxito, guárdela ahora porque no se volverá a mostrar",
+		"api_key": llave,
+		"detalle": apiKeyResponse(apiKey),
+	})
+}
+
+// Función para obtener las API keys de una Empresa
+func ObtenerApiKeys(c *gin.Context) {
+	var apiKeys []models.Api_key
+	apiKeysResponse := []dto.ApiKeyResponse{}
+
+	idParamEmpresa := c.Param("empresaID")
+	empresaID, err := strconv.ParseUint(idParamEmpresa, 10, 64)
+	if err != nil {
+		HandleError(c, nil, http.StatusBadRequest, "ID Empresa inválido")
+		return
+	}
+
+	// Parámetros de paginación
+	paginacion, ok := obtenerPaginacion(c, 20, false)
+	if !ok {
+		return
+	}
+
+	if err := paginacion.paginar(configs.DB.Where("empresa_id = ?", empresaID), "created_at DESC, id DESC", "id", false, &apiKeys); err != nil {
+		HandleError(c, err, http.StatusInternalServerError, "Error al obtener las API keys")
+		return
+	}
+
+	for _, apiKey := range apiKeys {
+		apiKeysResponse = append(apiKeysResponse, apiKeyResponse(apiKey))
+	}
+
+	paginacion.responder(c, "api_keys", apiKeysResponse)
+}
+
+// Función para revocar una API key, deja de funcionar de inmediato
+func RevocarApiKey(c *gin.Context) {
+	var apiKey models.Api_key
+
+	idParamEmpresa := c.Param("empresaID")
+	empresaID, err := strconv.ParseUint(idParamEmpresa, 10, 64)
+	if err != nil {
+		HandleError(c, nil, http.StatusBadRequest, "ID Empresa inválido")
+		return
+	}
+
+	idParamApiKey := c.Param("apiKeyID")
+	apiKeyID, err := strconv.ParseUint(idParamApiKey, 10, 64)
+	if err != nil {
+		HandleError(c, nil, http.StatusBadRequest, "ID API key inválido")
+		return
+	}
+
+	if err := configs.DB.Where("empresa_id = ? AND id = ?", empresaID, apiKeyID).First(&apiKey).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			HandleError(c, nil, http.StatusNotFound, "API key no encontrada")
+			return
+		}
+		HandleError(c, err, http.StatusInternalServerError, "Error al obtener la API key")
+		return
+	}
+
+	if apiKey.RevokedAt != nil {
+		HandleError(c, nil, http.StatusBadRequest, "La API key ya se encuentra revocada")
+		return
+	}
+
+	now := time.Now()
+	apiKey.RevokedAt = &now
+	if err := configs.DB.WithContext(c).Save(&apiKey).Error; err != nil {
+		HandleError(c, err, http.StatusInternalServerError, "Error, no se pudo revocar la API key")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "API key revocada exitosamente",
+		"api_key": apiKeyResponse(apiKey),
+	})
+}
+
+func apiKeyResponse(apiKey models.Api_key) dto.ApiKeyResponse {
+	return dto.ApiKeyResponse{
+		ID:         apiKey.ID,
+		CreatedAt:  apiKey.CreatedAt,
+		Nombre:     apiKey.Nombre,
+		Prefijo:    apiKey.Prefijo,
+		Scopes:     strings.Split(apiKey.Scopes, ","),
+		ExpiresAt:  apiKey.ExpiresAt,
+		LastUsedAt: apiKey.LastUsedAt,
+		RevokedAt:  apiKey.RevokedAt,
+		EmpresaID:  apiKey.EmpresaID,
+	}
+}
@@ -0,0 +1,104 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+	"v1_prefabricadas/configs"
+	"v1_prefabricadas/dto"
+	"v1_prefabricadas/models"
+	"v1_prefabricadas/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Función para consultar el registro de auditoría con filtros y paginación
+func ObtenerAuditoria(c *gin.Context) {
+	var auditorias []models.Auditoria
+	auditoriasResponse := []dto.AuditoriaResponse{}
+
+	// Parámetros de paginación desde la solicitud
+	paginacion, ok := obtenerPaginacion(c, 50, true)
+	if !ok {
+		return
+	}
+
+	query := configs.DB.Model(&models.Auditoria{})
+
+	// Los usuarios que no son super administradores solo ven los cambios de su empresa
+	superAdmin := false
+	for _, rol := range c.GetStringSlice("roles") {
+		if rol == services.RolSuperAdministrador {
+			superAdmin = true
+		}
+	}
+	if !superAdmin {
+		query = query.Where("empresa_id = ?", c.GetUint("empresaID"))
+	} else if empresaID := c.Query("empresa_id"); empresaID != "" {
+		query = query.Where("empresa_id = ?", empresaID)
+	}
+
+	// Filtros opcionales
+	if usuarioID := c.Query("usuario_id"); usuarioID != "" {
+		query = query.Where("usuario_id = ?", usuarioID)
+	}
+	if apiKeyID := c.Query("api_key_id"); apiKeyID != "" {
+		query = query.Where("api_key_id = ?", apiKeyID)
+	}
+	if entidad := c.Query("entidad"); entidad != "" {
+		query = query.Where("entidad = ?", entidad)
+	}
+	if entidadID := c.Query("entidad_id"); entidadID != "" {
+		query = query.Where("entidad_id = ?", entidadID)
+	}
+	if accion := c.Query("accion"); accion != "" {
+		query = query.Where("accion = ?", accion)
+	}
+	for parametro, condicion := range map[string]string{"desde": "created_at >= ?", "hasta": "created_at <= ?"} {
+		valor := c.Query(parametro)
+		if valor == "" {
+			continue
+		}
+		fecha, err := time.Parse(time.RFC3339, valor)
+		if err != nil {
+			if fecha, err = time.Parse("2006-01-02", valor); err != nil {
+				HandleError(c, nil, http.StatusBadRequest, "Fecha '"+parametro+"' inválida, use YYYY-MM-DD o RFC3339")
+				return
+			}
+			if parametro == "hasta" {
+				fecha = fecha.Add(24*time.Hour - time.Nanosecond) // Incluir el día completo
+			}
+		}
+		query = query.Where(condicion, fecha)
+	}
+
+	if err := paginacion.paginar(query, "created_at DESC, id DESC", "id", true, &auditorias); err != nil {
+		HandleError(c, err, http.StatusInternalServerError, "Error al obtener los registros de auditoría")
+		return
+	}
+
+	for _, auditoria := range auditorias {
+		auditoriasResponse = append(auditoriasResponse, dto.AuditoriaResponse{
+			ID:        auditoria.ID,
+			CreatedAt: auditoria.CreatedAt,
+			UsuarioID: auditoria.UsuarioID,
+			ApiKeyID:  auditoria.ApiKeyID,
+			EmpresaID: auditoria.EmpresaID,
+			Entidad:   auditoria.Entidad,
+			EntidadID: auditoria.EntidadID,
+			Accion:    auditoria.Accion,
+			Antes:     jsonAuditoria(auditoria.Antes),
+			Despues:   jsonAuditoria(auditoria.Despues),
+			Ruta:      auditoria.Ruta,
+		})
+	}
+
+	paginacion.responder(c, "auditoria", auditoriasResponse)
+}
+
+func jsonAuditoria(valor *string) json.RawMessage {
+	if valor == nil {
+		return nil
+	}
+	return json.RawMessage(*valor)
+}
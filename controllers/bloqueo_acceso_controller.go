@@ -0,0 +1,86 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+	"v1_prefabricadas/configs"
+	"v1_prefabricadas/dto"
+	"v1_prefabricadas/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Función para obtener los bloqueos de acceso vigentes (por email o IP)
+func ObtenerBloqueosAcceso(c *gin.Context) {
+	var bloqueos []models.BloqueoAcceso
+	bloqueosResponse := []dto.BloqueoAccesoResponse{}
+
+	// Parámetros de paginación
+	paginacion, ok := obtenerPaginacion(c, 50, false)
+	if !ok {
+		return
+	}
+
+	query := configs.DB.Where("bloqueado_hasta > ?", time.Now())
+
+	// Filtros opcionales
+	if tipo := c.Query("tipo"); tipo != "" {
+		query = query.Where("tipo = ?", tipo)
+	}
+	if valor := c.Query("valor"); valor != "" {
+		query = query.Where("valor = ?", valor)
+	}
+
+	if err := paginacion.paginar(query, "bloqueado_hasta DESC, id DESC", "id", false, &bloqueos); err != nil {
+		HandleError(c, err, http.StatusInternalServerError, "Error al obtener los bloqueos de acceso")
+		return
+	}
+
+	for _, bloqueo := range bloqueos {
+		bloqueosResponse = append(bloqueosResponse, dto.BloqueoAccesoResponse{
+			ID:               bloqueo.ID,
+			Accion:           bloqueo.Accion,
+			Tipo:             bloqueo.Tipo,
+			Valor:            bloqueo.Valor,
+			IntentosFallidos: bloqueo.IntentosFallidos,
+			UltimoIntentoAt:  bloqueo.UltimoIntentoAt,
+			BloqueadoHasta:   bloqueo.BloqueadoHasta,
+		})
+	}
+
+	// Mostrar/enviar bloqueos vigentes
+	paginacion.responder(c, "bloqueos", bloqueosResponse)
+}
+
+// Función para desbloquear un acceso (reinicia el contador de intentos fallidos)
+func DesbloquearAcceso(c *gin.Context) {
+	var bloqueo models.BloqueoAcceso
+
+	idParamBloqueo := c.Param("bloqueoID")
+	bloqueoID, err := strconv.ParseUint(idParamBloqueo, 10, 64)
+	if err != nil {
+		HandleError(c, nil, http.StatusBadRequest, "ID Bloqueo inválido")
+		return
+	}
+
+	if err := configs.DB.First(&bloqueo, bloqueoID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			HandleError(c, nil, http.StatusNotFound, "Bloqueo no encontrado")
+			return
+		}
+		HandleError(c, err, http.StatusInternalServerError, "Error al obtener el bloqueo")
+		return
+	}
+
+	// Eliminar el registro deja la clave sin intentos fallidos
+	if err := configs.DB.WithContext(c).Delete(&bloqueo).Error; err != nil {
+		HandleError(c, err, http.StatusInternalServerError, "Error, no se pudo desbloquear el acceso")
+		return
+	}
+
+	// Mostrar/enviar mensaje de éxito
+	c.JSON(http.StatusOK, gin.H{"message": "Acceso desbloqueado exitosamente"})
+}
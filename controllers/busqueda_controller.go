@@ -0,0 +1,125 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"v1_prefabricadas/configs"
+	"v1_prefabricadas/dto"
+	"v1_prefabricadas/models"
+	"v1_prefabricadas/services"
+	"v1_prefabricadas/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+const largoFragmentoBusqueda = 160 // Largo máximo (en letras) de los fragmentos de textos extensos
+
+// Función para buscar Prefabricadas de una Empresa por texto libre (ej. "casa 3 dormitorios mediterránea"),
+// ordenadas por relevancia y con los fragmentos donde se encontraron las coincidencias
+func BuscarPrefabricadas(c *gin.Context) {
+	var prefabricadas []models.Prefabricada
+
+	idParamEmpresa := c.Param("empresaID")
+	empresaID, err := strconv.ParseUint(idParamEmpresa, 10, 64)
+	if err != nil {
+		HandleError(c, nil, http.StatusBadRequest, "ID Empresa inválido")
+		return
+	}
+
+	terminos := services.TerminosBusqueda(c.Query("q"))
+	if len(terminos) == 0 {
+		HandleError(c, nil, http.StatusBadRequest, "La búsqueda debe contener al menos una palabra de 3 o más letras")
+		return
+	}
+
+	// Obtener parámetros de paginación
+	paginacion, ok := obtenerPaginacion(c, 12, false)
+	if !ok {
+		return
+	}
+	// Este listado enviaba page, limit y total en la raíz de la respuesta
+	paginacion.metadatosEnRaiz = true
+
+	resultados, total, err := services.BuscarPrefabricadas(uint(empresaID), terminos, soloPublicados(c), paginacion.Limit, (paginacion.Page-1)*paginacion.Limit)
+	if err != nil {
+		if errors.Is(err, services.ErrBusquedaVacia) {
+			HandleError(c, nil, http.StatusBadRequest, "La búsqueda debe contener al menos una palabra de 3 o más letras")
+			return
+		}
+		HandleError(c, err, http.StatusInternalServerError, "Error al buscar Prefabricadas")
+		return
+	}
+
+	// Cargar las prefabricadas de la página respetando el orden por relevancia
+	ids := make([]uint, len(resultados))
+	for i, resultado := range resultados {
+		ids[i] = resultado.ID
+	}
+	if len(ids) > 0 {
+		if err := precargarPrefabricada(configs.DB).
+			Preload("Estilo").
+			Preload("Tipo").
+			Where("id IN ?", ids).
+			Find(&prefabricadas).Error; err != nil {
+			HandleError(c, err, http.StatusInternalServerError, "Error al obtener las Prefabricadas")
+			return
+		}
+	}
+	porID := make(map[uint]models.Prefabricada, len(prefabricadas))
+	for _, prefabricada := range prefabricadas {
+		porID[prefabricada.ID] = prefabricada
+	}
+
+	resultadosResponse := []dto.ResultadoBusquedaPrefabricadaResponse{}
+	for _, resultado := range resultados {
+		prefabricada, ok := porID[resultado.ID]
+		if !ok {
+			continue
+		}
+		resultadosResponse = append(resultadosResponse, dto.ResultadoBusquedaPrefabricadaResponse{
+			Relevancia:   resultado.Relevancia,
+			Destacados:   destacadosBusqueda(prefabricada, terminos),
+			Prefabricada: prefabricadaAResponse(prefabricada),
+		})
+	}
+
+	paginacion.Total = total
+	paginacion.responder(c, "resultados", resultadosResponse)
+}
+
+// Obtener los fragmentos de cada campo de la Prefabricada que coinciden con los términos buscados
+func destacadosBusqueda(prefabricada models.Prefabricada, terminos []string) map[string]string {
+	destacados := map[string]string{}
+
+	campos := []struct {
+		nombre string
+		texto  string
+		largo  int
+	}{
+		{"nombre_prefabricada", prefabricada.NombrePrefabricada, 0},
+		{"eslogan", prefabricada.Eslogan, largoFragmentoBusqueda},
+		{"descripcion", prefabricada.Descripcion, largoFragmentoBusqueda},
+		{"estilo", prefabricada.Estilo.NombreEstilo, 0},
+		{"tipo", prefabricada.Tipo.MaterialEstructura, 0},
+	}
+	for _, campo := range campos {
+		if fragmento, ok := utils.ResaltarCoincidencias(campo.texto, terminos, campo.largo); ok {
+			destacados[campo.nombre] = fragmento
+		}
+	}
+
+	// Las características se muestran como "clave: valor"
+	var caracteristicas []string
+	for _, caracteristica := range prefabricada.Caracteristica {
+		if fragmento, ok := utils.ResaltarCoincidencias(caracteristica.Clave+": "+caracteristica.Valor, terminos, 0); ok {
+			caracteristicas = append(caracteristicas, fragmento)
+		}
+	}
+	if len(caracteristicas) > 0 {
+		destacados["caracteristicas"] = strings.Join(caracteristicas, " · ")
+	}
+
+	return destacados
+}
@@ -4,7 +4,6 @@ import (
 	"errors"
 	"net/http"
 	"strconv"
-	"time"
 	"v1_prefabricadas/configs"
 	"v1_prefabricadas/dto"
 	"v1_prefabricadas/models"
@@ -38,7 +37,7 @@ func CrearCaracteristica(c *gin.Context) {
 	caracteristica.PrefabricadaID = uint(prefabricadaID)
 
 	// Guardamos en la base de datos
-	if err := configs.DB.Create(&caracteristica).Error; err != nil {
+	if err := configs.DB.WithContext(c).Create(&caracteristica).Error; err != nil {
 		HandleError(c, err, http.StatusInternalServerError, "Error al guardar las características")
 		return
 	}
@@ -60,7 +59,7 @@ func CrearCaracteristica(c *gin.Context) {
 // Función para obtener todas las Caracteristicas
 func ObtenerCaracteristicas(c *gin.Context) {
 	var caracteristicas []models.Caracteristica
-	var caracteristicasResponse []dto.CaracteristicaResponse
+	caracteristicasResponse := []dto.CaracteristicaResponse{}
 
 	idParamPrefabricada := c.Param("prefabricadaID")
 	prefabricadaID, err := strconv.ParseUint(idParamPrefabricada, 10, 64)
@@ -69,8 +68,14 @@ func ObtenerCaracteristicas(c *gin.Context) {
 		return
 	}
 
+	// Parámetros de paginación
+	paginacion, ok := obtenerPaginacion(c, limiteMaximoPaginacion, false)
+	if !ok {
+		return
+	}
+
 	// Buscar las características en la base de datos
-	if err := configs.DB.Where("prefabricada_id = ?", prefabricadaID).Where("deleted_at IS NULL").Find(&caracteristicas).Error; err != nil {
+	if err := paginacion.paginar(configs.DB.Where("prefabricada_id = ?", prefabricadaID).Where("deleted_at IS NULL"), "id ASC", "id", false, &caracteristicas); err != nil {
 		HandleError(c, err, http.StatusInternalServerError, "Características no encontradas")
 		return
 	}
@@ -87,7 +92,7 @@ func ObtenerCaracteristicas(c *gin.Context) {
 	}
 
 	// Enviar response
-	c.JSON(http.StatusOK, gin.H{"caracteristicas": caracteristicasResponse})
+	paginacion.responder(c, "caracteristicas", caracteristicasResponse)
 }
 
 // Función para obtener característica de acuerdo al ID
@@ -173,7 +178,7 @@ func ActualizarCaracteristica(c *gin.Context) {
 	caracteristica.Valor = request.Valor
 
 	// Guardamos los cambios en la Base de datos
-	if err := configs.DB.Save(&caracteristica).Error; err != nil {
+	if err := configs.DB.WithContext(c).Save(&caracteristica).Error; err != nil {
 		HandleError(c, err, http.StatusInternalServerError, "No se pudo actualizar la Característica")
 		return
 	}
@@ -226,13 +231,8 @@ func EliminarCaracteristica(c *gin.Context) {
 		return
 	}
 
-	// Guardar fecha y hora de la eliminación lógica
-	now := time.Now()
-	caracteristica.DeletedAt = &now
-
-	// Guardamos la fecha y hora de la eliminación lógica en la base de datos
-	if err := configs.DB.Save(&caracteristica).Error; err != nil {
-		HandleError(c, err, http.StatusInternalServerError, "Error, no se pudo eliminar Característica")
+	// Eliminar lógicamente y registrar en la papelera
+	if !enviarAPapelera(c, "caracteristicas", caracteristica.ID, "Error, no se pudo eliminar Característica") {
 		return
 	}
 
@@ -2,11 +2,10 @@ package controllers
 
 import (
 	"net/http"
-	"strconv"
-	"time"
 	"v1_prefabricadas/configs"
 	"v1_prefabricadas/dto"
 	"v1_prefabricadas/models"
+	"v1_prefabricadas/services"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
@@ -28,12 +27,19 @@ func CrearCategoria(c *gin.Context) {
 		return
 	}
 
+	// Slug indicado o generado desde el nombre
+	slug, ok := slugParaGuardar(c, services.EntidadSlugCategoria, 0, 0, nil, request.Slug, request.NombreCategoria, true)
+	if !ok {
+		return
+	}
+
 	// Creación de Categoria
 	categoria := models.Categoria{
+		Slug:                 &slug,
 		NombreCategoria:      request.NombreCategoria,
 		DescripcionCategoria: request.DescripcionCategoria,
 	}
-	if err := configs.DB.Create(&categoria).Error; err != nil {
+	if err := configs.DB.WithContext(c).Create(&categoria).Error; err != nil {
 		handleErrorCategoria(c, err, http.StatusInternalServerError, "No se pudo crear la Categoria")
 		return
 	}
@@ -45,7 +51,7 @@ func CrearCategoria(c *gin.Context) {
 			TipoID:      tipo_categoriaReq.TipoID,
 		}
 
-		if err := configs.DB.Create(&tipo_categoria).Error; err != nil {
+		if err := configs.DB.WithContext(c).Create(&tipo_categoria).Error; err != nil {
 			handleErrorCategoria(c, err, http.StatusInternalServerError, "No se pudo crear Tipo_Categoria")
 			return
 		}
@@ -60,6 +66,7 @@ func CrearCategoria(c *gin.Context) {
 		ID:                   categoria.ID,
 		CreatedAt:            categoria.CreatedAt,
 		UpdatedAt:            categoria.UpdatedAt,
+		Slug:                 valorSlug(categoria.Slug),
 		NombreCategoria:      categoria.NombreCategoria,
 		DescripcionCategoria: categoria.DescripcionCategoria,
 		Tipos:                tiposResponse,
@@ -72,14 +79,18 @@ func CrearCategoria(c *gin.Context) {
 // Función para obtener todas las Categorias
 func ObtenerCategorias(c *gin.Context) {
 	var categorias []models.Categoria
-	var categoriasResponse []dto.CategoriaResponse
+	categoriasResponse := []dto.CategoriaResponse{}
+
+	// Parámetros de paginación
+	paginacion, ok := obtenerPaginacion(c, limiteMaximoPaginacion, false)
+	if !ok {
+		return
+	}
 
 	//Obtener todas las Categorias
-	if err := configs.DB.
+	if err := paginacion.paginar(configs.DB.
 		Where("deleted_at IS NULL").
-		Preload("Tipo_categoria.Tipo").
-		Find(&categorias).
-		Error; err != nil {
+		Preload("Tipo_categoria.Tipo"), "id ASC", "id", false, &categorias); err != nil {
 		handleErrorCategoria(c, err, http.StatusInternalServerError, "No se pudieron obtener las Categorias")
 		return
 	}
@@ -101,12 +112,13 @@ func ObtenerCategorias(c *gin.Context) {
 			ID:                   categoria.ID,
 			CreatedAt:            categoria.CreatedAt,
 			UpdatedAt:            categoria.UpdatedAt,
+			Slug:                 valorSlug(categoria.Slug),
 			NombreCategoria:      categoria.NombreCategoria,
 			DescripcionCategoria: categoria.DescripcionCategoria,
 			Tipos:                tipoCategoria,
 		})
 	}
-	c.JSON(http.StatusOK, gin.H{"categorias": categoriasResponse})
+	paginacion.responder(c, "categorias", categoriasResponse)
 }
 
 // Función para obtener una Categoria
@@ -114,10 +126,9 @@ func ObtenerCategoria(c *gin.Context) {
 	var categoria models.Categoria
 	var categoriasResponse dto.CategoriaResponse
 	var tipoCategoria []dto.Tipo_categoriaResponse
-	idParam := c.Param("id")
-	id, err := strconv.ParseUint(idParam, 10, 64) // Convertir ID a uint
-	if err != nil {
-		handleErrorEstilo(c, nil, http.StatusBadRequest, "ID inválido")
+	// La Categoria se puede obtener por su ID o por su slug
+	id, ok := resolverIDOSlug(c, "id", services.EntidadSlugCategoria, 0, "Categoria no encontrado")
+	if !ok {
 		return
 	}
 
@@ -148,6 +159,7 @@ func ObtenerCategoria(c *gin.Context) {
 		ID:                   categoria.ID,
 		CreatedAt:            categoria.CreatedAt,
 		UpdatedAt:            categoria.UpdatedAt,
+		Slug:                 valorSlug(categoria.Slug),
 		NombreCategoria:      categoria.NombreCategoria,
 		DescripcionCategoria: categoria.DescripcionCategoria,
 		Tipos:                tipoCategoria,
@@ -175,12 +187,26 @@ func ActualizarCategoria(c *gin.Context) {
 		return
 	}
 
+	// Al cambiar el nombre se genera un nuevo slug, salvo que se indique uno
+	slugAnterior := categoria.Slug
+	slug, ok := slugParaGuardar(c, services.EntidadSlugCategoria, 0, categoria.ID, slugAnterior, request.Slug, request.NombreCategoria, request.NombreCategoria != categoria.NombreCategoria)
+	if !ok {
+		return
+	}
+
 	// Actualizar datos de la categoría
+	categoria.Slug = &slug
 	categoria.NombreCategoria = request.NombreCategoria
 	categoria.DescripcionCategoria = request.DescripcionCategoria
 
-	// Guardar cambios de la categoría en la base de datos
-	if err := configs.DB.Save(&categoria).Error; err != nil {
+	// Guardar cambios de la categoría en la base de datos, conservando el slug anterior para redirigir las URLs antiguas
+	err := configs.DB.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&categoria).Error; err != nil {
+			return err
+		}
+		return services.RegistrarCambioSlug(tx, services.EntidadSlugCategoria, 0, categoria.ID, slugAnterior, slug)
+	})
+	if err != nil {
 		handleErrorCategoria(c, err, http.StatusInternalServerError, "No se pudo actualizar la categoría")
 		return
 	}
@@ -199,7 +225,7 @@ func ActualizarCategoria(c *gin.Context) {
 				CategoriaID: categoria.ID,
 				TipoID:      tipoReq.TipoID,
 			}
-			if err := configs.DB.Create(&nuevoTipo).Error; err != nil {
+			if err := configs.DB.WithContext(c).Create(&nuevoTipo).Error; err != nil {
 				handleErrorCategoria(c, err, http.StatusInternalServerError, "Error al agregar tipo")
 				return
 			}
@@ -210,7 +236,7 @@ func ActualizarCategoria(c *gin.Context) {
 
 	// Eliminar los tipos que no se incluyeron en la solicitud
 	for _, tipo := range existingTipos {
-		if err := configs.DB.Delete(&tipo).Error; err != nil {
+		if err := configs.DB.WithContext(c).Delete(&tipo).Error; err != nil {
 			handleErrorCategoria(c, err, http.StatusInternalServerError, "Error al eliminar tipo")
 			return
 		}
@@ -236,6 +262,7 @@ func ActualizarCategoria(c *gin.Context) {
 		ID:                   categoria.ID,
 		CreatedAt:            categoria.CreatedAt,
 		UpdatedAt:            categoria.UpdatedAt,
+		Slug:                 valorSlug(categoria.Slug),
 		NombreCategoria:      categoria.NombreCategoria,
 		DescripcionCategoria: categoria.DescripcionCategoria,
 		Tipos:                tiposResponse,
@@ -262,13 +289,8 @@ func EliminarCategoria(c *gin.Context) {
 		return
 	}
 
-	// Establecer la fecha de eliminación lógica
-	now := time.Now()
-	categoria.DeletedAt = &now
-
-	// Actualizar el registro del usuario en la base de datos
-	if err := configs.DB.Save(&categoria).Error; err != nil {
-		handleErrorCategoria(c, err, http.StatusInternalServerError, "No se pudo eliminar la Categoría")
+	// Eliminar lógicamente junto con sus vínculos con tipos, siempre que ninguna prefabricada la use
+	if !eliminarCatalogoGlobal(c, "categorias", categoria.ID, "No se pudo eliminar la Categoría") {
 		return
 	}
 
@@ -0,0 +1,107 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"v1_prefabricadas/dto"
+	"v1_prefabricadas/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Función para obtener la configuración de seguridad del sistema
+func ObtenerConfiguracionSeguridad(c *gin.Context) {
+	configuracion, err := configuracionSeguridad()
+	if err != nil {
+		HandleError(c, err, http.StatusInternalServerError, "Error al obtener la configuración de seguridad")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"configuracion": configuracion})
+}
+
+// Función para actualizar la configuración de seguridad del sistema
+func ActualizarConfiguracionSeguridad(c *gin.Context) {
+	var request dto.ConfiguracionSeguridadRequest
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		HandleError(c, err, http.StatusBadRequest, "Error de datos "+err.Error())
+		return
+	}
+
+	if request.DosFactoresObligatorioSuperAdmin != nil {
+		if err := services.GuardarConfiguracion(services.ConfigDosFactoresSuperAdmin, strconv.FormatBool(*request.DosFactoresObligatorioSuperAdmin)); err != nil {
+			HandleError(c, err, http.StatusInternalServerError, "Error, no se pudo guardar la configuración de seguridad")
+			return
+		}
+	}
+
+	if request.PoliticaPassword != nil {
+		politica, err := services.ObtenerPoliticaPassword()
+		if err != nil {
+			HandleError(c, err, http.StatusInternalServerError, "Error al obtener la política de contraseñas")
+			return
+		}
+
+		// Aplicar solo los campos enviados
+		cambios := request.PoliticaPassword
+		if cambios.LongitudMinima != nil {
+			politica.LongitudMinima = *cambios.LongitudMinima
+		}
+		if cambios.RequiereMayuscula != nil {
+			politica.RequiereMayuscula = *cambios.RequiereMayuscula
+		}
+		if cambios.RequiereMinuscula != nil {
+			politica.RequiereMinuscula = *cambios.RequiereMinuscula
+		}
+		if cambios.RequiereNumero != nil {
+			politica.RequiereNumero = *cambios.RequiereNumero
+		}
+		if cambios.RequiereSimbolo != nil {
+			politica.RequiereSimbolo = *cambios.RequiereSimbolo
+		}
+		if cambios.Historial != nil {
+			politica.Historial = *cambios.Historial
+		}
+
+		if err := services.GuardarPoliticaPassword(politica); err != nil {
+			HandleError(c, err, http.StatusInternalServerError, "Error, no se pudo guardar la política de contraseñas")
+			return
+		}
+	}
+
+	configuracion, err := configuracionSeguridad()
+	if err != nil {
+		HandleError(c, err, http.StatusInternalServerError, "Error al obtener la configuración de seguridad")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":       "Configuración de seguridad actualizada exitosamente",
+		"configuracion": configuracion,
+	})
+}
+
+func configuracionSeguridad() (dto.ConfiguracionSeguridadResponse, error) {
+	dosFactores, err := services.ObtenerConfiguracionBool(services.ConfigDosFactoresSuperAdmin, false)
+	if err != nil {
+		return dto.ConfiguracionSeguridadResponse{}, err
+	}
+
+	politica, err := services.ObtenerPoliticaPassword()
+	if err != nil {
+		return dto.ConfiguracionSeguridadResponse{}, err
+	}
+
+	return dto.ConfiguracionSeguridadResponse{
+		DosFactoresObligatorioSuperAdmin: dosFactores,
+		PoliticaPassword: dto.PoliticaPasswordResponse{
+			LongitudMinima:    politica.LongitudMinima,
+			RequiereMayuscula: politica.RequiereMayuscula,
+			RequiereMinuscula: politica.RequiereMinuscula,
+			RequiereNumero:    politica.RequiereNumero,
+			RequiereSimbolo:   politica.RequiereSimbolo,
+			Historial:         politica.Historial,
+		},
+	}, nil
+}
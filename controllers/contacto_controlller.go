@@ -5,7 +5,6 @@ import (
 	"fmt"
 	"net/http"
 	"strconv"
-	"time"
 	"v1_prefabricadas/configs"
 	"v1_prefabricadas/dto"
 	"v1_prefabricadas/models"
@@ -40,7 +39,7 @@ func CrearContacto(c *gin.Context) {
 	contacto.UsuarioID = uint(usuarioID)
 
 	// Guardar datos de Contacto en la base de datos
-	if err := configs.DB.Create(&contacto).Error; err != nil {
+	if err := configs.DB.WithContext(c).Create(&contacto).Error; err != nil {
 		HandleError(c, err, http.StatusInternalServerError, "Error, no se pudo guardar los datos de Contacto")
 		return
 	}
@@ -66,7 +65,7 @@ func CrearContacto(c *gin.Context) {
 // Función para obtener todos los contactos de un Usuario
 func ObtenerContactos(c *gin.Context) {
 	var contactos []models.Contacto
-	var contactoResponse []dto.ContactoResponse
+	contactoResponse := []dto.ContactoResponse{}
 
 	idParamUsuario := c.Param("usuarioID")
 	usuarioID, err := strconv.ParseUint(idParamUsuario, 10, 64)
@@ -75,15 +74,15 @@ func ObtenerContactos(c *gin.Context) {
 		return
 	}
 
-	// Buscar los Contactos del Usuario en la base de datos de acuerdo al ID del Usuario
-	if err := configs.DB.Where("usuario_id = ?", usuarioID).Where("deleted_at IS NULL").Find(&contactos).Error; err != nil {
-		HandleError(c, err, http.StatusInternalServerError, "Error al obtener datos de Contacto")
+	// Parámetros de paginación
+	paginacion, ok := obtenerPaginacion(c, limiteMaximoPaginacion, false)
+	if !ok {
 		return
 	}
 
-	// Devolver notFound en caso de que no existan Contactos
-	if len(contactos) == 0 {
-		HandleError(c, nil, http.StatusNotFound, "Datos de Contacto no encontrados")
+	// Buscar los Contactos del Usuario en la base de datos de acuerdo al ID del Usuario
+	if err := paginacion.paginar(configs.DB.Where("usuario_id = ?", usuarioID).Where("deleted_at IS NULL"), "id ASC", "id", false, &contactos); err != nil {
+		HandleError(c, err, http.StatusInternalServerError, "Error al obtener datos de Contacto")
 		return
 	}
 
@@ -100,9 +99,7 @@ func ObtenerContactos(c *gin.Context) {
 	}
 
 	// Mostrar/enviar Contactos de Usuario
-	c.JSON(http.StatusOK, gin.H{
-		"contactos": contactoResponse,
-	})
+	paginacion.responder(c, "contactos", contactoResponse)
 
 }
 
@@ -193,7 +190,7 @@ func ActualizarContacto(c *gin.Context) {
 	contacto.DireccionLaboral = request.DireccionLaboral
 
 	// Guardar los datos actualizados en la Base de datos
-	if err := configs.DB.Save(&contacto).Error; err != nil {
+	if err := configs.DB.WithContext(c).Save(&contacto).Error; err != nil {
 		HandleError(c, err, http.StatusInternalServerError, "Error, no se pudo actualizar los datos de Contacto")
 		return
 	}
@@ -250,13 +247,8 @@ func EliminarContacto(c *gin.Context) {
 		return
 	}
 
-	// Poner fecha y hora de la eliminación lógica
-	now := time.Now()
-	contacto.DeletedAt = &now
-
-	// Guardar eliminación lógica de la eliminación lógica
-	if err := configs.DB.Save(&contacto).Error; err != nil {
-		HandleError(c, err, http.StatusInternalServerError, "Error, no se pudo eliminar Datos de Contacto")
+	// Eliminar lógicamente y registrar en la papelera
+	if !enviarAPapelera(c, "contactos", contacto.ID, "Error, no se pudo eliminar Datos de Contacto") {
 		return
 	}
 
@@ -4,10 +4,12 @@ import (
 	"errors"
 	"net/http"
 	"strconv"
+	"strings"
 	"v1_prefabricadas/configs"
 	"v1_prefabricadas/dto"
 	"v1_prefabricadas/models"
 	"v1_prefabricadas/services"
+	"v1_prefabricadas/utils"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
@@ -26,6 +28,11 @@ func CrearCredencial(c *gin.Context) {
 		return
 	}
 
+	// No se gestionan las credenciales de usuarios con un rol superior
+	if !puedeGestionarUsuario(c, uint(usuarioID)) {
+		return
+	}
+
 	if err := c.ShouldBindJSON(&request); err != nil {
 		HandleError(c, nil, http.StatusBadRequest, "Error de datos "+err.Error())
 		return
@@ -42,19 +49,10 @@ func CrearCredencial(c *gin.Context) {
 		return
 	}
 
-	// Iniciar una transacción para crear las credenciales
-	tx := configs.DB.Begin()
-
-	// Hashear la contraseña
-	hashedpassword, err := services.HashPassword(request.Password)
-	if err != nil {
-		tx.Rollback()
-		HandleError(c, err, http.StatusInternalServerError, "Error, no se pudo hashear la contraseña")
-		return
-	}
+	// Crear las credenciales sin contraseña junto con la invitación en una transacción
+	tx := configs.DB.WithContext(c).Begin()
 
 	credencial.Email = request.Email
-	credencial.Password = hashedpassword
 	credencial.UsuarioID = uint(usuarioID)
 
 	if err := tx.Create(&credencial).Error; err != nil {
@@ -63,18 +61,29 @@ func CrearCredencial(c *gin.Context) {
 		return
 	}
 
+	token, err := services.CrearInvitacion(tx, credencial.ID)
+	if err != nil {
+		tx.Rollback()
+		HandleError(c, err, http.StatusInternalServerError, "Error, no se pudo generar la invitación")
+		return
+	}
+
 	tx.Commit()
 
+	// El usuario define su contraseña y confirma su email desde el enlace de la invitación
+	go utils.EnviarEmailInvitacion(credencial.Email, services.URLInvitacion(token))
+
 	credencialResponse = dto.CredencialResponse{
-		ID:        credencial.ID,
-		CreatedAt: credencial.CreatedAt,
-		UpdatedAt: credencial.UpdatedAt,
-		Email:     credencial.Email,
-		UsuarioID: credencial.UsuarioID,
+		ID:              credencial.ID,
+		CreatedAt:       credencial.CreatedAt,
+		UpdatedAt:       credencial.UpdatedAt,
+		Email:           credencial.Email,
+		EmailVerifiedAt: credencial.EmailVerifiedAt,
+		UsuarioID:       credencial.UsuarioID,
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"message":    "Credenciales de acceso creadas con éxito",
+		"message":    "Credenciales de acceso creadas con éxito, se envió la invitación al email indicado",
 		"credencial": credencialResponse,
 	})
 }
@@ -102,11 +111,12 @@ func ObtenerCredenciales(c *gin.Context) {
 	}
 
 	credencialesResponse = dto.CredencialResponse{
-		ID:        credenciales.ID,
-		CreatedAt: credenciales.CreatedAt,
-		UpdatedAt: credenciales.UpdatedAt,
-		Email:     credenciales.Email,
-		UsuarioID: credenciales.UsuarioID,
+		ID:              credenciales.ID,
+		CreatedAt:       credenciales.CreatedAt,
+		UpdatedAt:       credenciales.UpdatedAt,
+		Email:           credenciales.Email,
+		EmailVerifiedAt: credenciales.EmailVerifiedAt,
+		UsuarioID:       credenciales.UsuarioID,
 	}
 
 	// Enviar mensaje de éxito
@@ -129,6 +139,11 @@ func ActualizarCredenciales(c *gin.Context) {
 		return
 	}
 
+	// No se gestionan las credenciales de usuarios con un rol superior
+	if !puedeGestionarUsuario(c, uint(usuarioID)) {
+		return
+	}
+
 	idParamCredencial := c.Param("credencialID")
 	credencialID, err := strconv.ParseUint(idParamCredencial, 10, 64)
 	if err != nil {
@@ -143,7 +158,7 @@ func ActualizarCredenciales(c *gin.Context) {
 	}
 
 	// Iniciar transacción
-	tx := configs.DB.Begin()
+	tx := configs.DB.WithContext(c).Begin()
 
 	// Buscar credencial
 	if err := tx.Where("usuario_id = ? AND id = ?", usuarioID, credencialID).First(&credencial).Error; err != nil {
@@ -157,24 +172,32 @@ func ActualizarCredenciales(c *gin.Context) {
 	}
 
 	// Validar y actualizar el email
-	if request.Email != "" {
-		credencial.Email = request.Email
-	} else {
+	if request.Email == "" {
 		tx.Rollback()
 		HandleError(c, nil, http.StatusBadRequest, "El campo Email no debe estar vacío")
 		return
 	}
 
-	// Validar y actualizar la contraseña solo si se proporciona
-	if request.Password != "" {
-		hashedPassword, err := services.HashPassword(request.Password)
+	// Un email nuevo debe confirmarse: se marca sin verificar y se envía una invitación a la nueva dirección
+	var token string
+	if !strings.EqualFold(credencial.Email, request.Email) {
+		credencial.EmailVerifiedAt = nil
+
+		token, err = services.CrearInvitacion(tx, credencial.ID)
 		if err != nil {
 			tx.Rollback()
-			HandleError(c, err, http.StatusInternalServerError, "Error al hashear la nueva contraseña")
+			HandleError(c, err, http.StatusInternalServerError, "Error, no se pudo generar la invitación")
+			return
+		}
+
+		// Hasta confirmar el email nuevo la cuenta no puede iniciar sesión, se cierran las sesiones abiertas
+		if err := services.RevocarSesionesUsuario(tx, credencial.UsuarioID); err != nil {
+			tx.Rollback()
+			HandleError(c, err, http.StatusInternalServerError, "Error al cerrar las sesiones del usuario")
 			return
 		}
-		credencial.Password = hashedPassword
 	}
+	credencial.Email = request.Email
 
 	// Guardar actualización en la base de datos
 	if err := tx.Save(&credencial).Error; err != nil {
@@ -186,13 +209,18 @@ func ActualizarCredenciales(c *gin.Context) {
 	// Confirmar la transacción
 	tx.Commit()
 
+	if token != "" {
+		go utils.EnviarEmailInvitacion(credencial.Email, services.URLInvitacion(token))
+	}
+
 	// Preparar la respuesta
 	credencialResponse = dto.CredencialResponse{
-		ID:        credencial.ID,
-		CreatedAt: credencial.CreatedAt,
-		UpdatedAt: credencial.UpdatedAt,
-		Email:     credencial.Email,
-		UsuarioID: credencial.UsuarioID,
+		ID:              credencial.ID,
+		CreatedAt:       credencial.CreatedAt,
+		UpdatedAt:       credencial.UpdatedAt,
+		Email:           credencial.Email,
+		EmailVerifiedAt: credencial.EmailVerifiedAt,
+		UsuarioID:       credencial.UsuarioID,
 	}
 
 	// Enviar mensaje de éxito
@@ -0,0 +1,395 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+	"v1_prefabricadas/configs"
+	"v1_prefabricadas/dto"
+	"v1_prefabricadas/models"
+	"v1_prefabricadas/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v4"
+	"gorm.io/gorm"
+)
+
+// Propósitos del token de desafío entregado por el login
+const (
+	propositoDosFactores        = "2fa"
+	propositoEnrolarDosFactores = "enrolar_2fa"
+	duracionDesafio             = 5 * time.Minute
+)
+
+// Claims del token de desafío: solo sirve para completar el segundo factor, no como access token
+type DesafioClaims struct {
+	UsuarioID uint   `json:"usuario_id"`
+	Proposito string `json:"proposito"`
+	jwt.RegisteredClaims
+}
+
+// Determinar si el login requiere verificar el segundo factor o enrolarlo de forma obligatoria
+func propositoDesafioLogin(usuario models.Usuario) (string, error) {
+	if usuario.Credencial.TOTPHabilitadoAt != nil {
+		return propositoDosFactores, nil
+	}
+
+	obligatorio, err := dosFactoresObligatorio(usuario)
+	if err != nil {
+		return "", err
+	}
+	if obligatorio {
+		return propositoEnrolarDosFactores, nil
+	}
+	return "", nil
+}
+
+// El segundo factor es obligatorio para el super_administrador si así se configura
+func dosFactoresObligatorio(usuario models.Usuario) (bool, error) {
+	for _, rol := range obtenerNombresRoles(usuario) {
+		if rol == services.RolSuperAdministrador {
+			return services.ObtenerConfiguracionBool(services.ConfigDosFactoresSuperAdmin, false)
+		}
+	}
+	return false, nil
+}
+
+// Responder al login con un token de desafío de corta duración
+func responderDesafio(c *gin.Context, usuarioID uint, proposito string) {
+	claims := &DesafioClaims{
+		UsuarioID: usuarioID,
+		Proposito: proposito,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(duracionDesafio)),
+			Issuer:    services.EmisorToken,
+		},
+	}
+
+	desafio, err := services.FirmarToken(claims)
+	if err != nil {
+		HandleError(c, err, http.StatusInternalServerError, "No se pudo generar el token de desafío")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"requiere_2fa":         proposito == propositoDosFactores,
+		"requiere_enrolar_2fa": proposito == propositoEnrolarDosFactores,
+		"challenge_token":      desafio,
+		"expires_in":           int(duracionDesafio.Seconds()),
+	})
+}
+
+// Validar el token de desafío y cargar el usuario con su credencial y roles
+func usuarioDesdeDesafio(c *gin.Context, desafio string, proposito string) (models.Usuario, bool) {
+	var usuario models.Usuario
+
+	claims := &DesafioClaims{}
+	if err := services.VerificarToken(desafio, claims); err != nil || claims.Proposito != proposito {
+		HandleError(c, nil, http.StatusUnauthorized, "Token de desafío inválido o expirado")
+		return usuario, false
+	}
+
+	if err := configs.DB.
+		Preload("Credencial").
+		Preload("Rol_usuario", "deleted_at IS NULL").
+		Preload("Rol_usuario.Rol").
+		Where("deleted_at IS NULL").
+		First(&usuario, claims.UsuarioID).Error; err != nil || usuario.Credencial == nil {
+		HandleError(c, nil, http.StatusUnauthorized, "Usuario no encontrado")
+		return usuario, false
+	}
+
+	return usuario, true
+}
+
+// Cargar la credencial del usuario autenticado
+func credencialUsuarioAutenticado(c *gin.Context) (models.Credencial, bool) {
+	var credencial models.Credencial
+	if err := configs.DB.Where("usuario_id = ?", c.GetUint("usuarioID")).First(&credencial).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			HandleError(c, nil, http.StatusNotFound, "Credenciales no encontradas")
+			return credencial, false
+		}
+		HandleError(c, err, http.StatusInternalServerError, "Error al obtener credenciales")
+		return credencial, false
+	}
+	return credencial, true
+}
+
+// Generar un nuevo secreto (aún no habilitado) y devolver los datos para la aplicación autenticadora
+func iniciarEnrolamiento(c *gin.Context, credencial models.Credencial) {
+	if credencial.TOTPHabilitadoAt != nil {
+		HandleError(c, nil, http.StatusConflict, "La autenticación de dos factores ya está habilitada")
+		return
+	}
+
+	secreto, err := services.GenerarSecretoTOTP()
+	if err != nil {
+		HandleError(c, err, http.StatusInternalServerError, "No se pudo generar el secreto")
+		return
+	}
+
+	if err := configs.DB.Model(&credencial).Updates(map[string]interface{}{
+		"totp_secreto":     secreto,
+		"totp_ultimo_paso": 0,
+	}).Error; err != nil {
+		HandleError(c, err, http.StatusInternalServerError, "No se pudo guardar el secreto")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"enrolamiento": dto.EnrolamientoDosFactoresResponse{
+			Secreto: secreto,
+			URI:     services.URIProvisionamientoTOTP(secreto, credencial.Email),
+		},
+	})
+}
+
+// Confirmar el enrolamiento con un código válido, habilitar el segundo factor y generar los códigos de recuperación
+func completarEnrolamiento(c *gin.Context, credencial *models.Credencial, codigo string) ([]string, bool) {
+	if credencial.TOTPHabilitadoAt != nil {
+		HandleError(c, nil, http.StatusConflict, "La autenticación de dos factores ya está habilitada")
+		return nil, false
+	}
+	if credencial.TOTPSecreto == "" {
+		HandleError(c, nil, http.StatusBadRequest, "Debe iniciar el enrolamiento antes de confirmarlo")
+		return nil, false
+	}
+
+	paso, ok := services.VerificarCodigoTOTP(credencial.TOTPSecreto, codigo, credencial.TOTPUltimoPaso)
+	if !ok {
+		HandleError(c, nil, http.StatusUnauthorized, "Código inválido")
+		return nil, false
+	}
+
+	var codigos []string
+	err := configs.DB.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		if err := tx.Model(credencial).Updates(map[string]interface{}{
+			"totp_habilitado_at": &now,
+			"totp_ultimo_paso":   paso,
+		}).Error; err != nil {
+			return err
+		}
+
+		var err error
+		codigos, err = services.GenerarCodigosRecuperacion(tx, credencial.ID)
+		return err
+	})
+	if err != nil {
+		HandleError(c, err, http.StatusInternalServerError, "No se pudo habilitar la autenticación de dos factores")
+		return nil, false
+	}
+
+	return codigos, true
+}
+
+// VerificarLogin2FA: Segundo paso del login, valida el código y entrega la sesión
+func VerificarLogin2FA(c *gin.Context) {
+	var request dto.VerificarDosFactoresRequest
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		HandleError(c, err, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	usuario, ok := usuarioDesdeDesafio(c, request.ChallengeToken, propositoDosFactores)
+	if !ok {
+		return
+	}
+
+	// Limitar los intentos de adivinar el código
+	if accesoBloqueado(c, services.AccionDosFactores, usuario.Credencial.Email) {
+		return
+	}
+
+	valido, err := services.VerificarSegundoFactor(usuario.Credencial, request.Codigo)
+	if err != nil {
+		HandleError(c, err, http.StatusInternalServerError, "No se pudo verificar el código")
+		return
+	}
+	if !valido {
+		registrarIntentoFallido(c, services.AccionDosFactores, usuario.Credencial.Email)
+		HandleError(c, nil, http.StatusUnauthorized, "Código inválido")
+		return
+	}
+
+	limpiarIntentos(services.AccionDosFactores, usuario.Credencial.Email)
+	emitirSesion(c, usuario, nil)
+}
+
+// EnrolarLogin2FA: Iniciar el enrolamiento obligatorio con el token de desafío del login
+func EnrolarLogin2FA(c *gin.Context) {
+	var request dto.EnrolarDosFactoresLoginRequest
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		HandleError(c, err, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	usuario, ok := usuarioDesdeDesafio(c, request.ChallengeToken, propositoEnrolarDosFactores)
+	if !ok {
+		return
+	}
+
+	iniciarEnrolamiento(c, *usuario.Credencial)
+}
+
+// ActivarLogin2FA: Confirmar el enrolamiento obligatorio y entregar la sesión
+func ActivarLogin2FA(c *gin.Context) {
+	var request dto.VerificarDosFactoresRequest
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		HandleError(c, err, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	usuario, ok := usuarioDesdeDesafio(c, request.ChallengeToken, propositoEnrolarDosFactores)
+	if !ok {
+		return
+	}
+
+	codigos, ok := completarEnrolamiento(c, usuario.Credencial, request.Codigo)
+	if !ok {
+		return
+	}
+
+	// Se crea la sesión y se incluyen los códigos de recuperación, que solo se muestran esta vez
+	emitirSesion(c, usuario, gin.H{"codigos_recuperacion": codigos})
+}
+
+// Enrolar2FA: Iniciar el enrolamiento del segundo factor del usuario autenticado
+func Enrolar2FA(c *gin.Context) {
+	credencial, ok := credencialUsuarioAutenticado(c)
+	if !ok {
+		return
+	}
+
+	iniciarEnrolamiento(c, credencial)
+}
+
+// Activar2FA: Confirmar el enrolamiento del usuario autenticado con un código válido
+func Activar2FA(c *gin.Context) {
+	var request dto.CodigoDosFactoresRequest
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		HandleError(c, err, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	credencial, ok := credencialUsuarioAutenticado(c)
+	if !ok {
+		return
+	}
+
+	codigos, ok := completarEnrolamiento(c, &credencial, request.Codigo)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":              "Autenticación de dos factores habilitada exitosamente",
+		"codigos_recuperacion": codigos,
+	})
+}
+
+// Desactivar2FA: Deshabilitar el segundo factor del usuario autenticado (requiere un código válido)
+func Desactivar2FA(c *gin.Context) {
+	var request dto.CodigoDosFactoresRequest
+	var usuario models.Usuario
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		HandleError(c, err, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := configs.DB.Preload("Credencial").Preload("Rol_usuario", "deleted_at IS NULL").Preload("Rol_usuario.Rol").First(&usuario, c.GetUint("usuarioID")).Error; err != nil || usuario.Credencial == nil {
+		HandleError(c, err, http.StatusNotFound, "Credenciales no encontradas")
+		return
+	}
+
+	if usuario.Credencial.TOTPHabilitadoAt == nil {
+		HandleError(c, nil, http.StatusBadRequest, "La autenticación de dos factores no está habilitada")
+		return
+	}
+
+	obligatorio, err := dosFactoresObligatorio(usuario)
+	if err != nil {
+		HandleError(c, err, http.StatusInternalServerError, "No se pudo verificar la configuración de seguridad")
+		return
+	}
+	if obligatorio {
+		HandleError(c, nil, http.StatusForbidden, "La autenticación de dos factores es obligatoria para su rol")
+		return
+	}
+
+	valido, err := services.VerificarSegundoFactor(usuario.Credencial, request.Codigo)
+	if err != nil {
+		HandleError(c, err, http.StatusInternalServerError, "No se pudo verificar el código")
+		return
+	}
+	if !valido {
+		HandleError(c, nil, http.StatusUnauthorized, "Código inválido")
+		return
+	}
+
+	err = configs.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(usuario.Credencial).Updates(map[string]interface{}{
+			"totp_secreto":       "",
+			"totp_habilitado_at": nil,
+			"totp_ultimo_paso":   0,
+		}).Error; err != nil {
+			return err
+		}
+		return tx.Where("credencial_id = ?", usuario.Credencial.ID).Delete(&models.Codigo_recuperacion{}).Error
+	})
+	if err != nil {
+		HandleError(c, err, http.StatusInternalServerError, "No se pudo deshabilitar la autenticación de dos factores")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Autenticación de dos factores deshabilitada"})
+}
+
+// RegenerarCodigosRecuperacion: Invalidar los códigos anteriores y generar nuevos (requiere un código válido)
+func RegenerarCodigosRecuperacion(c *gin.Context) {
+	var request dto.CodigoDosFactoresRequest
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		HandleError(c, err, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	credencial, ok := credencialUsuarioAutenticado(c)
+	if !ok {
+		return
+	}
+
+	if credencial.TOTPHabilitadoAt == nil {
+		HandleError(c, nil, http.StatusBadRequest, "La autenticación de dos factores no está habilitada")
+		return
+	}
+
+	valido, err := services.VerificarSegundoFactor(&credencial, request.Codigo)
+	if err != nil {
+		HandleError(c, err, http.StatusInternalServerError, "No se pudo verificar el código")
+		return
+	}
+	if !valido {
+		HandleError(c, nil, http.StatusUnauthorized, "Código inválido")
+		return
+	}
+
+	var codigos []string
+	err = configs.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		codigos, err = services.GenerarCodigosRecuperacion(tx, credencial.ID)
+		return err
+	})
+	if err != nil {
+		HandleError(c, err, http.StatusInternalServerError, "No se pudo generar los códigos de recuperación")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"codigos_recuperacion": codigos})
+}
@@ -4,10 +4,10 @@ import (
 	"errors"
 	"net/http"
 	"strconv"
-	"time"
 	"v1_prefabricadas/configs"
 	"v1_prefabricadas/dto"
 	"v1_prefabricadas/models"
+	"v1_prefabricadas/services"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
@@ -42,7 +42,7 @@ func CrearEmpresa(c *gin.Context) {
 	}
 
 	// Guardamos la Empresa en la Base de Datos
-	if err := configs.DB.Create(&empresa).Error; err != nil {
+	if err := configs.DB.WithContext(c).Create(&empresa).Error; err != nil {
 		handleErrorEmpresa(c, err, http.StatusInternalServerError, "No se pudo crear Empresa")
 		return
 	}
@@ -69,18 +69,23 @@ func CrearEmpresa(c *gin.Context) {
 // Función para Obtener Todas las Empresas
 func ObtenerEmpresas(c *gin.Context) {
 	var empresas []models.Empresa
-	var empresasResponse []dto.EmpresaResponse
+	empresasResponse := []dto.EmpresaResponse{}
+
+	// Parámetros de paginación
+	paginacion, ok := obtenerPaginacion(c, 20, false)
+	if !ok {
+		return
+	}
 
 	// Buscar todas las Empresas con sus Servicios y Redes en la base de datos
-	if err := configs.DB.
+	if err := paginacion.paginar(configs.DB.
 		Where("deleted_at IS NULL").
 		Preload("Servicio", func(db *gorm.DB) *gorm.DB {
 			return db.Where("deleted_at IS NULL") // Condición para no cargar Serviios eliminadas lógicamente
 		}).
 		Preload("Red", func(db *gorm.DB) *gorm.DB {
 			return db.Where("deleted_at IS NULL") // Condición para no cargar RedesSociales eliminadas lógicamente
-		}).
-		Find(&empresas).Error; err != nil {
+		}), "id ASC", "id", false, &empresas); err != nil {
 		HandleError(c, err, http.StatusInternalServerError, "No se pudieron obtener las Empresas")
 		return
 	}
@@ -124,7 +129,7 @@ func ObtenerEmpresas(c *gin.Context) {
 	}
 
 	// Responder/mostrar Empresas
-	c.JSON(http.StatusOK, gin.H{"empresas": empresasResponse})
+	paginacion.responder(c, "empresas", empresasResponse)
 }
 
 // Función para obtener una Empresa de acuerdo a su ID
@@ -243,7 +248,7 @@ func ActualizarEmpresa(c *gin.Context) {
 	empresa.EmailEmpresa = request.EmailEmpresa
 
 	// Guardar los datos en la base de datos
-	if err := configs.DB.Save(&empresa).Error; err != nil {
+	if err := configs.DB.WithContext(c).Save(&empresa).Error; err != nil {
 		HandleError(c, err, http.StatusInternalServerError, "No se pudo actualizar los datos de la Empresa")
 		return
 	}
@@ -315,13 +320,14 @@ func EliminarEmpresa(c *gin.Context) {
 		return
 	}
 
-	// Establecer la fecha y hora de la eliminación lógica
-	now := time.Now()
-	empresa.DeletedAt = &now
+	// Eliminar lógicamente con sus usuarios, prefabricadas, noticias, portadas, servicios y redes, y registrar en la papelera
+	if !enviarAPapelera(c, "empresa", empresa.ID, "No se pudo eliminar la Empresa") {
+		return
+	}
 
-	// Guardar en la base de datos la fecha y hora de la eliminación lógica
-	if err := configs.DB.Save(&empresa).Error; err != nil {
-		HandleError(c, err, http.StatusInternalServerError, "No se pudo eliminar la Empresa")
+	// Cortar el acceso de los usuarios de la empresa eliminada
+	if err := services.RevocarSesionesEmpresa(configs.DB, empresa.ID); err != nil {
+		HandleError(c, err, http.StatusInternalServerError, "Error, no se pudo cerrar las sesiones de los usuarios")
 		return
 	}
 
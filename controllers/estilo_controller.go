@@ -5,7 +5,6 @@ import (
 	"log"
 	"net/http"
 	"strconv"
-	"time"
 	"v1_prefabricadas/configs"
 	"v1_prefabricadas/dto"
 	"v1_prefabricadas/models"
@@ -45,7 +44,7 @@ func CrearEstilo(c *gin.Context) {
 	}
 
 	// Agregamos el Estilo a la base de Datos
-	if err := configs.DB.Create(&estilo).Error; err != nil {
+	if err := configs.DB.WithContext(c).Create(&estilo).Error; err != nil {
 		handleErrorEstilo(c, err, http.StatusInternalServerError, "No se pudo crear el Estilo")
 		return
 	}
@@ -69,10 +68,16 @@ func CrearEstilo(c *gin.Context) {
 // Función para obtener todos los Estilos
 func ObtenerEstilos(c *gin.Context) {
 	var estilos []models.Estilo
-	var estiloResponse []dto.EstiloResponse
+	estiloResponse := []dto.EstiloResponse{}
+
+	// Parámetros de paginación
+	paginacion, ok := obtenerPaginacion(c, limiteMaximoPaginacion, false)
+	if !ok {
+		return
+	}
 
 	// Buscar los estilos en la base de datos
-	if err := configs.DB.Where("deleted_at IS NULL").Find(&estilos).Error; err != nil {
+	if err := paginacion.paginar(configs.DB.Where("deleted_at IS NULL"), "id ASC", "id", false, &estilos); err != nil {
 		handleErrorEstilo(c, err, http.StatusInternalServerError, "No se pudieron obtener los Estilo")
 		return
 	}
@@ -89,7 +94,7 @@ func ObtenerEstilos(c *gin.Context) {
 	}
 
 	// Mostrar los Estilos
-	c.JSON(http.StatusOK, gin.H{"estilos": estiloResponse})
+	paginacion.responder(c, "estilos", estiloResponse)
 }
 
 // Función para obtener un estilo de acuerdo a su ID
@@ -160,7 +165,7 @@ func ActualizarEstilo(c *gin.Context) {
 	estilo.DescripcionEstilo = request.DescripcionEstilo
 
 	// Guardar datos en la base de datos
-	if err := configs.DB.Save(&estilo).Error; err != nil {
+	if err := configs.DB.WithContext(c).Save(&estilo).Error; err != nil {
 		HandleError(c, err, http.StatusInternalServerError, "No se pudo actualizar el Estilo")
 		return
 	}
@@ -203,13 +208,8 @@ func EliminarEstilo(c *gin.Context) {
 		return
 	}
 
-	// Establecer la fecha de la eliminación lógica
-	now := time.Now()
-	estilo.DeletedAt = &now
-
-	// Actualizar el registro de estilo en la base de datos
-	if err := configs.DB.Save(&estilo).Error; err != nil {
-		HandleError(c, err, http.StatusInternalServerError, "No se pudo eliminar el Estilo")
+	// Eliminar lógicamente, siempre que ninguna prefabricada lo use
+	if !eliminarCatalogoGlobal(c, "estilos", estilo.ID, "No se pudo eliminar el Estilo") {
 		return
 	}
 
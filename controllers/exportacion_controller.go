@@ -0,0 +1,218 @@
+package controllers
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"v1_prefabricadas/configs"
+	"v1_prefabricadas/models"
+	"v1_prefabricadas/services"
+	"v1_prefabricadas/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Cantidad de prefabricadas que se leen de la base de datos por vez al exportar
+const loteExportacion = 200
+
+var tiposContenidoExportacion = map[string]string{
+	"csv":  "text/csv; charset=utf-8",
+	"xlsx": "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+	"json": "application/json; charset=utf-8",
+}
+
+// Función para exportar el catálogo de la empresa en CSV o XLSX (una fila por prefabricada y precio,
+// con las mismas columnas que la importación) o en JSON (prefabricadas con sus relaciones anidadas).
+// Admite los mismos filtros y orden que el listado de prefabricadas. El archivo se genera y envía
+// por lotes, sin cargar el catálogo completo en memoria.
+func ExportarPrefabricadas(c *gin.Context) {
+	empresaID, err := strconv.ParseUint(c.Param("empresaID"), 10, 64)
+	if err != nil {
+		HandleError(c, nil, http.StatusBadRequest, "ID Empresa inválido")
+		return
+	}
+
+	formato := c.Param("formato")
+	tipoContenido, ok := tiposContenidoExportacion[formato]
+	if !ok {
+		HandleError(c, nil, http.StatusBadRequest, "Formato no soportado, use csv, xlsx o json")
+		return
+	}
+
+	// Mismos filtros que ObtenerPrefabricadas (categoría, tipo, estilo, destacada, oferta, m2, precio, características, estado) y orden
+	filtros, err := services.ParsearFiltrosPrefabricadas(c.Request.URL.Query())
+	if err != nil {
+		HandleError(c, nil, http.StatusBadRequest, err.Error())
+		return
+	}
+	filtros.SoloPublicadas = soloPublicados(c)
+
+	query := precargarPrefabricada(configs.DB).
+		Where("prefabricadas.empresa_id = ?", empresaID).
+		Where("prefabricadas.deleted_at IS NULL")
+	query = services.AplicarFiltrosPrefabricadas(query, filtros)
+	query = services.OrdenarPrefabricadas(query, filtros.Orden).Session(&gorm.Session{})
+
+	// Leer el primer lote antes de enviar los encabezados, para poder responder un error si la consulta falla
+	lote, err := loteDeExportacion(query, 0)
+	if err != nil {
+		HandleError(c, err, http.StatusInternalServerError, "Error al exportar las prefabricadas")
+		return
+	}
+
+	nombreArchivo := fmt.Sprintf("prefabricadas-%d-%s.%s", empresaID, time.Now().Format("20060102-150405"), formato)
+	c.Header("Content-Type", tipoContenido)
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, nombreArchivo))
+	c.Status(http.StatusOK)
+
+	var escribirLote func([]models.Prefabricada) error
+	var terminar func() error
+
+	switch formato {
+	case "csv":
+		// Marca de orden de bytes para que Excel reconozca los acentos
+		if _, err := c.Writer.WriteString("\xef\xbb\xbf"); err != nil {
+			log.Printf("Error al exportar las prefabricadas: %v", err)
+			return
+		}
+		csvWriter := csv.NewWriter(c.Writer)
+		if err := csvWriter.Write(services.ColumnasCatalogo); err != nil {
+			log.Printf("Error al exportar las prefabricadas: %v", err)
+			return
+		}
+		escribirLote = func(prefabricadas []models.Prefabricada) error {
+			for _, prefabricada := range prefabricadas {
+				for _, fila := range services.FilasCatalogo(prefabricada) {
+					textos := make([]string, len(fila))
+					for i, valor := range fila {
+						textos[i] = textoCeldaExportacion(valor)
+					}
+					if err := csvWriter.Write(textos); err != nil {
+						return err
+					}
+				}
+			}
+			csvWriter.Flush()
+			return csvWriter.Error()
+		}
+		terminar = func() error { return nil }
+
+	case "xlsx":
+		escritor, err := utils.NuevoEscritorXLSX(c.Writer, "Prefabricadas")
+		if err != nil {
+			log.Printf("Error al exportar las prefabricadas: %v", err)
+			return
+		}
+		encabezado := make([]interface{}, len(services.ColumnasCatalogo))
+		for i, columna := range services.ColumnasCatalogo {
+			encabezado[i] = columna
+		}
+		if err := escritor.EscribirFila(encabezado...); err != nil {
+			log.Printf("Error al exportar las prefabricadas: %v", err)
+			return
+		}
+		escribirLote = func(prefabricadas []models.Prefabricada) error {
+			for _, prefabricada := range prefabricadas {
+				for _, fila := range services.FilasCatalogo(prefabricada) {
+					if err := escritor.EscribirFila(fila...); err != nil {
+						return err
+					}
+				}
+			}
+			return escritor.Flush()
+		}
+		terminar = escritor.Cerrar
+
+	case "json":
+		if _, err := c.Writer.WriteString(`{"prefabricadas":[`); err != nil {
+			log.Printf("Error al exportar las prefabricadas: %v", err)
+			return
+		}
+		primera := true
+		escribirLote = func(prefabricadas []models.Prefabricada) error {
+			for _, prefabricada := range prefabricadas {
+				contenido, err := json.Marshal(prefabricadaAResponse(prefabricada))
+				if err != nil {
+					return err
+				}
+				if !primera {
+					if _, err := c.Writer.WriteString(","); err != nil {
+						return err
+					}
+				}
+				primera = false
+				if _, err := c.Writer.Write(contenido); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+		terminar = func() error {
+			_, err := c.Writer.WriteString("]}")
+			return err
+		}
+	}
+
+	// Escribir lote por lote. Los encabezados ya se enviaron, un error a esta altura solo se registra
+	// y el archivo queda incompleto.
+	for offset := 0; len(lote) > 0; {
+		if err := escribirLote(lote); err != nil {
+			log.Printf("Error al exportar las prefabricadas: %v", err)
+			return
+		}
+		c.Writer.Flush()
+
+		if len(lote) < loteExportacion {
+			break
+		}
+		offset += len(lote)
+		if lote, err = loteDeExportacion(query, offset); err != nil {
+			log.Printf("Error al exportar las prefabricadas: %v", err)
+			return
+		}
+	}
+	if err := terminar(); err != nil {
+		log.Printf("Error al exportar las prefabricadas: %v", err)
+	}
+}
+
+func loteDeExportacion(query *gorm.DB, offset int) ([]models.Prefabricada, error) {
+	var prefabricadas []models.Prefabricada
+	err := query.Limit(loteExportacion).Offset(offset).Find(&prefabricadas).Error
+	return prefabricadas, err
+}
+
+// Caracteres con los que Excel y otras planillas interpretan una celda de texto como fórmula
+const inicioFormulaCSV = "=+-@\t\r"
+
+// Texto de una celda del CSV (los decimales con punto, como los espera la importación).
+// Los textos que empiezan como una fórmula se anteponen con ' para que la planilla no los ejecute.
+func textoCeldaExportacion(valor interface{}) string {
+	switch v := valor.(type) {
+	case nil:
+		return ""
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case string:
+		if v != "" && strings.ContainsRune(inicioFormulaCSV, rune(v[0])) {
+			return "'" + v
+		}
+		return v
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// Quitar la ' que agrega textoCeldaExportacion, para que un CSV exportado se pueda volver a importar sin cambios
+func textoCeldaImportacion(texto string) string {
+	if len(texto) > 1 && texto[0] == '\'' && strings.ContainsRune(inicioFormulaCSV, rune(texto[1])) {
+		return texto[1:]
+	}
+	return texto
+}
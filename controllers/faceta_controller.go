@@ -0,0 +1,81 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"v1_prefabricadas/dto"
+	"v1_prefabricadas/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Función para obtener las cantidades de Prefabricadas por cada opción de los filtros del catálogo.
+// Acepta los mismos filtros que ObtenerPrefabricadas y los rangos rangos_m2 y rangos_precio (ej. 0-50,50-100,100-)
+func ObtenerFacetasPrefabricadas(c *gin.Context) {
+	idParamEmpresa := c.Param("empresaID")
+	empresaID, err := strconv.ParseUint(idParamEmpresa, 10, 64)
+	if err != nil {
+		HandleError(c, nil, http.StatusBadRequest, "ID Empresa inválido")
+		return
+	}
+
+	filtros, err := services.ParsearFiltrosPrefabricadas(c.Request.URL.Query())
+	if err != nil {
+		HandleError(c, nil, http.StatusBadRequest, err.Error())
+		return
+	}
+	filtros.SoloPublicadas = soloPublicados(c)
+
+	rangosM2, err := services.ParsearRangosFaceta(c.DefaultQuery("rangos_m2", services.RangosM2PorDefecto), "rangos_m2")
+	if err != nil {
+		HandleError(c, nil, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	rangosPrecio, err := services.ParsearRangosFaceta(c.DefaultQuery("rangos_precio", services.RangosPrecioPorDefecto), "rangos_precio")
+	if err != nil {
+		HandleError(c, nil, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	facetas, err := services.ObtenerFacetasPrefabricadas(uint(empresaID), filtros, rangosM2, rangosPrecio)
+	if err != nil {
+		HandleError(c, err, http.StatusInternalServerError, "Error al obtener las facetas de Prefabricadas")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"facetas": dto.FacetasPrefabricadasResponse{
+		Total:      facetas.Total,
+		Categorias: opcionesFacetaResponse(facetas.Categorias),
+		Estilos:    opcionesFacetaResponse(facetas.Estilos),
+		Tipos:      opcionesFacetaResponse(facetas.Tipos),
+		Oferta:     dto.FacetaBooleanaResponse{Si: facetas.Oferta[true], No: facetas.Oferta[false]},
+		Destacada:  dto.FacetaBooleanaResponse{Si: facetas.Destacada[true], No: facetas.Destacada[false]},
+		M2:         rangosFacetaResponse(facetas.M2),
+		Precio:     rangosFacetaResponse(facetas.Precio),
+	}})
+}
+
+func opcionesFacetaResponse(opciones []services.OpcionFaceta) []dto.OpcionFacetaResponse {
+	response := []dto.OpcionFacetaResponse{}
+	for _, opcion := range opciones {
+		response = append(response, dto.OpcionFacetaResponse{
+			ID:       opcion.ID,
+			Nombre:   opcion.Nombre,
+			Cantidad: opcion.Cantidad,
+		})
+	}
+	return response
+}
+
+func rangosFacetaResponse(rangos []services.CantidadRango) []dto.RangoFacetaResponse {
+	response := []dto.RangoFacetaResponse{}
+	for _, rango := range rangos {
+		response = append(response, dto.RangoFacetaResponse{
+			Desde:    rango.Rango.Desde,
+			Hasta:    rango.Rango.Hasta,
+			Cantidad: rango.Cantidad,
+		})
+	}
+	return response
+}
@@ -0,0 +1,77 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"v1_prefabricadas/configs"
+	"v1_prefabricadas/models"
+	"v1_prefabricadas/services"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Función para obtener la ficha imprimible en PDF de una Prefabricada publicada,
+// con sus imágenes principales, características, precios e incluyes y los datos de contacto de la Empresa
+func ObtenerFichaPrefabricada(c *gin.Context) {
+	var prefabricada models.Prefabricada
+	var empresa models.Empresa
+
+	empresaID, err := strconv.ParseUint(c.Param("empresaID"), 10, 64)
+	if err != nil {
+		HandleError(c, nil, http.StatusBadRequest, "ID Empresa inválido")
+		return
+	}
+
+	// La Prefabricada se puede obtener por su ID o por su slug
+	prefabricadaID, ok := resolverIDOSlug(c, "prefabricadaID", services.EntidadSlugPrefabricada, uint(empresaID), "Prefabricada no encontrada")
+	if !ok {
+		return
+	}
+
+	query := configs.DB
+	if soloPublicados(c) {
+		query = services.FiltrarPublicados(query, "prefabricadas")
+	}
+
+	if err := precargarPrefabricada(query).
+		Where("prefabricadas.empresa_id = ?", empresaID).
+		Where("prefabricadas.deleted_at IS NULL").
+		First(&prefabricada, prefabricadaID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			HandleError(c, nil, http.StatusNotFound, "Prefabricada no encontrada")
+			return
+		}
+		HandleError(c, err, http.StatusInternalServerError, "Error al obtener datos de la Prefabricada")
+		return
+	}
+
+	if err := configs.DB.
+		Preload("Red", func(db *gorm.DB) *gorm.DB {
+			return db.Where("deleted_at IS NULL") // Condición para no cargar redes eliminadas lógicamente
+		}).
+		Where("deleted_at IS NULL").
+		First(&empresa, empresaID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			HandleError(c, nil, http.StatusNotFound, "Empresa no encontrada")
+			return
+		}
+		HandleError(c, err, http.StatusInternalServerError, "Error al obtener datos de la Empresa")
+		return
+	}
+
+	ficha, err := services.FichaPrefabricadaEnCache(prefabricada, empresa)
+	if err != nil {
+		HandleError(c, err, http.StatusInternalServerError, "Error al generar la ficha de la Prefabricada")
+		return
+	}
+
+	nombreArchivo := valorSlug(prefabricada.Slug)
+	if nombreArchivo == "" {
+		nombreArchivo = strconv.FormatUint(uint64(prefabricada.ID), 10)
+	}
+	c.Header("Content-Disposition", fmt.Sprintf(`inline; filename="ficha-%s.pdf"`, nombreArchivo))
+	c.Data(http.StatusOK, "application/pdf", ficha)
+}
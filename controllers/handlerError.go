@@ -1,7 +1,10 @@
 package controllers
 
 import (
+	"errors"
 	"log"
+	"net/http"
+	"v1_prefabricadas/services"
 
 	"github.com/gin-gonic/gin"
 )
@@ -13,3 +16,18 @@ func HandleError(c *gin.Context, err error, statusCode int, message string) {
 	c.JSON(statusCode, gin.H{"error": message})
 	c.Abort() // Asegura que no se ejecute más lógica después.
 }
+
+// Responder el error de validación de una contraseña nueva, indicando los motivos del rechazo
+// cuando no cumple la política de seguridad
+func HandlePasswordError(c *gin.Context, err error, message string) {
+	var errPolitica *services.ErrPoliticaPassword
+	if errors.As(err, &errPolitica) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "La contraseña no cumple la política de seguridad",
+			"motivos": errPolitica.Motivos,
+		})
+		c.Abort()
+		return
+	}
+	HandleError(c, err, http.StatusInternalServerError, message)
+}
@@ -4,7 +4,6 @@ import (
 	"errors"
 	"net/http"
 	"strconv"
-	"time"
 	"v1_prefabricadas/configs"
 	"v1_prefabricadas/dto"
 	"v1_prefabricadas/models"
@@ -62,7 +61,7 @@ func CrearImagenNoticia(c *gin.Context) {
 	imagen.Image = url
 
 	// Guardar la Imagen en la base da datos
-	if err := configs.DB.Create(&imagen).Error; err != nil {
+	if err := configs.DB.WithContext(c).Create(&imagen).Error; err != nil {
 		HandleError(c, err, http.StatusInternalServerError, "Error, no se pudo guardar la Imagen")
 		return
 	}
@@ -88,7 +87,7 @@ func CrearImagenNoticia(c *gin.Context) {
 // Obtener Todas las Imagenes de una Noticia
 func ObtenerImagenesNoticias(c *gin.Context) {
 	var imagenes []models.Imagen_noticia
-	var imagenesResponse []dto.Imagen_noticiaResponse
+	imagenesResponse := []dto.Imagen_noticiaResponse{}
 
 	idParamNoticia := c.Param("noticiaID")
 	noticiaID, err := strconv.ParseUint(idParamNoticia, 10, 64)
@@ -97,18 +96,15 @@ func ObtenerImagenesNoticias(c *gin.Context) {
 		return
 	}
 
-	// Buscar todas las imagenes de una noticia
-	if err := configs.DB.Where("noticia_id = ?", noticiaID).Where("deleted_at IS NULL").Find(&imagenes).Error; err != nil {
-		HandleError(c, err, http.StatusInternalServerError, "Error al obtener las imagenes de la Noticia")
+	// Parámetros de paginación
+	paginacion, ok := obtenerPaginacion(c, limiteMaximoPaginacion, false)
+	if !ok {
 		return
 	}
 
-	// Si no hay imágenes, devolver un array vacío con status 200
-	if len(imagenes) == 0 {
-		// Devolver array vacío en lugar de error 404
-		c.JSON(http.StatusOK, gin.H{
-			"imagenes_noticia": []interface{}{},
-		})
+	// Buscar todas las imagenes de una noticia
+	if err := paginacion.paginar(configs.DB.Where("noticia_id = ?", noticiaID).Where("deleted_at IS NULL"), "id ASC", "id", false, &imagenes); err != nil {
+		HandleError(c, err, http.StatusInternalServerError, "Error al obtener las imagenes de la Noticia")
 		return
 	}
 
@@ -123,9 +119,7 @@ func ObtenerImagenesNoticias(c *gin.Context) {
 	}
 
 	// Mostrat/enviar imagenes de la Noticia
-	c.JSON(http.StatusOK, gin.H{
-		"imagenes_noticia": imagenesResponse,
-	})
+	paginacion.responder(c, "imagenes_noticia", imagenesResponse)
 
 }
 
@@ -222,7 +216,7 @@ func ActualizarImagenNoticia(c *gin.Context) {
 	}
 
 	// guardar cambios en la base de datos
-	if err := configs.DB.Save(&imagen).Error; err != nil {
+	if err := configs.DB.WithContext(c).Save(&imagen).Error; err != nil {
 		HandleError(c, err, http.StatusInternalServerError, "Error, no se pudo actualizar la imagen")
 		return
 	}
@@ -275,13 +269,8 @@ func EliminarImagenNoticia(c *gin.Context) {
 		return
 	}
 
-	// Poner fecha y hora de la eliminación lógica
-	now := time.Now()
-	imagen.DeletedAt = &now
-
-	// guardar Fecha y hora de la eliminación lógica
-	if err := configs.DB.Save(&imagen).Error; err != nil {
-		HandleError(c, err, http.StatusInternalServerError, "Error al tratar de eliminar la Imagen")
+	// Eliminar lógicamente y registrar en la papelera
+	if !enviarAPapelera(c, "imagenes_noticias", imagen.ID, "Error al tratar de eliminar la Imagen") {
 		return
 	}
 
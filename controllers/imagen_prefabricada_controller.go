@@ -4,7 +4,6 @@ import (
 	"errors"
 	"net/http"
 	"strconv"
-	"time"
 	"v1_prefabricadas/configs"
 	"v1_prefabricadas/dto"
 	"v1_prefabricadas/models"
@@ -60,7 +59,7 @@ func CrearImagen_prefabricada(c *gin.Context) {
 	imagen_prefabricada.PrefabricadaID = uint(prefabricadaID)
 
 	// Guardamos en la base de datos
-	if err := configs.DB.Create(&imagen_prefabricada).Error; err != nil {
+	if err := configs.DB.WithContext(c).Create(&imagen_prefabricada).Error; err != nil {
 		HandleError(c, err, http.StatusInternalServerError, "No se pudo guardar la Imagen")
 		return
 	}
@@ -82,7 +81,7 @@ func CrearImagen_prefabricada(c *gin.Context) {
 // Función para obtener todas las Imagenes de una prefabricada
 func ObtenerImagenesPrefabricadas(c *gin.Context) {
 	var imagenes []models.Imagen_prefabricada
-	var imagenesResponse []dto.Imagen_prefabricadaResponse
+	imagenesResponse := []dto.Imagen_prefabricadaResponse{}
 
 	idParamPrefabricada := c.Param("prefabricadaID")
 	prefabricadaID, err := strconv.ParseUint(idParamPrefabricada, 10, 64)
@@ -91,8 +90,14 @@ func ObtenerImagenesPrefabricadas(c *gin.Context) {
 		return
 	}
 
+	// Parámetros de paginación
+	paginacion, ok := obtenerPaginacion(c, limiteMaximoPaginacion, false)
+	if !ok {
+		return
+	}
+
 	// Buscar las imagenes de la prefabricada en la base de datos
-	if err := configs.DB.Where("prefabricada_id = ? AND deleted_at IS NULL", prefabricadaID).Find(&imagenes).Error; err != nil {
+	if err := paginacion.paginar(configs.DB.Where("prefabricada_id = ? AND deleted_at IS NULL", prefabricadaID), "id ASC", "id", false, &imagenes); err != nil {
 		HandleError(c, err, http.StatusInternalServerError, "Imagenes no encontradas")
 		return
 	}
@@ -108,7 +113,7 @@ func ObtenerImagenesPrefabricadas(c *gin.Context) {
 	}
 
 	// Mostrar/enviar imagenes_prefabricadas
-	c.JSON(http.StatusOK, gin.H{"Imagenes_prefabricadas": imagenesResponse})
+	paginacion.responder(c, "Imagenes_prefabricadas", imagenesResponse)
 }
 
 // Función para obtener una imagen de una prefabricada
@@ -212,7 +217,7 @@ func ActualizarImagenPrefabricada(c *gin.Context) {
 	}
 
 	// Guardar cambios en la base de datos
-	if err := configs.DB.Save(&imagen).Error; err != nil {
+	if err := configs.DB.WithContext(c).Save(&imagen).Error; err != nil {
 		HandleError(c, err, http.StatusInternalServerError, "Error, no se pudo guardar los cambios")
 		return
 	}
@@ -267,13 +272,8 @@ func EliminarImagenPrefabricada(c *gin.Context) {
 		return
 	}
 
-	// Poner fecha y hora de la eliminación
-	now := time.Now()
-	imagen.DeletedAt = &now
-
-	// Guardar fecha y hora de la eliminación lógica en la base de datos
-	if err := configs.DB.Save(&imagen).Error; err != nil {
-		HandleError(c, err, http.StatusInternalServerError, "Error, no se pudo eliminar la Imagen")
+	// Eliminar lógicamente y registrar en la papelera
+	if !enviarAPapelera(c, "imagenes_prefabricadas", imagen.ID, "Error, no se pudo eliminar la Imagen") {
 		return
 	}
 
@@ -0,0 +1,588 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"v1_prefabricadas/configs"
+	"v1_prefabricadas/dto"
+	"v1_prefabricadas/models"
+	"v1_prefabricadas/services"
+	"v1_prefabricadas/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"gorm.io/gorm"
+)
+
+const (
+	tamanoMaximoImportacion = 10 << 20 // 10 MB
+	filasMaximasImportacion = 5000
+)
+
+// Límites de lectura de la hoja: las filas de datos más el encabezado, las columnas del catálogo
+// con margen para columnas propias del usuario y cada parte descomprimida de un XLSX
+var limitesHojaImportacion = utils.LimitesHojaCalculo{
+	Filas:    filasMaximasImportacion + 1,
+	Columnas: len(services.ColumnasCatalogo) + 20,
+	Bytes:    5 * tamanoMaximoImportacion,
+}
+
+// Columna de la hoja para cada campo de las solicitudes validadas
+var columnasImportacion = map[string]string{
+	"NombrePrefabricada": "nombre_prefabricada",
+	"Slug":               "slug",
+	"M2":                 "m2",
+	"Garantia":           "garantia",
+	"Eslogan":            "eslogan",
+	"Descripcion":        "descripcion",
+	"CategoriaID":        "categoria_id",
+	"EstiloID":           "estilo_id",
+	"TipoID":             "tipo_id",
+	"Clave":              "caracteristicas",
+	"Valor":              "caracteristicas",
+	"NombrePrecio":       "nombre_precio",
+	"DescripcionPrecio":  "descripcion_precio",
+	"ValorPrefabricada":  "valor_prefabricada",
+	"NombreIncluye":      "incluyes",
+}
+
+// Función para importar prefabricadas con sus precios, incluyes y características desde un CSV o XLSX.
+// Con dry_run=true solo informa lo que se crearía, actualizaría u omitiría y los errores por fila.
+// Sin dry_run aplica todo en una transacción, o nada si alguna fila tiene errores.
+func ImportarPrefabricadas(c *gin.Context) {
+	empresaID, err := strconv.ParseUint(c.Param("empresaID"), 10, 64)
+	if err != nil {
+		HandleError(c, nil, http.StatusBadRequest, "ID Empresa inválido")
+		return
+	}
+
+	dryRun, err := strconv.ParseBool(c.DefaultQuery("dry_run", "false"))
+	if err != nil {
+		HandleError(c, nil, http.StatusBadRequest, "Parámetro dry_run inválido")
+		return
+	}
+
+	fileHeader, err := c.FormFile("archivo")
+	if err != nil {
+		HandleError(c, nil, http.StatusBadRequest, "Debe enviar la hoja de cálculo en el campo 'archivo'")
+		return
+	}
+	if fileHeader.Size > tamanoMaximoImportacion {
+		HandleError(c, nil, http.StatusRequestEntityTooLarge, "El archivo supera el tamaño máximo de 10 MB")
+		return
+	}
+
+	formato := strings.ToLower(strings.TrimPrefix(filepath.Ext(fileHeader.Filename), "."))
+	if formato != "csv" && formato != "xlsx" {
+		HandleError(c, nil, http.StatusBadRequest, "Formato no soportado, use CSV o XLSX")
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		HandleError(c, err, http.StatusInternalServerError, "No se pudo abrir el archivo")
+		return
+	}
+	defer file.Close()
+
+	var filas [][]string
+	if formato == "csv" {
+		filas, err = utils.LeerCSV(file, limitesHojaImportacion)
+		for _, fila := range filas {
+			for i := range fila {
+				fila[i] = textoCeldaImportacion(fila[i])
+			}
+		}
+	} else {
+		filas, err = utils.LeerXLSX(file, fileHeader.Size, limitesHojaImportacion)
+	}
+	if err != nil {
+		if errors.Is(err, utils.ErrHojaCalculoInvalida) || errors.Is(err, utils.ErrHojaCalculoExcedeLimite) {
+			HandleError(c, nil, http.StatusBadRequest, err.Error())
+			return
+		}
+		HandleError(c, err, http.StatusInternalServerError, "No se pudo leer el archivo")
+		return
+	}
+
+	// Leer y validar cada fila con las mismas reglas que la creación desde el panel
+	prefabricadas, erroresFilas, cantidadFilas, err := leerHojaImportacion(filas)
+	if err != nil {
+		HandleError(c, nil, http.StatusBadRequest, err.Error())
+		return
+	}
+	if cantidadFilas > filasMaximasImportacion {
+		HandleError(c, nil, http.StatusBadRequest, fmt.Sprintf("La hoja tiene %d filas, el máximo es %d", cantidadFilas, filasMaximasImportacion))
+		return
+	}
+
+	plan, err := services.PlanificarImportacion(configs.DB, uint(empresaID), prefabricadas)
+	if err != nil {
+		HandleError(c, err, http.StatusInternalServerError, "Error al comparar la hoja con el catálogo")
+		return
+	}
+	plan.Errores = append(erroresFilas, plan.Errores...)
+	sort.SliceStable(plan.Errores, func(i, j int) bool { return plan.Errores[i].Fila < plan.Errores[j].Fila })
+
+	// Registro de la importación para el historial
+	var resultado dto.ResultadoImportacionResponse
+	var importacion models.Importacion
+	guardarImportacion := func(tx *gorm.DB, estado string) error {
+		resultado = resultadoImportacionResponse(plan)
+		resultadoJSON, err := json.Marshal(resultado)
+		if err != nil {
+			return err
+		}
+		resultadoTexto := string(resultadoJSON)
+		importacion = models.Importacion{
+			EmpresaID:    uint(empresaID),
+			Archivo:      fileHeader.Filename,
+			Formato:      formato,
+			DryRun:       dryRun,
+			Estado:       estado,
+			Filas:        cantidadFilas,
+			Creadas:      plan.Contar(services.AccionImportacionCrear),
+			Actualizadas: plan.Contar(services.AccionImportacionActualizar),
+			Omitidas:     plan.Contar(services.AccionImportacionOmitir),
+			Errores:      len(plan.Errores),
+			Resultado:    &resultadoTexto,
+		}
+		if usuarioID := c.GetUint("usuarioID"); usuarioID != 0 {
+			importacion.UsuarioID = &usuarioID
+		}
+		if apiKeyID := c.GetUint("apiKeyID"); apiKeyID != 0 {
+			importacion.ApiKeyID = &apiKeyID
+		}
+		return tx.Create(&importacion).Error
+	}
+
+	estado := services.EstadoImportacionSimulada
+	switch {
+	case len(plan.Errores) > 0 && !dryRun:
+		estado = services.EstadoImportacionRechazada
+	case !dryRun:
+		estado = services.EstadoImportacionAplicada
+	}
+
+	if estado == services.EstadoImportacionAplicada {
+		// Todo o nada, incluido el registro en el historial
+		err = configs.DB.WithContext(c).Transaction(func(tx *gorm.DB) error {
+			if err := services.AplicarImportacion(tx, uint(empresaID), c.GetUint("usuarioID"), &plan); err != nil {
+				return err
+			}
+			return guardarImportacion(tx, estado)
+		})
+		if err != nil {
+			HandleError(c, err, http.StatusInternalServerError, "Error, no se pudo aplicar la importación")
+			return
+		}
+	} else if err := guardarImportacion(configs.DB.WithContext(c), estado); err != nil {
+		HandleError(c, err, http.StatusInternalServerError, "Error al guardar el historial de la importación")
+		return
+	}
+
+	response := importacionAResponse(importacion)
+	response.Resultado = &resultado
+
+	if estado == services.EstadoImportacionRechazada {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"error":       "La hoja tiene errores, no se importó ninguna fila",
+			"importacion": response,
+		})
+		return
+	}
+
+	message := "Importación simulada, no se guardaron cambios"
+	if estado == services.EstadoImportacionAplicada {
+		message = "Importación aplicada exitosamente"
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message":     message,
+		"importacion": response,
+	})
+}
+
+// Función para obtener el historial de importaciones de la empresa
+func ObtenerImportaciones(c *gin.Context) {
+	var importaciones []models.Importacion
+	importacionesResponse := []dto.ImportacionResponse{}
+
+	empresaID, err := strconv.ParseUint(c.Param("empresaID"), 10, 64)
+	if err != nil {
+		HandleError(c, nil, http.StatusBadRequest, "ID Empresa inválido")
+		return
+	}
+
+	paginacion, ok := obtenerPaginacion(c, 20, true)
+	if !ok {
+		return
+	}
+
+	// El resultado detallado puede ser grande, se obtiene en el detalle de cada importación
+	query := configs.DB.Omit("resultado").Where("empresa_id = ?", empresaID)
+	if estado := c.Query("estado"); estado != "" {
+		query = query.Where("estado = ?", estado)
+	}
+
+	if err := paginacion.paginar(query, "id DESC", "id", true, &importaciones); err != nil {
+		HandleError(c, err, http.StatusInternalServerError, "Error al obtener el historial de importaciones")
+		return
+	}
+
+	for _, importacion := range importaciones {
+		importacionesResponse = append(importacionesResponse, importacionAResponse(importacion))
+	}
+
+	paginacion.responder(c, "importaciones", importacionesResponse)
+}
+
+// Función para obtener una importación con sus cambios y errores por fila
+func ObtenerImportacion(c *gin.Context) {
+	var importacion models.Importacion
+
+	empresaID, err := strconv.ParseUint(c.Param("empresaID"), 10, 64)
+	if err != nil {
+		HandleError(c, nil, http.StatusBadRequest, "ID Empresa inválido")
+		return
+	}
+
+	importacionID, err := strconv.ParseUint(c.Param("importacionID"), 10, 64)
+	if err != nil {
+		HandleError(c, nil, http.StatusBadRequest, "ID Importación inválido")
+		return
+	}
+
+	if err := configs.DB.Where("empresa_id = ?", empresaID).First(&importacion, importacionID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			HandleError(c, nil, http.StatusNotFound, "Importación no encontrada")
+			return
+		}
+		HandleError(c, err, http.StatusInternalServerError, "Error al obtener la importación")
+		return
+	}
+
+	response := importacionAResponse(importacion)
+	if importacion.Resultado != nil {
+		var resultado dto.ResultadoImportacionResponse
+		if err := json.Unmarshal([]byte(*importacion.Resultado), &resultado); err != nil {
+			HandleError(c, err, http.StatusInternalServerError, "Error al leer el resultado de la importación")
+			return
+		}
+		response.Resultado = &resultado
+	}
+
+	c.JSON(http.StatusOK, gin.H{"importacion": response})
+}
+
+// Leer las prefabricadas de la hoja. La primera fila con datos es el encabezado (los nombres de columna
+// no distinguen mayúsculas ni tildes y las columnas desconocidas se ignoran). Las filas con el mismo slug,
+// o sin slug y con el mismo nombre, son precios de la misma prefabricada. Devuelve también los errores
+// por fila y la cantidad de filas con datos; el error es solo para una hoja sin el encabezado necesario.
+func leerHojaImportacion(filas [][]string) ([]services.PrefabricadaImportada, []services.ErrorImportacion, int, error) {
+	var prefabricadas []services.PrefabricadaImportada
+	var errores []services.ErrorImportacion
+	cantidadFilas := 0
+
+	encabezado := -1
+	for i, fila := range filas {
+		if !filaVacia(fila) {
+			encabezado = i
+			break
+		}
+	}
+	if encabezado < 0 {
+		return nil, nil, 0, errors.New("La hoja está vacía")
+	}
+
+	columnas := map[string]int{}
+	for i, nombre := range filas[encabezado] {
+		nombre = strings.Join(strings.Fields(utils.NormalizarTexto(nombre)), "_")
+		if _, repetida := columnas[nombre]; !repetida && nombre != "" {
+			columnas[nombre] = i
+		}
+	}
+	if _, ok := columnas["nombre_prefabricada"]; !ok {
+		return nil, nil, 0, errors.New("Falta la columna nombre_prefabricada en el encabezado")
+	}
+	_, sincronizarCaracteristicas := columnas["caracteristicas"]
+	_, sincronizarIncluyes := columnas["incluyes"]
+
+	valor := func(fila []string, columna string) string {
+		i, ok := columnas[columna]
+		if !ok || i >= len(fila) {
+			return ""
+		}
+		return strings.TrimSpace(fila[i])
+	}
+
+	grupos := map[string]int{}         // Índice en prefabricadas de cada slug o nombre
+	primeraFila := map[string]string{} // Datos de la prefabricada en su primera fila, para comparar con las siguientes
+	filaDePrecio := map[string]int{}
+
+	for i := encabezado + 1; i < len(filas); i++ {
+		fila := filas[i]
+		if filaVacia(fila) {
+			continue
+		}
+		cantidadFilas++
+		numero := i + 1
+		erroresFila := len(errores)
+		agregarError := func(columna, mensaje string) {
+			errores = append(errores, services.ErrorImportacion{Fila: numero, Columna: columna, Mensaje: mensaje})
+		}
+
+		// Datos de la prefabricada
+		request := dto.CrearPrefabricadaRequest{
+			NombrePrefabricada: valor(fila, "nombre_prefabricada"),
+			Slug:               valor(fila, "slug"),
+			Garantia:           valor(fila, "garantia"),
+			Eslogan:            valor(fila, "eslogan"),
+			Descripcion:        valor(fila, "descripcion"),
+		}
+		columnasInvalidas := map[string]bool{}
+		if texto := valor(fila, "m2"); texto != "" {
+			m2, err := strconv.Atoi(texto)
+			if err != nil {
+				agregarError("m2", "debe ser un número entero")
+				columnasInvalidas["m2"] = true
+			}
+			request.M2 = m2
+		}
+		for columna, destino := range map[string]*uint{"categoria_id": &request.CategoriaID, "estilo_id": &request.EstiloID, "tipo_id": &request.TipoID} {
+			if texto := valor(fila, columna); texto != "" {
+				id, err := strconv.ParseUint(texto, 10, 64)
+				if err != nil {
+					agregarError(columna, "debe ser un ID numérico")
+					columnasInvalidas[columna] = true
+				}
+				*destino = uint(id)
+			}
+		}
+		for columna, destino := range map[string]*bool{"destacada": &request.Destacada, "oferta": &request.Oferta} {
+			b, ok := booleanoImportacion(valor(fila, columna))
+			if !ok {
+				agregarError(columna, "debe ser sí o no")
+			}
+			*destino = b
+		}
+		for _, e := range erroresValidacionImportacion(request) {
+			if !columnasInvalidas[e.Columna] {
+				agregarError(e.Columna, e.Mensaje)
+			}
+		}
+
+		// Características: "Clave: Valor; Clave: Valor"
+		var caracteristicas []services.CaracteristicaImportada
+		claves := map[string]bool{}
+		for _, elemento := range listaImportacion(valor(fila, "caracteristicas")) {
+			partes := strings.SplitN(elemento, services.SeparadorCaracteristicaCatalogo, 2)
+			caracteristica := dto.CrearCaracteristicaRequest{Clave: strings.TrimSpace(partes[0])}
+			if len(partes) == 2 {
+				caracteristica.Valor = strings.TrimSpace(partes[1])
+			}
+			if len(erroresValidacionImportacion(caracteristica)) > 0 {
+				agregarError("caracteristicas", fmt.Sprintf("'%s' debe tener el formato clave%s valor", elemento, services.SeparadorCaracteristicaCatalogo))
+				continue
+			}
+			if claves[strings.ToLower(caracteristica.Clave)] {
+				agregarError("caracteristicas", fmt.Sprintf("la característica '%s' está repetida", caracteristica.Clave))
+				continue
+			}
+			claves[strings.ToLower(caracteristica.Clave)] = true
+			caracteristicas = append(caracteristicas, services.CaracteristicaImportada{Clave: caracteristica.Clave, Valor: caracteristica.Valor})
+		}
+
+		// Precio de la fila (opcional) con sus incluyes: "Ventanas; Puertas"
+		var precio *services.PrecioImportado
+		if valor(fila, "nombre_precio") != "" || valor(fila, "descripcion_precio") != "" || valor(fila, "valor_prefabricada") != "" || valor(fila, "incluyes") != "" {
+			precioRequest := dto.CrearPrecioRequest{
+				NombrePrecio:      valor(fila, "nombre_precio"),
+				DescripcionPrecio: valor(fila, "descripcion_precio"),
+			}
+			valorInvalido := false
+			if texto := valor(fila, "valor_prefabricada"); texto != "" {
+				v, err := strconv.ParseFloat(strings.ReplaceAll(texto, " ", ""), 64)
+				if err != nil {
+					agregarError("valor_prefabricada", "debe ser un número (use punto para los decimales)")
+					valorInvalido = true
+				}
+				precioRequest.ValorPrefabricada = v
+			}
+			for _, e := range erroresValidacionImportacion(precioRequest) {
+				if !(valorInvalido && e.Columna == "valor_prefabricada") {
+					agregarError(e.Columna, e.Mensaje)
+				}
+			}
+
+			precio = &services.PrecioImportado{
+				NombrePrecio:      precioRequest.NombrePrecio,
+				DescripcionPrecio: precioRequest.DescripcionPrecio,
+				ValorPrefabricada: precioRequest.ValorPrefabricada,
+			}
+			for _, nombre := range listaImportacion(valor(fila, "incluyes")) {
+				incluye := dto.CrearIncluyeRequest{NombreIncluye: nombre}
+				for _, e := range erroresValidacionImportacion(incluye) {
+					agregarError(e.Columna, e.Mensaje)
+				}
+				precio.Incluyes = append(precio.Incluyes, incluye.NombreIncluye)
+			}
+		}
+
+		if len(errores) > erroresFila {
+			continue
+		}
+
+		// Agrupar las filas de la misma prefabricada
+		clave := "nombre:" + utils.NormalizarTexto(request.NombrePrefabricada)
+		if request.Slug != "" {
+			clave = "slug:" + strings.ToLower(request.Slug)
+		}
+		datos := fmt.Sprintf("%+v|%+v", request, caracteristicas)
+		indice, existe := grupos[clave]
+		if !existe {
+			indice = len(prefabricadas)
+			grupos[clave] = indice
+			primeraFila[clave] = datos
+			prefabricadas = append(prefabricadas, services.PrefabricadaImportada{
+				Slug:                       request.Slug,
+				NombrePrefabricada:         request.NombrePrefabricada,
+				M2:                         request.M2,
+				Garantia:                   request.Garantia,
+				Eslogan:                    request.Eslogan,
+				Descripcion:                request.Descripcion,
+				Destacada:                  request.Destacada,
+				Oferta:                     request.Oferta,
+				CategoriaID:                request.CategoriaID,
+				EstiloID:                   request.EstiloID,
+				TipoID:                     request.TipoID,
+				Caracteristicas:            caracteristicas,
+				SincronizarCaracteristicas: sincronizarCaracteristicas,
+				SincronizarIncluyes:        sincronizarIncluyes,
+			})
+		} else if primeraFila[clave] != datos {
+			agregarError("", fmt.Sprintf("los datos de la prefabricada no coinciden con los de la fila %d", prefabricadas[indice].Filas[0]))
+			continue
+		}
+
+		if precio != nil {
+			clavePrecio := clave + "|" + strings.ToLower(precio.NombrePrecio)
+			if anterior, repetido := filaDePrecio[clavePrecio]; repetido {
+				agregarError("nombre_precio", fmt.Sprintf("el precio '%s' ya está en la fila %d", precio.NombrePrecio, anterior))
+				continue
+			}
+			filaDePrecio[clavePrecio] = numero
+			prefabricadas[indice].Precios = append(prefabricadas[indice].Precios, *precio)
+		}
+		prefabricadas[indice].Filas = append(prefabricadas[indice].Filas, numero)
+	}
+
+	return prefabricadas, errores, cantidadFilas, nil
+}
+
+// Validar una solicitud con sus reglas de binding y devolver un error por cada campo inválido
+func erroresValidacionImportacion(request interface{}) []services.ErrorImportacion {
+	var errores []services.ErrorImportacion
+	err := binding.Validator.ValidateStruct(request)
+	if err == nil {
+		return nil
+	}
+
+	var erroresCampos validator.ValidationErrors
+	if !errors.As(err, &erroresCampos) {
+		return []services.ErrorImportacion{{Mensaje: err.Error()}}
+	}
+	for _, campo := range erroresCampos {
+		mensaje := "no es válido (" + campo.Tag() + ")"
+		if campo.Tag() == "required" {
+			mensaje = "es obligatorio"
+		}
+		errores = append(errores, services.ErrorImportacion{Columna: columnasImportacion[campo.Field()], Mensaje: mensaje})
+	}
+	return errores
+}
+
+// Elementos no vacíos de una celda con una lista separada por punto y coma
+func listaImportacion(texto string) []string {
+	var elementos []string
+	for _, elemento := range strings.Split(texto, services.SeparadorListaCatalogo) {
+		if elemento = strings.TrimSpace(elemento); elemento != "" {
+			elementos = append(elementos, elemento)
+		}
+	}
+	return elementos
+}
+
+// Interpretar sí/no, true/false o 1/0 (vacío es no)
+func booleanoImportacion(texto string) (bool, bool) {
+	switch utils.NormalizarTexto(texto) {
+	case "", "no", "false", "0", "n":
+		return false, true
+	case "si", "true", "1", "s", "x":
+		return true, true
+	}
+	return false, false
+}
+
+func filaVacia(fila []string) bool {
+	for _, valor := range fila {
+		if strings.TrimSpace(valor) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// Convertir el plan de importación a DTO
+func resultadoImportacionResponse(plan services.PlanImportacion) dto.ResultadoImportacionResponse {
+	resultado := dto.ResultadoImportacionResponse{
+		Cambios: []dto.CambioImportacionResponse{},
+		Errores: []dto.ErrorImportacionResponse{},
+	}
+	for _, cambio := range plan.Cambios {
+		respuesta := dto.CambioImportacionResponse{
+			Filas:              cambio.Filas,
+			Accion:             cambio.Accion,
+			NombrePrefabricada: cambio.NombrePrefabricada,
+			Slug:               cambio.Slug,
+			Detalles:           cambio.Detalles,
+		}
+		if cambio.PrefabricadaID != 0 {
+			id := cambio.PrefabricadaID
+			respuesta.PrefabricadaID = &id
+		}
+		if respuesta.Detalles == nil {
+			respuesta.Detalles = []string{}
+		}
+		resultado.Cambios = append(resultado.Cambios, respuesta)
+	}
+	for _, e := range plan.Errores {
+		resultado.Errores = append(resultado.Errores, dto.ErrorImportacionResponse{Fila: e.Fila, Columna: e.Columna, Mensaje: e.Mensaje})
+	}
+	return resultado
+}
+
+func importacionAResponse(importacion models.Importacion) dto.ImportacionResponse {
+	return dto.ImportacionResponse{
+		ID:           importacion.ID,
+		CreatedAt:    importacion.CreatedAt,
+		EmpresaID:    importacion.EmpresaID,
+		UsuarioID:    importacion.UsuarioID,
+		ApiKeyID:     importacion.ApiKeyID,
+		Archivo:      importacion.Archivo,
+		Formato:      importacion.Formato,
+		DryRun:       importacion.DryRun,
+		Estado:       importacion.Estado,
+		Filas:        importacion.Filas,
+		Creadas:      importacion.Creadas,
+		Actualizadas: importacion.Actualizadas,
+		Omitidas:     importacion.Omitidas,
+		Errores:      importacion.Errores,
+	}
+}
@@ -4,7 +4,6 @@ import (
 	"errors"
 	"net/http"
 	"strconv"
-	"time"
 	"v1_prefabricadas/configs"
 	"v1_prefabricadas/dto"
 	"v1_prefabricadas/models"
@@ -37,7 +36,7 @@ func CrearIncluye(c *gin.Context) {
 	incluye.PrecioID = uint(precioID)
 
 	// Guardamos en la base de datos
-	if err := configs.DB.Create(&incluye).Error; err != nil {
+	if err := configs.DB.WithContext(c).Create(&incluye).Error; err != nil {
 		HandleError(c, err, http.StatusInternalServerError, "Error, no se pudo guardar Incluye")
 		return
 	}
@@ -60,7 +59,7 @@ func CrearIncluye(c *gin.Context) {
 // Función para obtener todos los Incluyes
 func ObtenerIncluyes(c *gin.Context) {
 	var incluyes []models.Incluye
-	var incluyesResponse []dto.IncluyeResponse
+	incluyesResponse := []dto.IncluyeResponse{}
 
 	idParamPrecio := c.Param("precioID")
 	precioID, err := strconv.ParseUint(idParamPrecio, 10, 64)
@@ -69,15 +68,15 @@ func ObtenerIncluyes(c *gin.Context) {
 		return
 	}
 
-	// Buscar todos los incluyes de un precio
-	if err := configs.DB.Where("precio_id = ?", precioID).Where("deleted_at IS NULL").Find(&incluyes).Error; err != nil {
-		HandleError(c, err, http.StatusInternalServerError, "Error al obtener Incluyes")
+	// Parámetros de paginación
+	paginacion, ok := obtenerPaginacion(c, limiteMaximoPaginacion, false)
+	if !ok {
 		return
 	}
 
-	// Verificar si no se encontraron registros
-	if len(incluyes) == 0 {
-		HandleError(c, nil, http.StatusNotFound, "Incluyes no encontrados")
+	// Buscar todos los incluyes de un precio
+	if err := paginacion.paginar(configs.DB.Where("precio_id = ?", precioID).Where("deleted_at IS NULL"), "id ASC", "id", false, &incluyes); err != nil {
+		HandleError(c, err, http.StatusInternalServerError, "Error al obtener Incluyes")
 		return
 	}
 
@@ -92,9 +91,7 @@ func ObtenerIncluyes(c *gin.Context) {
 	}
 
 	// Mostrar/enviar incluyes
-	c.JSON(http.StatusOK, gin.H{
-		"incluyes": incluyesResponse,
-	})
+	paginacion.responder(c, "incluyes", incluyesResponse)
 }
 
 // Función para obtener un incluye de acuerdo a su ID y precio
@@ -180,7 +177,7 @@ func ActualizarIncluye(c *gin.Context) {
 	incluye.NombreIncluye = request.NombreIncluye
 
 	// Guardar los cambios en la base de datos
-	if err := configs.DB.Save(&incluye).Error; err != nil {
+	if err := configs.DB.WithContext(c).Save(&incluye).Error; err != nil {
 		HandleError(c, err, http.StatusBadRequest, "Error, no se pudo actualizar los datos de Incluye")
 		return
 	}
@@ -234,13 +231,8 @@ func EliminarIncluye(c *gin.Context) {
 		return
 	}
 
-	// Poner fecha y hora de la eliminación lógica
-	now := time.Now()
-	incluye.DeletedAt = &now
-
-	// Guardar eliminación lógica en la base de datos
-	if err := configs.DB.Save(&incluye).Error; err != nil {
-		HandleError(c, err, http.StatusInternalServerError, "Error, no se pudo eliminar el Incluye")
+	// Eliminar lógicamente y registrar en la papelera
+	if !enviarAPapelera(c, "incluyes", incluye.ID, "Error, no se pudo eliminar el Incluye") {
 		return
 	}
 
@@ -0,0 +1,118 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+	"v1_prefabricadas/configs"
+	"v1_prefabricadas/dto"
+	"v1_prefabricadas/models"
+	"v1_prefabricadas/services"
+	"v1_prefabricadas/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Función para aceptar una invitación: confirma el email y define la contraseña del usuario
+func AceptarInvitacion(c *gin.Context) {
+	var request dto.AceptarInvitacionRequest
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		HandleError(c, err, http.StatusBadRequest, "Error de datos "+err.Error())
+		return
+	}
+
+	if request.Password != request.ConfirmPassword {
+		HandleError(c, nil, http.StatusBadRequest, "Las contraseñas no coinciden")
+		return
+	}
+
+	// Consumir la invitación, definir la contraseña y verificar el email en una sola transacción
+	err := configs.DB.Transaction(func(tx *gorm.DB) error {
+		invitacion, err := services.ConsumirInvitacion(tx, request.Token, false)
+		if err != nil {
+			return err
+		}
+
+		var credencial models.Credencial
+		if err := tx.First(&credencial, invitacion.CredencialID).Error; err != nil {
+			return err
+		}
+
+		hashedPassword, err := services.HashearPasswordValidada(tx, credencial.ID, request.Password)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		if err := tx.Model(&credencial).Updates(map[string]interface{}{
+			"password":          hashedPassword,
+			"email_verified_at": now,
+		}).Error; err != nil {
+			return err
+		}
+
+		return services.RegistrarHistorialPassword(tx, credencial.ID, hashedPassword)
+	})
+	if err != nil {
+		if errors.Is(err, services.ErrInvitacionInvalida) {
+			HandleError(c, nil, http.StatusBadRequest, "La invitación es inválida o ha expirado")
+			return
+		}
+		HandlePasswordError(c, err, "No se pudo aceptar la invitación")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Cuenta activada correctamente, ya puede iniciar sesión"})
+}
+
+// Función para reenviar la invitación de una credencial que aún no confirma su email
+func ReenviarInvitacion(c *gin.Context) {
+	var credencial models.Credencial
+
+	idParamUsuario := c.Param("usuarioID")
+	usuarioID, err := strconv.ParseUint(idParamUsuario, 10, 64)
+	if err != nil {
+		HandleError(c, nil, http.StatusBadRequest, "ID Usuario inválido")
+		return
+	}
+
+	// No se gestionan las credenciales de usuarios con un rol superior
+	if !puedeGestionarUsuario(c, uint(usuarioID)) {
+		return
+	}
+
+	idParamCredencial := c.Param("credencialID")
+	credencialID, err := strconv.ParseUint(idParamCredencial, 10, 64)
+	if err != nil {
+		HandleError(c, nil, http.StatusBadRequest, "ID Credencial inválido")
+		return
+	}
+
+	if err := configs.DB.Where("usuario_id = ? AND id = ?", usuarioID, credencialID).First(&credencial).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			HandleError(c, nil, http.StatusNotFound, "Credenciales no encontradas")
+			return
+		}
+		HandleError(c, err, http.StatusInternalServerError, "Error al obtener credenciales")
+		return
+	}
+
+	if credencial.EmailVerifiedAt != nil {
+		HandleError(c, nil, http.StatusConflict, "El email de estas credenciales ya fue confirmado")
+		return
+	}
+
+	// La nueva invitación invalida los enlaces enviados anteriormente
+	token, err := services.CrearInvitacion(configs.DB.WithContext(c), credencial.ID)
+	if err != nil {
+		HandleError(c, err, http.StatusInternalServerError, "Error, no se pudo generar la invitación")
+		return
+	}
+
+	go utils.EnviarEmailInvitacion(credencial.Email, services.URLInvitacion(token))
+
+	c.JSON(http.StatusOK, gin.H{"message": "Invitación reenviada exitosamente"})
+}
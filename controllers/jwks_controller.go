@@ -0,0 +1,14 @@
+package controllers
+
+import (
+	"net/http"
+	"v1_prefabricadas/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Función para publicar las llaves públicas con las que se verifican los tokens emitidos por la API
+func ObtenerJWKS(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, services.JWKS())
+}
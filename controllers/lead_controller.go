@@ -0,0 +1,154 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"v1_prefabricadas/configs"
+	"v1_prefabricadas/dto"
+	"v1_prefabricadas/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Función para registrar un lead de la Empresa (normalmente desde el sitio web con una API key con scope leads:write)
+func CrearLead(c *gin.Context) {
+	var request dto.CrearLeadRequest
+
+	idParamEmpresa := c.Param("empresaID")
+	empresaID, err := strconv.ParseUint(idParamEmpresa, 10, 64)
+	if err != nil {
+		HandleError(c, nil, http.StatusBadRequest, "ID Empresa inválido")
+		return
+	}
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		HandleError(c, err, http.StatusBadRequest, "Error de datos "+err.Error())
+		return
+	}
+
+	lead := models.Lead{
+		Nombre:    strings.TrimSpace(request.Nombre),
+		Email:     strings.TrimSpace(request.Email),
+		Telefono:  strings.TrimSpace(request.Telefono),
+		Mensaje:   strings.TrimSpace(request.Mensaje),
+		Origen:    strings.TrimSpace(request.Origen),
+		EmpresaID: uint(empresaID),
+	}
+	if lead.Nombre == "" {
+		HandleError(c, nil, http.StatusBadRequest, "Debe indicar el nombre del interesado")
+		return
+	}
+	if lead.Email == "" && lead.Telefono == "" {
+		HandleError(c, nil, http.StatusBadRequest, "Debe indicar un email o un teléfono de contacto")
+		return
+	}
+
+	// La prefabricada consultada debe ser de la misma Empresa
+	if request.PrefabricadaID != nil {
+		var prefabricadas int64
+		if err := configs.DB.Model(&models.Prefabricada{}).
+			Where("id = ? AND empresa_id = ? AND deleted_at IS NULL", *request.PrefabricadaID, empresaID).
+			Count(&prefabricadas).Error; err != nil {
+			HandleError(c, err, http.StatusInternalServerError, "Error al obtener datos de la Prefabricada")
+			return
+		}
+		if prefabricadas == 0 {
+			HandleError(c, nil, http.StatusBadRequest, "La Prefabricada indicada no existe en la Empresa")
+			return
+		}
+		lead.PrefabricadaID = request.PrefabricadaID
+	}
+
+	if apiKeyID := c.GetUint("apiKeyID"); apiKeyID != 0 {
+		lead.ApiKeyID = &apiKeyID
+	}
+
+	if err := configs.DB.WithContext(c).Create(&lead).Error; err != nil {
+		HandleError(c, err, http.StatusInternalServerError, "Error, no se pudo registrar el lead")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Lead registrado con éxito",
+		"lead":    leadResponse(lead),
+	})
+}
+
+// Función para obtener los leads de una Empresa, del más reciente al más antiguo
+func ObtenerLeads(c *gin.Context) {
+	var leads []models.Lead
+	leadsResponse := []dto.LeadResponse{}
+
+	idParamEmpresa := c.Param("empresaID")
+	empresaID, err := strconv.ParseUint(idParamEmpresa, 10, 64)
+	if err != nil {
+		HandleError(c, nil, http.StatusBadRequest, "ID Empresa inválido")
+		return
+	}
+
+	// Parámetros de paginación
+	paginacion, ok := obtenerPaginacion(c, limiteMaximoPaginacion, true)
+	if !ok {
+		return
+	}
+
+	query := configs.DB.Where("empresa_id = ?", empresaID).Where("deleted_at IS NULL")
+	if err := paginacion.paginar(query, "id DESC", "id", true, &leads); err != nil {
+		HandleError(c, err, http.StatusInternalServerError, "Error al obtener los leads")
+		return
+	}
+
+	for _, lead := range leads {
+		leadsResponse = append(leadsResponse, leadResponse(lead))
+	}
+
+	paginacion.responder(c, "leads", leadsResponse)
+}
+
+// Función para obtener un lead de la Empresa
+func ObtenerLead(c *gin.Context) {
+	var lead models.Lead
+
+	idParamEmpresa := c.Param("empresaID")
+	empresaID, err := strconv.ParseUint(idParamEmpresa, 10, 64)
+	if err != nil {
+		HandleError(c, nil, http.StatusBadRequest, "ID Empresa inválido")
+		return
+	}
+
+	idParamLead := c.Param("leadID")
+	leadID, err := strconv.ParseUint(idParamLead, 10, 64)
+	if err != nil {
+		HandleError(c, nil, http.StatusBadRequest, "ID Lead inválido")
+		return
+	}
+
+	if err := configs.DB.Where("empresa_id = ? AND deleted_at IS NULL", empresaID).First(&lead, leadID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			HandleError(c, nil, http.StatusNotFound, "Lead no encontrado")
+			return
+		}
+		HandleError(c, err, http.StatusInternalServerError, "Error al obtener el lead")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"lead": leadResponse(lead)})
+}
+
+func leadResponse(lead models.Lead) dto.LeadResponse {
+	return dto.LeadResponse{
+		ID:             lead.ID,
+		CreatedAt:      lead.CreatedAt,
+		Nombre:         lead.Nombre,
+		Email:          lead.Email,
+		Telefono:       lead.Telefono,
+		Mensaje:        lead.Mensaje,
+		Origen:         lead.Origen,
+		PrefabricadaID: lead.PrefabricadaID,
+		ApiKeyID:       lead.ApiKeyID,
+		EmpresaID:      lead.EmpresaID,
+	}
+}
@@ -1,26 +1,20 @@
 package controllers
 
 import (
+	"errors"
+	"log"
+	"math"
 	"net/http"
-	"os"
+	"strconv"
 	"time"
 	"v1_prefabricadas/configs"
 	"v1_prefabricadas/models"
+	"v1_prefabricadas/services"
 
 	"github.com/gin-gonic/gin"
-	"github.com/golang-jwt/jwt/v4"
 	"golang.org/x/crypto/bcrypt"
 )
 
-var jwtKey = []byte(os.Getenv("JWT_SECRET")) // Lee la clave secreta desde variables de entorno
-
-// Estructura para las claims del token JWT
-type Claims struct {
-	UsuarioID uint     `json:"usuario_id"`
-	Roles     []string `json:"roles"` // Campo para los roles
-	jwt.RegisteredClaims
-}
-
 // Login: Verificar credenciales y generar un JWT con múltiples roles
 func Login(c *gin.Context) {
 	var credencialRequest models.Credencial
@@ -31,14 +25,22 @@ func Login(c *gin.Context) {
 		return
 	}
 
+	// Rechazar el intento si el email o la IP están bloqueados o en periodo de espera
+	if accesoBloqueado(c, services.AccionLogin, credencialRequest.Email) {
+		return
+	}
+
 	var usuario models.Usuario
 	// Buscar el usuario por email y precargar las relaciones de Credencial y Rol_usuario con Rol
 	if err := configs.DB.
 		Joins("JOIN credenciales ON credenciales.usuario_id = usuarios.id").
 		Where("credenciales.email = ?", credencialRequest.Email).
-		Preload("Credencial").      // Precargar Credencial
-		Preload("Rol_usuario.Rol"). // Precargar Rol_usuario y Rol
+		Where("usuarios.deleted_at IS NULL").         // Los usuarios eliminados no pueden iniciar sesión
+		Preload("Credencial").                        // Precargar Credencial
+		Preload("Rol_usuario", "deleted_at IS NULL"). // Solo las asignaciones de roles vigentes
+		Preload("Rol_usuario.Rol").                   // Precargar Rol
 		First(&usuario).Error; err != nil {
+		registrarIntentoFallido(c, services.AccionLogin, credencialRequest.Email)
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Credenciales inválidas"})
 		return
 	}
@@ -46,43 +48,180 @@ func Login(c *gin.Context) {
 	// Verificar si la contraseña es correcta
 	err := bcrypt.CompareHashAndPassword([]byte(usuario.Credencial.Password), []byte(credencialRequest.Password))
 	if err != nil {
+		registrarIntentoFallido(c, services.AccionLogin, credencialRequest.Email)
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Credenciales inválidas"})
 		return
 	}
 
-	// Extraer los nombres de los roles en un slice
-	var roles []string
-	for _, rolUsuario := range usuario.Rol_usuario {
-		roles = append(roles, rolUsuario.Rol.NombreRol)
+	// La cuenta debe haber confirmado su email aceptando la invitación
+	if usuario.Credencial.EmailVerifiedAt == nil {
+		HandleError(c, nil, http.StatusForbidden, "Debe confirmar su email desde la invitación recibida antes de iniciar sesión")
+		return
 	}
 
-	// Generar el token JWT con usuarioID y roles
-	tokenString, err := generarJWT(usuario.ID, roles)
+	// Acceso correcto: reiniciar los intentos fallidos del email
+	limpiarIntentos(services.AccionLogin, credencialRequest.Email)
+
+	// Si la cuenta usa (o debe usar) segundo factor, se entrega un token de desafío en lugar de la sesión
+	proposito, err := propositoDesafioLogin(usuario)
+	if err != nil {
+		HandleError(c, err, http.StatusInternalServerError, "No se pudo verificar el segundo factor")
+		return
+	}
+	if proposito != "" {
+		responderDesafio(c, usuario.ID, proposito)
+		return
+	}
+
+	emitirSesion(c, usuario, nil)
+}
+
+// Crear la sesión del dispositivo y devolver el access token y el refresh token al cliente,
+// junto con los campos adicionales que se indiquen
+func emitirSesion(c *gin.Context, usuario models.Usuario, extra gin.H) {
+	// Crear la sesión del dispositivo con su refresh token
+	sesion, refreshToken, err := services.CrearSesion(usuario.ID, c.Request.UserAgent(), c.ClientIP())
+	if err != nil {
+		HandleError(c, err, http.StatusInternalServerError, "No se pudo iniciar la sesión")
+		return
+	}
+
+	// Generar el token JWT con usuarioID, empresa y roles
+	tokenString, err := generarJWT(usuario, sesion.ID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "No se pudo generar el token"})
 		return
 	}
 
-	// Devolver el token al cliente
-	c.JSON(http.StatusOK, gin.H{"token": tokenString})
+	// Devolver los tokens al cliente
+	response := gin.H{
+		"token":         tokenString,
+		"refresh_token": refreshToken,
+		"expires_in":    int(services.DuracionAccessToken.Seconds()),
+	}
+	for clave, valor := range extra {
+		response[clave] = valor
+	}
+	c.JSON(http.StatusOK, response)
 }
 
-// Generar un JWT con una duración de 24 horas
-func generarJWT(usuarioID uint, roles []string) (string, error) {
-	claims := &Claims{
-		UsuarioID: usuarioID,
-		Roles:     roles, // Añadir roles a las claims
-		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(24 * time.Hour)),
-			Issuer:    "miApp",
-		},
+// RefrescarToken: Rotar el refresh token y emitir un nuevo access token para la misma sesión
+func RefrescarToken(c *gin.Context) {
+	var request struct {
+		RefreshToken string `json:"refresh_token" binding:"required"`
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenString, err := token.SignedString(jwtKey)
+	if err := c.ShouldBindJSON(&request); err != nil {
+		HandleError(c, err, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	sesion, refreshToken, err := services.RotarRefreshToken(request.RefreshToken, c.Request.UserAgent(), c.ClientIP())
 	if err != nil {
-		return "", err
+		if errors.Is(err, services.ErrRefreshTokenInvalido) || errors.Is(err, services.ErrRefreshTokenReutilizado) {
+			HandleError(c, nil, http.StatusUnauthorized, err.Error())
+			return
+		}
+		HandleError(c, err, http.StatusInternalServerError, "No se pudo refrescar la sesión")
+		return
 	}
 
-	return tokenString, nil
+	// Volver a cargar los roles para que los cambios se reflejen en el nuevo token
+	var usuario models.Usuario
+	if err := configs.DB.
+		Preload("Rol_usuario", "deleted_at IS NULL").
+		Preload("Rol_usuario.Rol").
+		Where("deleted_at IS NULL").
+		First(&usuario, sesion.UsuarioID).Error; err != nil {
+		services.RevocarSesion(sesion.ID, sesion.UsuarioID)
+		HandleError(c, nil, http.StatusUnauthorized, "Usuario no encontrado")
+		return
+	}
+
+	tokenString, err := generarJWT(usuario, sesion.ID)
+	if err != nil {
+		HandleError(c, err, http.StatusInternalServerError, "No se pudo generar el token")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"token":         tokenString,
+		"refresh_token": refreshToken,
+		"expires_in":    int(services.DuracionAccessToken.Seconds()),
+	})
+}
+
+// Logout: Revocar la sesión actual en el servidor
+func Logout(c *gin.Context) {
+	usuarioID := c.GetUint("usuarioID")
+	sesionID := c.GetUint("sesionID")
+
+	if err := services.RevocarSesion(sesionID, usuarioID); err != nil {
+		HandleError(c, err, http.StatusInternalServerError, "No se pudo cerrar la sesión")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Sesión cerrada exitosamente"})
+}
+
+// Verificar si el email o la IP del cliente deben esperar antes de un nuevo intento.
+// Si es así responde 429 con la cabecera Retry-After y devuelve true.
+func accesoBloqueado(c *gin.Context, accion, email string) bool {
+	espera := time.Duration(0)
+	for tipo, valor := range map[string]string{services.TipoBloqueoEmail: email, services.TipoBloqueoIP: c.ClientIP()} {
+		t, err := services.TiempoEsperaAcceso(accion, tipo, valor)
+		if err != nil {
+			HandleError(c, err, http.StatusInternalServerError, "No se pudo verificar el estado de acceso")
+			return true
+		}
+		if t > espera {
+			espera = t
+		}
+	}
+
+	if espera > 0 {
+		segundos := int(math.Ceil(espera.Seconds()))
+		c.Header("Retry-After", strconv.Itoa(segundos))
+		c.JSON(http.StatusTooManyRequests, gin.H{
+			"error":       "Demasiados intentos, vuelva a intentarlo más tarde",
+			"retry_after": segundos,
+		})
+		c.Abort()
+		return true
+	}
+
+	return false
+}
+
+// Registrar un intento fallido tanto para el email como para la IP del cliente
+func registrarIntentoFallido(c *gin.Context, accion, email string) {
+	if err := services.RegistrarIntentoFallido(accion, services.TipoBloqueoEmail, email); err != nil {
+		log.Printf("Error al registrar intento fallido: %v", err)
+	}
+	if err := services.RegistrarIntentoFallido(accion, services.TipoBloqueoIP, c.ClientIP()); err != nil {
+		log.Printf("Error al registrar intento fallido: %v", err)
+	}
+}
+
+// Reiniciar los intentos fallidos del email tras un acceso correcto. Los de la IP no se reinician
+// (vencen con su ventana), ya que si no bastaría con acceder a una cuenta propia entre intentos
+// contra otros emails para evitar el bloqueo por IP.
+func limpiarIntentos(accion, email string) {
+	if err := services.LimpiarIntentos(accion, services.TipoBloqueoEmail, email); err != nil {
+		log.Printf("Error al reiniciar intentos de acceso: %v", err)
+	}
+}
+
+// Extraer los nombres de los roles en un slice
+func obtenerNombresRoles(usuario models.Usuario) []string {
+	var roles []string
+	for _, rolUsuario := range usuario.Rol_usuario {
+		roles = append(roles, rolUsuario.Rol.NombreRol)
+	}
+	return roles
+}
+
+// Generar un JWT de corta duración asociado a una sesión
+func generarJWT(usuario models.Usuario, sesionID uint) (string, error) {
+	return services.GenerarAccessToken(usuario.ID, usuario.EmpresaID, obtenerNombresRoles(usuario), sesionID)
 }
@@ -0,0 +1,304 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+	"v1_prefabricadas/configs"
+	"v1_prefabricadas/dto"
+	"v1_prefabricadas/models"
+	"v1_prefabricadas/services"
+	"v1_prefabricadas/utils"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// Función para obtener los datos del usuario autenticado con su empresa, roles y contactos
+func ObtenerMe(c *gin.Context) {
+	var usuario models.Usuario
+
+	if err := configs.DB.
+		Where("deleted_at IS NULL").
+		Preload("Empresa").
+		Preload("Credencial").
+		Preload("Contacto", "deleted_at IS NULL").
+		Preload("Rol_usuario", "deleted_at IS NULL").
+		Preload("Rol_usuario.Rol").
+		First(&usuario, c.GetUint("usuarioID")).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			HandleError(c, nil, http.StatusNotFound, "Usuario no encontrado")
+			return
+		}
+		HandleError(c, err, http.StatusInternalServerError, "Error al obtener Usuario")
+		return
+	}
+
+	contactosResponse := []dto.ContactoResponse{}
+	for _, contacto := range usuario.Contacto {
+		contactosResponse = append(contactosResponse, dto.ContactoResponse{
+			ID:               contacto.ID,
+			CreatedAt:        contacto.CreatedAt,
+			UpdatedAt:        contacto.UpdatedAt,
+			EmailLaboral:     contacto.EmailLaboral,
+			CelularLaboral:   contacto.CelularLaboral,
+			DireccionLaboral: contacto.DireccionLaboral,
+			UsuarioID:        contacto.UsuarioID,
+		})
+	}
+
+	roles := obtenerNombresRoles(usuario)
+	if roles == nil {
+		roles = []string{}
+	}
+
+	response := dto.MeResponse{
+		Usuario: meUsuarioResponse(usuario),
+		Empresa: dto.EmpresaResponse{
+			ID:                 usuario.Empresa.ID,
+			UpdatedAt:          usuario.Empresa.UpdatedAt,
+			NombreEmpresa:      usuario.Empresa.NombreEmpresa,
+			DescripcionEmpresa: usuario.Empresa.DescripcionEmpresa,
+			HistoriaEmpresa:    usuario.Empresa.HistoriaEmpresa,
+			MisionEmpresa:      usuario.Empresa.MisionEmpresa,
+			VisionEmpresa:      usuario.Empresa.VisionEmpresa,
+			UbicacionEmpresa:   usuario.Empresa.UbicacionEmpresa,
+			CelularEmpresa:     usuario.Empresa.CelularEmpresa,
+			EmailEmpresa:       usuario.Empresa.EmailEmpresa,
+		},
+		Roles:     roles,
+		Contactos: contactosResponse,
+	}
+	if usuario.Credencial != nil {
+		response.Credencial = meCredencialResponse(*usuario.Credencial)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"me": response})
+}
+
+// Función para que el usuario autenticado actualice sus datos de perfil e imagen
+func ActualizarMe(c *gin.Context) {
+	var request dto.ActualizarUsuarioRequest
+	var usuario models.Usuario
+
+	if err := c.ShouldBind(&request); err != nil {
+		HandleError(c, err, http.StatusBadRequest, "Error en los datos del formulario")
+		return
+	}
+
+	if err := configs.DB.Where("deleted_at IS NULL").First(&usuario, c.GetUint("usuarioID")).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			HandleError(c, nil, http.StatusNotFound, "Usuario no encontrado")
+			return
+		}
+		HandleError(c, err, http.StatusInternalServerError, "Error al obtener Usuario")
+		return
+	}
+
+	// Si se proporciona una nueva imagen, subirla a S3
+	if fileHeader, err := c.FormFile("image"); err == nil {
+		file, err := fileHeader.Open()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to open image", "details": err.Error()})
+			return
+		}
+		defer file.Close()
+
+		url, err := services.UploadToS3(file, "imagenes_usuarios", fileHeader.Filename)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to upload image to S3", "details": err.Error()})
+			return
+		}
+		usuario.Image = url
+	}
+
+	usuario.PrimerNombre = request.PrimerNombre
+	usuario.SegundoNombre = request.SegundoNombre
+	usuario.PrimerApellido = request.PrimerApellido
+	usuario.SegundoApellido = request.SegundoApellido
+
+	if err := configs.DB.WithContext(c).Save(&usuario).Error; err != nil {
+		HandleError(c, err, http.StatusInternalServerError, "Error, no se pudo actualizar los datos de Usuario")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Datos de Usuario actualizados con éxito",
+		"usuario": meUsuarioResponse(usuario),
+	})
+}
+
+// Función para que el usuario autenticado cambie su contraseña indicando la actual
+func ActualizarMiPassword(c *gin.Context) {
+	var request dto.ActualizarMiPasswordRequest
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		HandleError(c, err, http.StatusBadRequest, "Error de datos "+err.Error())
+		return
+	}
+
+	if request.PasswordNuevo != request.ConfirmPassword {
+		HandleError(c, nil, http.StatusBadRequest, "Las contraseñas no coinciden")
+		return
+	}
+
+	credencial, ok := credencialConPasswordActual(c, request.PasswordActual)
+	if !ok {
+		return
+	}
+
+	err := configs.DB.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		hashedPassword, err := services.HashearPasswordValidada(tx, credencial.ID, request.PasswordNuevo)
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Model(&credencial).Update("password", hashedPassword).Error; err != nil {
+			return err
+		}
+
+		return services.RegistrarHistorialPassword(tx, credencial.ID, hashedPassword)
+	})
+	if err != nil {
+		HandlePasswordError(c, err, "No se pudo actualizar la contraseña")
+		return
+	}
+
+	// Cerrar las sesiones de los demás dispositivos, la sesión actual se mantiene
+	if err := services.RevocarOtrasSesiones(credencial.UsuarioID, c.GetUint("sesionID")); err != nil {
+		HandleError(c, err, http.StatusInternalServerError, "Error al cerrar las demás sesiones")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Contraseña actualizada correctamente"})
+}
+
+// Función para que el usuario autenticado cambie su email; se envía un enlace de confirmación a la nueva dirección
+func ActualizarMiEmail(c *gin.Context) {
+	var request dto.ActualizarMiEmailRequest
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		HandleError(c, err, http.StatusBadRequest, "Error de datos "+err.Error())
+		return
+	}
+
+	credencial, ok := credencialConPasswordActual(c, request.PasswordActual)
+	if !ok {
+		return
+	}
+
+	if request.Email == credencial.Email {
+		HandleError(c, nil, http.StatusBadRequest, "El email indicado es el actual")
+		return
+	}
+
+	var count int64
+	if err := configs.DB.Model(&models.Credencial{}).Where("email = ?", request.Email).Count(&count).Error; err != nil {
+		HandleError(c, err, http.StatusInternalServerError, "Error al verificar el email")
+		return
+	}
+	if count > 0 {
+		HandleError(c, nil, http.StatusConflict, "El email ya está en uso, intente con otro")
+		return
+	}
+
+	token, err := services.CrearVerificacionEmail(configs.DB, credencial.ID, request.Email)
+	if err != nil {
+		HandleError(c, err, http.StatusInternalServerError, "Error, no se pudo generar la verificación del email")
+		return
+	}
+
+	go utils.EnviarEmailVerificacion(request.Email, services.URLVerificarEmail(token))
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":    "Se envió un enlace de confirmación al nuevo email, el email actual seguirá vigente hasta confirmarlo",
+		"credencial": meCredencialResponse(credencial),
+	})
+}
+
+// Función para confirmar un cambio de email desde el enlace recibido
+func VerificarEmail(c *gin.Context) {
+	var request dto.VerificarEmailRequest
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		HandleError(c, err, http.StatusBadRequest, "Error de datos "+err.Error())
+		return
+	}
+
+	var credencial models.Credencial
+	err := configs.DB.Transaction(func(tx *gorm.DB) error {
+		verificacion, err := services.ConsumirInvitacion(tx, request.Token, true)
+		if err != nil {
+			return err
+		}
+
+		if err := tx.First(&credencial, verificacion.CredencialID).Error; err != nil {
+			return err
+		}
+
+		now := time.Now()
+		credencial.Email = verificacion.EmailNuevo
+		credencial.EmailVerifiedAt = &now
+		return tx.Save(&credencial).Error
+	})
+	if err != nil {
+		if errors.Is(err, services.ErrInvitacionInvalida) {
+			HandleError(c, nil, http.StatusBadRequest, "El enlace es inválido o ha expirado")
+			return
+		}
+		HandleError(c, err, http.StatusInternalServerError, "No se pudo confirmar el email, es posible que ya esté en uso")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":    "Email confirmado correctamente",
+		"credencial": meCredencialResponse(credencial),
+	})
+}
+
+// Cargar la credencial del usuario autenticado verificando su contraseña actual.
+// Los intentos fallidos cuentan para el bloqueo de acceso igual que en el login.
+func credencialConPasswordActual(c *gin.Context, passwordActual string) (models.Credencial, bool) {
+	credencial, ok := credencialUsuarioAutenticado(c)
+	if !ok {
+		return credencial, false
+	}
+
+	if accesoBloqueado(c, services.AccionLogin, credencial.Email) {
+		return credencial, false
+	}
+
+	if bcrypt.CompareHashAndPassword([]byte(credencial.Password), []byte(passwordActual)) != nil {
+		registrarIntentoFallido(c, services.AccionLogin, credencial.Email)
+		HandleError(c, nil, http.StatusUnauthorized, "La contraseña actual es incorrecta")
+		return credencial, false
+	}
+
+	return credencial, true
+}
+
+func meUsuarioResponse(usuario models.Usuario) dto.UsuarioResponse {
+	return dto.UsuarioResponse{
+		ID:              usuario.ID,
+		CreatedAt:       usuario.CreatedAt,
+		UpdatedAt:       usuario.UpdatedAt,
+		PrimerNombre:    usuario.PrimerNombre,
+		SegundoNombre:   usuario.SegundoNombre,
+		PrimerApellido:  usuario.PrimerApellido,
+		SegundoApellido: usuario.SegundoApellido,
+		Image:           usuario.Image,
+		EmpresaID:       usuario.EmpresaID,
+	}
+}
+
+func meCredencialResponse(credencial models.Credencial) dto.CredencialResponse {
+	return dto.CredencialResponse{
+		ID:              credencial.ID,
+		CreatedAt:       credencial.CreatedAt,
+		UpdatedAt:       credencial.UpdatedAt,
+		Email:           credencial.Email,
+		EmailVerifiedAt: credencial.EmailVerifiedAt,
+		UsuarioID:       credencial.UsuarioID,
+	}
+}
@@ -4,10 +4,10 @@ import (
 	"errors"
 	"net/http"
 	"strconv"
-	"time"
 	"v1_prefabricadas/configs"
 	"v1_prefabricadas/dto"
 	"v1_prefabricadas/models"
+	"v1_prefabricadas/services"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
@@ -38,15 +38,31 @@ func CrearNoticia(c *gin.Context) {
 		return
 	}
 
+	// Slug indicado o generado desde el título
+	slug, ok := slugParaGuardar(c, services.EntidadSlugNoticia, uint(empresaID), 0, nil, request.Slug, request.TituloNoticia, true)
+	if !ok {
+		return
+	}
+
+	// Por defecto la Noticia se crea como borrador
+	publicacion, ok := publicacionSolicitada(c, request.Estado, request.PublishAt, request.UnpublishAt)
+	if !ok {
+		return
+	}
+
 	// Creamos la Noticia
+	noticia.Slug = &slug
 	noticia.TituloNoticia = request.TituloNoticia
 	noticia.DesarrolloNoticia = request.DesarrolloNoticia
 	//noticia.UsuarioID = uint(usuarioID)
 	//noticia.UsuarioID = usuarioID
 	noticia.EmpresaID = uint(empresaID)
+	noticia.Estado = publicacion.Estado
+	noticia.PublishAt = publicacion.PublishAt
+	noticia.UnpublishAt = publicacion.UnpublishAt
 
 	// Guardamos la noticia en la base de datos
-	if err := configs.DB.Create(&noticia).Error; err != nil {
+	if err := configs.DB.WithContext(c).Create(&noticia).Error; err != nil {
 		HandleError(c, err, http.StatusInternalServerError, "Error, no se pudo crear la Noticia")
 		return
 	}
@@ -55,8 +71,12 @@ func CrearNoticia(c *gin.Context) {
 		ID:                noticia.ID,
 		CreatedAt:         noticia.CreatedAt,
 		UpdatedAt:         noticia.UpdatedAt,
+		Slug:              valorSlug(noticia.Slug),
 		TituloNoticia:     noticia.TituloNoticia,
 		DesarrolloNoticia: noticia.DesarrolloNoticia,
+		Estado:            noticia.Estado,
+		PublishAt:         noticia.PublishAt,
+		UnpublishAt:       noticia.UnpublishAt,
 		EmpresaID:         noticia.EmpresaID,
 	}
 
@@ -73,21 +93,6 @@ func ObtenerNoticiasEmpresa(c *gin.Context) {
 	var noticias []models.Noticia
 	var noticiasResponse []dto.NoticiaResponse
 
-	// Parámetros de paginación desde la solicitud
-	pageStr := c.Query("page")
-	limitStr := c.Query("limit")
-
-	page, err := strconv.Atoi(pageStr)
-	if err != nil || page < 1 {
-		page = 1
-	}
-	limit, err := strconv.Atoi(limitStr)
-	if err != nil || limit < 1 {
-		limit = 12
-	}
-
-	offset := (page - 1) * limit
-
 	idParamEmpresa := c.Param("empresaID")
 	empresaID, err := strconv.ParseUint(idParamEmpresa, 10, 64)
 	if err != nil {
@@ -95,56 +100,43 @@ func ObtenerNoticiasEmpresa(c *gin.Context) {
 		return
 	}
 
-	// Calcular el total de noticias sin paginación
-	var totalCount int64
-	if err := configs.DB.
-		Model(&models.Noticia{}). // Cambié `Noticia{}` a `models.Noticia{}`
-		Where("deleted_at IS NULL").
-		Where("empresa_id = ?", empresaID).
-		Count(&totalCount).Error; err != nil {
-		HandleError(c, err, http.StatusInternalServerError, "Error al contar las Noticias/Actividas")
+	// Parámetros de paginación desde la solicitud
+	paginacion, ok := obtenerPaginacion(c, 12, true)
+	if !ok {
 		return
 	}
 
-	// Buscar todas las noticias con paginación
-	if err := configs.DB.
+	// Buscar todas las noticias con paginación (las más recientes primero)
+	query := configs.DB.
 		Where("deleted_at IS NULL").
-		Where("empresa_id = ?", empresaID).
-		Order("created_at DESC").
-		Limit(limit).
-		Offset(offset).
-		Find(&noticias).Error; err != nil {
-		HandleError(c, err, http.StatusInternalServerError, "Error al obtener las Noticias/Actividas")
-		return
+		Where("empresa_id = ?", empresaID)
+	if soloPublicados(c) {
+		query = services.FiltrarPublicados(query, "noticias")
 	}
-
-	// Verificar si no hay noticias
-	if len(noticias) == 0 {
-		HandleError(c, nil, http.StatusNotFound, "Sin Noticias/Actividades por el momento")
+	if err := paginacion.paginar(query, "created_at DESC, id DESC", "id", true, &noticias); err != nil {
+		HandleError(c, err, http.StatusInternalServerError, "Error al obtener las Noticias/Actividas")
 		return
 	}
 
 	// Crear la respuesta
+	noticiasResponse = []dto.NoticiaResponse{}
 	for _, noticia := range noticias {
 		noticiasResponse = append(noticiasResponse, dto.NoticiaResponse{
 			ID:                noticia.ID,
 			CreatedAt:         noticia.CreatedAt,
 			UpdatedAt:         noticia.UpdatedAt,
+			Slug:              valorSlug(noticia.Slug),
 			TituloNoticia:     noticia.TituloNoticia,
 			DesarrolloNoticia: noticia.DesarrolloNoticia,
+			Estado:            noticia.Estado,
+			PublishAt:         noticia.PublishAt,
+			UnpublishAt:       noticia.UnpublishAt,
 			EmpresaID:         noticia.EmpresaID,
 		})
 	}
 
 	// Retornar las noticias junto con información de paginación
-	c.JSON(http.StatusOK, gin.H{
-		"noticias": noticiasResponse, // Enviar el array `noticiasResponse` con el DTO
-		"pagination": gin.H{
-			"page":  page,
-			"limit": limit,
-			"total": totalCount,
-		},
-	})
+	paginacion.responder(c, "noticias", noticiasResponse)
 }
 
 // Función para obterner una noticia especifica de una Empresa
@@ -159,15 +151,18 @@ func ObtenerNoticiaEmpresa(c *gin.Context) {
 		return
 	}
 
-	idParamNoticia := c.Param("noticiaID")
-	noticiaID, err := strconv.ParseUint(idParamNoticia, 10, 64)
-	if err != nil {
-		HandleError(c, nil, http.StatusBadRequest, "ID Noticia no válido")
+	// La Noticia se puede obtener por su ID o por su slug
+	noticiaID, ok := resolverIDOSlug(c, "noticiaID", services.EntidadSlugNoticia, uint(empresaID), "Noticia no encontrada")
+	if !ok {
 		return
 	}
 
 	// Buscar en la base de datos noticia de acuerdo al id de la empresa y al id de la noticia
-	if err := configs.DB.Where("deleted_at IS NULL").Where("empresa_id = ? AND id = ?", empresaID, noticiaID).First(&noticia).Error; err != nil {
+	query := configs.DB.Where("deleted_at IS NULL").Where("empresa_id = ? AND id = ?", empresaID, noticiaID)
+	if soloPublicados(c) {
+		query = services.FiltrarPublicados(query, "noticias")
+	}
+	if err := query.First(&noticia).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			HandleError(c, nil, http.StatusNotFound, "Noticia no encontrada")
 			return
@@ -180,8 +175,12 @@ func ObtenerNoticiaEmpresa(c *gin.Context) {
 		ID:                noticia.ID,
 		CreatedAt:         noticia.CreatedAt,
 		UpdatedAt:         noticia.UpdatedAt,
+		Slug:              valorSlug(noticia.Slug),
 		TituloNoticia:     noticia.TituloNoticia,
 		DesarrolloNoticia: noticia.DesarrolloNoticia,
+		Estado:            noticia.Estado,
+		PublishAt:         noticia.PublishAt,
+		UnpublishAt:       noticia.UnpublishAt,
 		EmpresaID:         noticia.EmpresaID,
 	}
 
@@ -288,6 +287,13 @@ func ActualizarNoticia(c *gin.Context) {
 	// 	return
 	// }
 
+	idParamEmpresa := c.Param("empresaID")
+	empresaID, err := strconv.ParseUint(idParamEmpresa, 10, 64)
+	if err != nil {
+		HandleError(c, nil, http.StatusBadRequest, "ID Empresa inválido")
+		return
+	}
+
 	idParamNoticia := c.Param("noticiaID")
 	noticiaID, err := strconv.ParseUint(idParamNoticia, 10, 64)
 	if err != nil {
@@ -302,7 +308,7 @@ func ActualizarNoticia(c *gin.Context) {
 	}
 
 	// Buacamos la noticia en la bade de datos
-	if err := configs.DB.Where("id = ?", noticiaID).Where("deleted_at IS NULL").First(&noticia).Error; err != nil {
+	if err := configs.DB.Where("empresa_id = ? AND id = ?", empresaID, noticiaID).Where("deleted_at IS NULL").First(&noticia).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			HandleError(c, nil, http.StatusNotFound, "Noticia no encontrada")
 			return
@@ -312,13 +318,20 @@ func ActualizarNoticia(c *gin.Context) {
 	}
 
 	// Actualizar los datos de la Noticia
-	if request.TituloNoticia != "" {
-		noticia.TituloNoticia = request.TituloNoticia
-	} else {
+	if request.TituloNoticia == "" {
 		HandleError(c, nil, http.StatusBadRequest, "El Título de la Noticia no debe estar vacio")
 		return
 	}
 
+	// Al cambiar el título se genera un nuevo slug, salvo que se indique uno
+	slugAnterior := noticia.Slug
+	slug, ok := slugParaGuardar(c, services.EntidadSlugNoticia, noticia.EmpresaID, noticia.ID, slugAnterior, request.Slug, request.TituloNoticia, request.TituloNoticia != noticia.TituloNoticia)
+	if !ok {
+		return
+	}
+	noticia.Slug = &slug
+	noticia.TituloNoticia = request.TituloNoticia
+
 	if request.DesarrolloNoticia != "" {
 		noticia.DesarrolloNoticia = request.DesarrolloNoticia
 	} else {
@@ -326,8 +339,14 @@ func ActualizarNoticia(c *gin.Context) {
 		return
 	}
 
-	// Guardar en la base de datos
-	if err := configs.DB.Save(&noticia).Error; err != nil {
+	// Guardar en la base de datos, conservando el slug anterior para redirigir las URLs antiguas
+	err = configs.DB.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&noticia).Error; err != nil {
+			return err
+		}
+		return services.RegistrarCambioSlug(tx, services.EntidadSlugNoticia, noticia.EmpresaID, noticia.ID, slugAnterior, slug)
+	})
+	if err != nil {
 		HandleError(c, err, http.StatusInternalServerError, "Error al tratar de actualizar los datos de la Noticia")
 		return
 	}
@@ -336,8 +355,12 @@ func ActualizarNoticia(c *gin.Context) {
 		ID:                noticia.ID,
 		CreatedAt:         noticia.CreatedAt,
 		UpdatedAt:         noticia.UpdatedAt,
+		Slug:              valorSlug(noticia.Slug),
 		TituloNoticia:     noticia.TituloNoticia,
 		DesarrolloNoticia: noticia.DesarrolloNoticia,
+		Estado:            noticia.Estado,
+		PublishAt:         noticia.PublishAt,
+		UnpublishAt:       noticia.UnpublishAt,
 		EmpresaID:         noticia.EmpresaID,
 	}
 
@@ -365,6 +388,13 @@ func EliminarNoticia(c *gin.Context) {
 	// 	return
 	// }
 
+	idParamEmpresa := c.Param("empresaID")
+	empresaID, err := strconv.ParseUint(idParamEmpresa, 10, 64)
+	if err != nil {
+		HandleError(c, nil, http.StatusBadRequest, "ID Empresa inválido")
+		return
+	}
+
 	idParamNoticia := c.Param("noticiaID")
 	noticiaID, err := strconv.ParseUint(idParamNoticia, 10, 64)
 	if err != nil {
@@ -373,7 +403,7 @@ func EliminarNoticia(c *gin.Context) {
 	}
 
 	// Buscar la Noticia en la base de datos de acuerdo al ID de la Noticia y al ID del Usuario que la Creo
-	if err := configs.DB.Where("id = ?", noticiaID).Where("deleted_at IS NULL").First(&noticia).Error; err != nil {
+	if err := configs.DB.Where("empresa_id = ? AND id = ?", empresaID, noticiaID).Where("deleted_at IS NULL").First(&noticia).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			HandleError(c, nil, http.StatusNotFound, "Noticia no encontrada")
 			return
@@ -382,13 +412,8 @@ func EliminarNoticia(c *gin.Context) {
 		return
 	}
 
-	// Poner Fecha y Hora de la eliminación lógica
-	now := time.Now()
-	noticia.DeletedAt = &now
-
-	// Guardar en la base da datos la fecha y hora de la eliminación lógica
-	if err := configs.DB.Save(&noticia).Error; err != nil {
-		HandleError(c, err, http.StatusInternalServerError, "Error, no se pudo eliminar la Noticia")
+	// Enviar a la papelera junto con los registros que dependen de él
+	if !enviarAPapelera(c, "noticias", noticia.ID, "Error, no se pudo eliminar la Noticia") {
 		return
 	}
 
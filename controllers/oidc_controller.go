@@ -0,0 +1,320 @@
+package controllers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"v1_prefabricadas/configs"
+	"v1_prefabricadas/dto"
+	"v1_prefabricadas/models"
+	"v1_prefabricadas/services"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Función para iniciar el login con OpenID Connect (Google Workspace)
+func IniciarLoginOIDC(c *gin.Context) {
+	iniciarOIDC(c, services.PropositoOIDCLogin, 0)
+}
+
+// Función para completar el login con OpenID Connect: emite los mismos tokens que Login
+func LoginOIDC(c *gin.Context) {
+	var request dto.CompletarOIDCRequest
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		HandleError(c, err, http.StatusBadRequest, "Error de datos "+err.Error())
+		return
+	}
+
+	identidad, _, err := services.CompletarOIDC(request.Code, request.State, request.StateToken, services.PropositoOIDCLogin)
+	if err != nil {
+		handleErrorOIDC(c, err)
+		return
+	}
+
+	// Buscar primero por la identidad ya vinculada y luego por email
+	var credencial models.Credencial
+	err = configs.DB.Where("oidc_issuer = ? AND oidc_subject = ?", identidad.Issuer, identidad.Subject).First(&credencial).Error
+	vinculada := err == nil
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		err = configs.DB.Where("email = ?", identidad.Email).First(&credencial).Error
+	}
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			HandleError(c, nil, http.StatusUnauthorized, "No existe una cuenta asociada a este email")
+			return
+		}
+		HandleError(c, err, http.StatusInternalServerError, "Error al obtener credenciales")
+		return
+	}
+
+	var usuario models.Usuario
+	if err := configs.DB.
+		Where("deleted_at IS NULL").
+		Preload("Credencial").
+		Preload("Rol_usuario", "deleted_at IS NULL").
+		Preload("Rol_usuario.Rol").
+		First(&usuario, credencial.UsuarioID).Error; err != nil {
+		HandleError(c, nil, http.StatusUnauthorized, "Usuario no encontrado")
+		return
+	}
+
+	configuracion, ok := configuracionOIDCPermitida(c, usuario.EmpresaID, identidad)
+	if !ok {
+		return
+	}
+
+	if !vinculada {
+		if credencial.OIDCSubject != nil {
+			HandleError(c, nil, http.StatusForbidden, "La cuenta ya está vinculada a otra identidad externa")
+			return
+		}
+		if !configuracion.VinculacionAutomatica {
+			HandleError(c, nil, http.StatusForbidden, "Debe vincular su cuenta desde su perfil antes de usarla para iniciar sesión")
+			return
+		}
+	}
+
+	// Vincular la identidad y, si el email coincide, darlo por verificado ya que lo verificó el proveedor
+	if err := vincularIdentidadOIDC(&credencial, identidad); err != nil {
+		HandleError(c, err, http.StatusInternalServerError, "Error, no se pudo vincular la cuenta")
+		return
+	}
+	usuario.Credencial = &credencial
+
+	if credencial.EmailVerifiedAt == nil {
+		HandleError(c, nil, http.StatusForbidden, "Debe confirmar su email desde la invitación recibida antes de iniciar sesión")
+		return
+	}
+
+	// El segundo factor se exige igual que en el login con contraseña
+	proposito, err := propositoDesafioLogin(usuario)
+	if err != nil {
+		HandleError(c, err, http.StatusInternalServerError, "No se pudo verificar el segundo factor")
+		return
+	}
+	if proposito != "" {
+		responderDesafio(c, usuario.ID, proposito)
+		return
+	}
+
+	emitirSesion(c, usuario, nil)
+}
+
+// Función para iniciar la vinculación de una cuenta externa con el usuario autenticado
+func IniciarVincularOIDC(c *gin.Context) {
+	iniciarOIDC(c, services.PropositoOIDCVincular, c.GetUint("usuarioID"))
+}
+
+// Función para completar la vinculación de una cuenta externa con el usuario autenticado
+func VincularOIDC(c *gin.Context) {
+	var request dto.CompletarOIDCRequest
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		HandleError(c, err, http.StatusBadRequest, "Error de datos "+err.Error())
+		return
+	}
+
+	identidad, estado, err := services.CompletarOIDC(request.Code, request.State, request.StateToken, services.PropositoOIDCVincular)
+	if err != nil {
+		handleErrorOIDC(c, err)
+		return
+	}
+	if estado.UsuarioID != c.GetUint("usuarioID") {
+		HandleError(c, nil, http.StatusUnauthorized, services.ErrOIDCEstadoInvalido.Error())
+		return
+	}
+
+	credencial, ok := credencialUsuarioAutenticado(c)
+	if !ok {
+		return
+	}
+
+	// La cuenta externa debe corresponder al mismo email de la credencial
+	if !strings.EqualFold(credencial.Email, identidad.Email) {
+		HandleError(c, nil, http.StatusForbidden, "El email de la cuenta externa no coincide con el de su usuario")
+		return
+	}
+
+	if _, ok := configuracionOIDCPermitida(c, c.GetUint("empresaID"), identidad); !ok {
+		return
+	}
+
+	var count int64
+	if err := configs.DB.Model(&models.Credencial{}).
+		Where("oidc_issuer = ? AND oidc_subject = ? AND id <> ?", identidad.Issuer, identidad.Subject, credencial.ID).
+		Count(&count).Error; err != nil {
+		HandleError(c, err, http.StatusInternalServerError, "Error al verificar la cuenta externa")
+		return
+	}
+	if count > 0 {
+		HandleError(c, nil, http.StatusConflict, "La cuenta externa ya está vinculada a otro usuario")
+		return
+	}
+
+	if err := vincularIdentidadOIDC(&credencial, identidad); err != nil {
+		HandleError(c, err, http.StatusInternalServerError, "Error, no se pudo vincular la cuenta")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Cuenta externa vinculada exitosamente"})
+}
+
+// Función para desvincular la cuenta externa del usuario autenticado
+func DesvincularOIDC(c *gin.Context) {
+	credencial, ok := credencialUsuarioAutenticado(c)
+	if !ok {
+		return
+	}
+
+	if err := configs.DB.Model(&credencial).Updates(map[string]interface{}{
+		"oidc_issuer":  nil,
+		"oidc_subject": nil,
+	}).Error; err != nil {
+		HandleError(c, err, http.StatusInternalServerError, "Error, no se pudo desvincular la cuenta")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Cuenta externa desvinculada exitosamente"})
+}
+
+// Función para obtener la configuración de OpenID Connect de una Empresa
+func ObtenerConfiguracionOIDC(c *gin.Context) {
+	idParamEmpresa := c.Param("empresaID")
+	empresaID, err := strconv.ParseUint(idParamEmpresa, 10, 64)
+	if err != nil {
+		HandleError(c, nil, http.StatusBadRequest, "ID Empresa inválido")
+		return
+	}
+
+	configuracion, err := services.ObtenerConfiguracionOIDCEmpresa(uint(empresaID))
+	if err != nil {
+		HandleError(c, err, http.StatusInternalServerError, "Error al obtener la configuración OIDC")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"configuracion": configuracionOIDCResponse(configuracion)})
+}
+
+// Función para actualizar la configuración de OpenID Connect de una Empresa
+func ActualizarConfiguracionOIDC(c *gin.Context) {
+	var request dto.ConfiguracionOIDCRequest
+
+	idParamEmpresa := c.Param("empresaID")
+	empresaID, err := strconv.ParseUint(idParamEmpresa, 10, 64)
+	if err != nil {
+		HandleError(c, nil, http.StatusBadRequest, "ID Empresa inválido")
+		return
+	}
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		HandleError(c, err, http.StatusBadRequest, "Error de datos "+err.Error())
+		return
+	}
+
+	var dominios []string
+	for _, dominio := range request.DominiosPermitidos {
+		dominios = append(dominios, strings.ToLower(strings.TrimSpace(dominio)))
+	}
+	if *request.Habilitado && len(dominios) == 0 {
+		HandleError(c, nil, http.StatusBadRequest, "Debe indicar al menos un dominio permitido para habilitar el inicio de sesión externo")
+		return
+	}
+
+	configuracion := models.Configuracion_oidc{
+		Habilitado:            *request.Habilitado,
+		DominiosPermitidos:    strings.Join(dominios, ","),
+		VinculacionAutomatica: *request.VinculacionAutomatica,
+		EmpresaID:             uint(empresaID),
+	}
+	if err := configs.DB.WithContext(c).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "empresa_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"habilitado", "dominios_permitidos", "vinculacion_automatica", "updated_at"}),
+	}).Create(&configuracion).Error; err != nil {
+		HandleError(c, err, http.StatusInternalServerError, "Error, no se pudo guardar la configuración OIDC")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":       "Configuración OIDC actualizada exitosamente",
+		"configuracion": configuracionOIDCResponse(configuracion),
+	})
+}
+
+func iniciarOIDC(c *gin.Context, proposito string, usuarioID uint) {
+	authorizationURL, stateToken, err := services.IniciarOIDC(proposito, usuarioID)
+	if err != nil {
+		handleErrorOIDC(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.IniciarOIDCResponse{
+		AuthorizationURL: authorizationURL,
+		StateToken:       stateToken,
+	})
+}
+
+// Verificar que la empresa tenga habilitado el login externo y que el dominio del email esté permitido
+func configuracionOIDCPermitida(c *gin.Context, empresaID uint, identidad services.IdentidadOIDC) (models.Configuracion_oidc, bool) {
+	configuracion, err := services.ObtenerConfiguracionOIDCEmpresa(empresaID)
+	if err != nil {
+		HandleError(c, err, http.StatusInternalServerError, "Error al obtener la configuración OIDC")
+		return configuracion, false
+	}
+	if !configuracion.Habilitado {
+		HandleError(c, nil, http.StatusForbidden, "El inicio de sesión externo no está habilitado para su empresa")
+		return configuracion, false
+	}
+	if !services.DominioPermitido(configuracion, identidad) {
+		HandleError(c, nil, http.StatusForbidden, "El dominio de la cuenta externa no está permitido para su empresa")
+		return configuracion, false
+	}
+	return configuracion, true
+}
+
+// Guardar la identidad externa en la credencial y verificar el email si coincide con el del proveedor
+func vincularIdentidadOIDC(credencial *models.Credencial, identidad services.IdentidadOIDC) error {
+	cambios := map[string]interface{}{
+		"oidc_issuer":  identidad.Issuer,
+		"oidc_subject": identidad.Subject,
+	}
+	if credencial.EmailVerifiedAt == nil && strings.EqualFold(credencial.Email, identidad.Email) {
+		now := time.Now()
+		cambios["email_verified_at"] = now
+	}
+	return configs.DB.Model(credencial).Updates(cambios).Error
+}
+
+func handleErrorOIDC(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrOIDCNoConfigurado):
+		HandleError(c, nil, http.StatusServiceUnavailable, err.Error())
+	case errors.Is(err, services.ErrOIDCEstadoInvalido),
+		errors.Is(err, services.ErrOIDCTokenInvalido),
+		errors.Is(err, services.ErrOIDCEmailNoVerificado):
+		log.Printf("Error OIDC: %v", err)
+		HandleError(c, nil, http.StatusUnauthorized, err.Error())
+	default:
+		HandleError(c, err, http.StatusBadGateway, "No se pudo comunicar con el proveedor de identidad")
+	}
+}
+
+func configuracionOIDCResponse(configuracion models.Configuracion_oidc) dto.ConfiguracionOIDCResponse {
+	dominios := []string{}
+	for _, dominio := range strings.Split(configuracion.DominiosPermitidos, ",") {
+		if dominio != "" {
+			dominios = append(dominios, dominio)
+		}
+	}
+	return dto.ConfiguracionOIDCResponse{
+		Habilitado:            configuracion.Habilitado,
+		DominiosPermitidos:    dominios,
+		VinculacionAutomatica: configuracion.VinculacionAutomatica,
+		EmpresaID:             configuracion.EmpresaID,
+	}
+}
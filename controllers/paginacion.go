@@ -0,0 +1,224 @@
+package controllers
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const limiteMaximoPaginacion = 100 // Máximo de elementos por página aunque se pida un limit mayor
+
+// Parámetros y resultado de la paginación de un listado.
+// En modo página se usan page y limit; en modo cursor (parámetro cursor, vacío para la primera página)
+// se recorre la tabla por ID sin OFFSET ni conteo, lo que es más eficiente en tablas grandes.
+type paginacion struct {
+	Page            int
+	Limit           int
+	Total           int64
+	modoCursor      bool
+	cursor          uint // ID del último elemento de la página anterior (0 en la primera página)
+	siguienteCursor string
+	// Listados que antes enviaban page, limit y total en la raíz de la respuesta; se siguen enviando
+	// ahí (además de en "pagination") para no romper a los clientes existentes
+	metadatosEnRaiz bool
+}
+
+// Obtener page, limit y cursor de la URL. Si el parámetro es inválido responde 400 y devuelve false.
+// permiteCursor indica si el listado admite el modo cursor.
+func obtenerPaginacion(c *gin.Context, limitPorDefecto int, permiteCursor bool) (*paginacion, bool) {
+	p := &paginacion{Page: 1, Limit: limitPorDefecto}
+
+	if valor := c.Query("page"); valor != "" {
+		page, err := strconv.Atoi(valor)
+		if err != nil || page < 1 {
+			HandleError(c, nil, http.StatusBadRequest, "Parámetro page inválido")
+			return nil, false
+		}
+		p.Page = page
+	}
+
+	if valor := c.Query("limit"); valor != "" {
+		limit, err := strconv.Atoi(valor)
+		if err != nil || limit < 1 {
+			HandleError(c, nil, http.StatusBadRequest, "Parámetro limit inválido")
+			return nil, false
+		}
+		p.Limit = limit
+	}
+	if p.Limit > limiteMaximoPaginacion {
+		p.Limit = limiteMaximoPaginacion
+	}
+
+	if valor, ok := c.GetQuery("cursor"); ok {
+		if !permiteCursor {
+			HandleError(c, nil, http.StatusBadRequest, "Este listado no admite paginación por cursor")
+			return nil, false
+		}
+		if c.Query("page") != "" {
+			HandleError(c, nil, http.StatusBadRequest, "No se puede usar page junto con cursor")
+			return nil, false
+		}
+		p.modoCursor = true
+		if valor != "" {
+			id, err := decodificarCursor(valor)
+			if err != nil {
+				HandleError(c, nil, http.StatusBadRequest, "Parámetro cursor inválido")
+				return nil, false
+			}
+			p.cursor = id
+		}
+	}
+
+	return p, true
+}
+
+// Ejecutar la consulta paginada sobre destino (puntero a un slice de modelos con campo ID).
+// En modo página cuenta el total y aplica el orden indicado; en modo cursor ordena por columnaID
+// (descendente si se indica) y obtiene los elementos siguientes al cursor.
+func (p *paginacion) paginar(query *gorm.DB, orden, columnaID string, descendente bool, destino interface{}) error {
+	if !p.modoCursor {
+		// El conteo no debe precargar relaciones
+		conteo := query.Session(&gorm.Session{}).Model(destino)
+		conteo.Statement.Preloads = map[string][]interface{}{}
+		if err := conteo.Count(&p.Total).Error; err != nil {
+			return err
+		}
+		if orden != "" {
+			query = query.Order(orden)
+		}
+		return query.Limit(p.Limit).Offset((p.Page - 1) * p.Limit).Find(destino).Error
+	}
+
+	direccion, comparacion := "ASC", ">"
+	if descendente {
+		direccion, comparacion = "DESC", "<"
+	}
+	if p.cursor > 0 {
+		query = query.Where(columnaID+" "+comparacion+" ?", p.cursor)
+	}
+
+	// Se pide un elemento extra para saber si hay una página siguiente
+	if err := query.Order(columnaID + " " + direccion).Limit(p.Limit + 1).Find(destino).Error; err != nil {
+		return err
+	}
+
+	elementos := reflect.ValueOf(destino).Elem()
+	if elementos.Len() > p.Limit {
+		elementos.Set(elementos.Slice(0, p.Limit))
+		ultimo := reflect.Indirect(elementos.Index(p.Limit - 1)).FieldByName("ID")
+		if !ultimo.IsValid() {
+			return errors.New("el listado paginado por cursor requiere un campo ID")
+		}
+		p.siguienteCursor = codificarCursor(uint(ultimo.Uint()))
+	}
+	return nil
+}
+
+// Responder el listado con la información de paginación y las cabeceras Link (RFC 8288)
+func (p *paginacion) responder(c *gin.Context, clave string, elementos interface{}) {
+	metadatos := gin.H{"limit": p.Limit}
+	var enlaces []string
+
+	if p.modoCursor {
+		var siguiente interface{}
+		if p.siguienteCursor != "" {
+			siguiente = p.url(c, map[string]string{"cursor": p.siguienteCursor})
+			enlaces = append(enlaces, fmt.Sprintf(`<%s>; rel="next"`, siguiente))
+		}
+		metadatos["next_cursor"] = nullSiVacio(p.siguienteCursor)
+		metadatos["next"] = siguiente
+		metadatos["prev"] = nil
+		enlaces = append(enlaces, fmt.Sprintf(`<%s>; rel="first"`, p.url(c, map[string]string{"cursor": ""})))
+	} else {
+		totalPaginas := int(math.Ceil(float64(p.Total) / float64(p.Limit)))
+		metadatos["page"] = p.Page
+		metadatos["total"] = p.Total
+		metadatos["total_pages"] = totalPaginas
+
+		var siguiente, anterior interface{}
+		if p.Page < totalPaginas {
+			siguiente = p.url(c, map[string]string{"page": strconv.Itoa(p.Page + 1)})
+			enlaces = append(enlaces, fmt.Sprintf(`<%s>; rel="next"`, siguiente))
+		}
+		if p.Page > 1 {
+			paginaAnterior := p.Page - 1
+			if totalPaginas > 0 && paginaAnterior > totalPaginas {
+				paginaAnterior = totalPaginas
+			}
+			anterior = p.url(c, map[string]string{"page": strconv.Itoa(paginaAnterior)})
+			enlaces = append(enlaces, fmt.Sprintf(`<%s>; rel="prev"`, anterior))
+		}
+		metadatos["next"] = siguiente
+		metadatos["prev"] = anterior
+
+		enlaces = append(enlaces, fmt.Sprintf(`<%s>; rel="first"`, p.url(c, map[string]string{"page": "1"})))
+		if totalPaginas > 0 {
+			enlaces = append(enlaces, fmt.Sprintf(`<%s>; rel="last"`, p.url(c, map[string]string{"page": strconv.Itoa(totalPaginas)})))
+		}
+	}
+
+	respuesta := gin.H{
+		clave:        elementos,
+		"pagination": metadatos,
+	}
+	if p.metadatosEnRaiz {
+		for _, campo := range []string{"page", "limit", "total"} {
+			if valor, ok := metadatos[campo]; ok {
+				respuesta[campo] = valor
+			}
+		}
+	}
+
+	c.Header("Link", strings.Join(enlaces, ", "))
+	c.JSON(http.StatusOK, respuesta)
+}
+
+// Construir la URL de la solicitud actual con el limit efectivo y los parámetros indicados.
+// Es absoluta si se configuró API_BASE_URL (URL pública de la API); si no, es relativa
+// a la raíz. Nunca se usa la cabecera Host, que la controla el cliente.
+func (p *paginacion) url(c *gin.Context, parametros map[string]string) string {
+	valores := c.Request.URL.Query()
+	valores.Set("limit", strconv.Itoa(p.Limit))
+	for clave, valor := range parametros {
+		valores.Set(clave, valor)
+	}
+
+	u := url.URL{
+		Path:     c.Request.URL.Path,
+		RawQuery: valores.Encode(),
+	}
+	return strings.TrimSuffix(os.Getenv("API_BASE_URL"), "/") + u.String()
+}
+
+func codificarCursor(id uint) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.FormatUint(uint64(id), 10)))
+}
+
+func decodificarCursor(cursor string) (uint, error) {
+	valor, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, err
+	}
+	id, err := strconv.ParseUint(string(valor), 10, 64)
+	if err != nil || id == 0 {
+		return 0, errors.New("cursor inválido")
+	}
+	return uint(id), nil
+}
+
+func nullSiVacio(valor string) interface{} {
+	if valor == "" {
+		return nil
+	}
+	return valor
+}
@@ -0,0 +1,180 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"v1_prefabricadas/configs"
+	"v1_prefabricadas/dto"
+	"v1_prefabricadas/models"
+	"v1_prefabricadas/services"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Función para listar los elementos eliminados que aún se pueden restaurar
+func ObtenerPapelera(c *gin.Context) {
+	var entradas []models.Papelera
+	papeleraResponse := []dto.PapeleraResponse{}
+
+	// Parámetros de paginación desde la solicitud
+	paginacion, ok := obtenerPaginacion(c, 50, true)
+	if !ok {
+		return
+	}
+
+	dias, err := services.DiasRetencionPapelera()
+	if err != nil {
+		HandleError(c, err, http.StatusInternalServerError, "Error al obtener la configuración de la papelera")
+		return
+	}
+
+	query := papeleraDelUsuario(c, configs.DB.Model(&models.Papelera{}))
+	if empresaID := c.Query("empresa_id"); empresaID != "" && esSuperAdministrador(c) {
+		query = query.Where("empresa_id = ?", empresaID)
+	}
+	if entidad := c.Query("entidad"); entidad != "" {
+		query = query.Where("entidad = ?", entidad)
+	}
+
+	if err := paginacion.paginar(query, "eliminado_at DESC, id DESC", "id", true, &entradas); err != nil {
+		HandleError(c, err, http.StatusInternalServerError, "Error al obtener los elementos de la papelera")
+		return
+	}
+
+	for _, entrada := range entradas {
+		papeleraResponse = append(papeleraResponse, dto.PapeleraResponse{
+			ID:                    entrada.ID,
+			EmpresaID:             entrada.EmpresaID,
+			Entidad:               entrada.Entidad,
+			EntidadID:             entrada.EntidadID,
+			Nombre:                entrada.Nombre,
+			UsuarioID:             entrada.UsuarioID,
+			EliminadoAt:           entrada.EliminadoAt,
+			EliminacionDefinitiva: entrada.EliminadoAt.AddDate(0, 0, dias),
+		})
+	}
+
+	paginacion.responder(c, "papelera", papeleraResponse)
+}
+
+// Función para restaurar un elemento de la papelera junto con lo que se eliminó con él
+func RestaurarElementoPapelera(c *gin.Context) {
+	var entrada models.Papelera
+
+	papeleraID, err := strconv.ParseUint(c.Param("papeleraID"), 10, 64)
+	if err != nil {
+		HandleError(c, nil, http.StatusBadRequest, "ID Papelera inválido")
+		return
+	}
+
+	if err := papeleraDelUsuario(c, configs.DB).First(&entrada, papeleraID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			HandleError(c, nil, http.StatusNotFound, "Elemento no encontrado en la papelera")
+			return
+		}
+		HandleError(c, err, http.StatusInternalServerError, "Error al obtener el elemento de la papelera")
+		return
+	}
+
+	if err := services.RestaurarDePapelera(configs.DB.WithContext(c), entrada); err != nil {
+		switch {
+		case errors.Is(err, services.ErrPadreEnPapelera):
+			HandleError(c, nil, http.StatusConflict, err.Error())
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			HandleError(c, nil, http.StatusNotFound, "El elemento ya no existe")
+		default:
+			HandleError(c, err, http.StatusInternalServerError, "Error, no se pudo restaurar el elemento")
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":    "Elemento restaurado exitosamente",
+		"entidad":    entrada.Entidad,
+		"entidad_id": entrada.EntidadID,
+	})
+}
+
+// Función para obtener los días que se conservan los elementos de la papelera
+func ObtenerConfiguracionPapelera(c *gin.Context) {
+	dias, err := services.DiasRetencionPapelera()
+	if err != nil {
+		HandleError(c, err, http.StatusInternalServerError, "Error al obtener la configuración de la papelera")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"configuracion": dto.ConfiguracionPapeleraResponse{DiasRetencion: dias}})
+}
+
+// Función para actualizar los días que se conservan los elementos de la papelera
+func ActualizarConfiguracionPapelera(c *gin.Context) {
+	var request dto.ConfiguracionPapeleraRequest
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		HandleError(c, err, http.StatusBadRequest, "Error de datos "+err.Error())
+		return
+	}
+
+	if err := services.GuardarConfiguracion(services.ConfigDiasRetencionPapelera, strconv.Itoa(request.DiasRetencion)); err != nil {
+		HandleError(c, err, http.StatusInternalServerError, "Error, no se pudo guardar la configuración de la papelera")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":       "Configuración de la papelera actualizada exitosamente",
+		"configuracion": dto.ConfiguracionPapeleraResponse{DiasRetencion: request.DiasRetencion},
+	})
+}
+
+// Enviar a la papelera un registro de la empresa de la ruta. Si hay un error ya respondió y devuelve false.
+func enviarAPapelera(c *gin.Context, entidad string, id uint, mensajeError string) bool {
+	empresaID, err := strconv.ParseUint(c.Param("empresaID"), 10, 64)
+	if err != nil {
+		HandleError(c, nil, http.StatusBadRequest, "ID Empresa inválido")
+		return false
+	}
+	return enviarAPapeleraDeEmpresa(c, entidad, id, uint(empresaID), mensajeError)
+}
+
+// Enviar a la papelera de la empresa indicada un registro cuya ruta no incluye la empresa
+func enviarAPapeleraDeEmpresa(c *gin.Context, entidad string, id, empresaID uint, mensajeError string) bool {
+	if err := services.EnviarAPapelera(configs.DB.WithContext(c), entidad, id, empresaID, c.GetUint("usuarioID")); err != nil {
+		HandleError(c, err, http.StatusInternalServerError, mensajeError)
+		return false
+	}
+	return true
+}
+
+// Eliminar lógicamente un catálogo global (no pasa por la papelera). Si está en uso responde 409.
+// Si hay un error ya respondió y devuelve false.
+func eliminarCatalogoGlobal(c *gin.Context, tabla string, id uint, mensajeError string) bool {
+	if err := services.EliminarCatalogoGlobal(configs.DB.WithContext(c), tabla, id); err != nil {
+		var enUso *services.ErrEntidadEnUso
+		if errors.As(err, &enUso) {
+			HandleError(c, nil, http.StatusConflict, "No se puede eliminar mientras existan registros que lo usan ("+enUso.Tabla+"), reasígnelos o elimínelos primero")
+			return false
+		}
+		HandleError(c, err, http.StatusInternalServerError, mensajeError)
+		return false
+	}
+	return true
+}
+
+// Los usuarios que no son super administradores solo ven la papelera de su empresa
+func papeleraDelUsuario(c *gin.Context, query *gorm.DB) *gorm.DB {
+	if esSuperAdministrador(c) {
+		return query
+	}
+	return query.Where("empresa_id = ?", c.GetUint("empresaID"))
+}
+
+func esSuperAdministrador(c *gin.Context) bool {
+	for _, rol := range c.GetStringSlice("roles") {
+		if rol == services.RolSuperAdministrador {
+			return true
+		}
+	}
+	return false
+}
@@ -1,15 +1,17 @@
 package controllers
 
 import (
+	"errors"
+	"log"
 	"net/http"
 	"time"
 	"v1_prefabricadas/configs"
 	"v1_prefabricadas/models"
+	"v1_prefabricadas/services"
 	"v1_prefabricadas/utils"
 
 	"github.com/gin-gonic/gin"
-	"github.com/google/uuid"
-	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
 )
 
 // Solicitar recuperación de contraseña
@@ -23,42 +25,48 @@ func SolicitarRecuperacion(c *gin.Context) {
 		return
 	}
 
+	// Limitar las solicitudes por email e IP para evitar el envío masivo de correos
+	if accesoBloqueado(c, services.AccionRecuperacion, request.Email) {
+		return
+	}
+	registrarIntentoFallido(c, services.AccionRecuperacion, request.Email)
+
+	// La respuesta es la misma exista o no la cuenta, para no revelar qué emails están registrados
+	respuesta := gin.H{"message": "Si el email está registrado recibirá las instrucciones para recuperar su contraseña"}
+
 	var usuario models.Usuario
 	if err := configs.DB.
 		Preload("Credencial"). // Preload de la relación Credencial
 		Where("credenciales.email = ?", request.Email).
+		Where("usuarios.deleted_at IS NULL").
 		Joins("JOIN credenciales ON credenciales.usuario_id = usuarios.id").
 		First(&usuario).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Usuario no encontrado"})
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			log.Printf("Error al buscar usuario para recuperación: %v", err)
+		}
+		c.JSON(http.StatusOK, respuesta)
 		return
 	}
 
-	token := uuid.NewString()
-	expiration := time.Now().Add(15 * time.Minute)
-
-	recuperacion := models.Recuperacion{
-		Token:     token,
-		UsuarioID: usuario.ID,
-		ExpiresAt: expiration,
-	}
-	if err := configs.DB.Create(&recuperacion).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al generar el token"})
+	// Emitir un token nuevo invalidando los anteriores
+	token, err := services.CrearTokenRecuperacion(usuario.ID)
+	if err != nil {
+		HandleError(c, err, http.StatusInternalServerError, "Error al generar el token")
 		return
 	}
 
 	// Este link aparecera en el correo enviado por es sistema
-	// Ruta desarrollo local path: '/reset-password/:token'
-	link := "https://vifrontendcasasemilia-production.up.railway.app/reset-password/" + token
-	go utils.EnviarEmailRecuperacion(request.Email, link)
+	// La base se configura con RESET_PASSWORD_URL (desarrollo local path: '/reset-password/:token')
+	go utils.EnviarEmailRecuperacion(request.Email, services.URLRecuperacion(token))
 
-	c.JSON(http.StatusOK, gin.H{"message": "Email enviado con las instrucciones"})
+	c.JSON(http.StatusOK, respuesta)
 }
 
 // Cambiar contraseña
 func CambiarContrasena(c *gin.Context) {
 	var request struct {
 		Token        string `json:"token" binding:"required"`
-		NuevaClave   string `json:"nueva_clave" binding:"required,min=8"`
+		NuevaClave   string `json:"nueva_clave" binding:"required"`
 		ConfirmClave string `json:"confirm_clave" binding:"required"`
 	}
 
@@ -72,25 +80,53 @@ func CambiarContrasena(c *gin.Context) {
 		return
 	}
 
-	var recuperacion models.Recuperacion
-	if err := configs.DB.Where("token = ?", request.Token).First(&recuperacion).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Token inválido"})
-		return
-	}
-
-	if time.Now().After(recuperacion.ExpiresAt) {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "El token ha expirado"})
-		return
-	}
-
-	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte(request.NuevaClave), bcrypt.DefaultCost)
-	if err := configs.DB.Model(&models.Credencial{}).
-		Where("usuario_id = ?", recuperacion.UsuarioID).
-		Update("password", string(hashedPassword)).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "No se pudo actualizar la contraseña"})
+	// Consumir el token, cambiar la contraseña y cortar los accesos anteriores en una sola transacción
+	err := configs.DB.Transaction(func(tx *gorm.DB) error {
+		recuperacion, err := services.ConsumirTokenRecuperacion(tx, request.Token)
+		if err != nil {
+			return err
+		}
+
+		var credencial models.Credencial
+		if err := tx.Where("usuario_id = ?", recuperacion.UsuarioID).First(&credencial).Error; err != nil {
+			return err
+		}
+
+		// La contraseña nueva debe cumplir la misma política que al crear o editar credenciales
+		hashedPassword, err := services.HashearPasswordValidada(tx, credencial.ID, request.NuevaClave)
+		if err != nil {
+			return err
+		}
+
+		// Recibir el enlace en el email también confirma que la cuenta le pertenece
+		cambios := map[string]interface{}{"password": hashedPassword}
+		if credencial.EmailVerifiedAt == nil {
+			cambios["email_verified_at"] = time.Now()
+		}
+		if err := tx.Model(&credencial).Updates(cambios).Error; err != nil {
+			return err
+		}
+
+		if err := services.RegistrarHistorialPassword(tx, credencial.ID, hashedPassword); err != nil {
+			return err
+		}
+
+		// Invalidar cualquier otro enlace de recuperación pendiente
+		if err := services.InvalidarRecuperacionesUsuario(tx, recuperacion.UsuarioID); err != nil {
+			return err
+		}
+
+		// Cerrar todas las sesiones abiertas con la contraseña anterior
+		return services.RevocarSesionesUsuario(tx, recuperacion.UsuarioID)
+	})
+	if err != nil {
+		if errors.Is(err, services.ErrTokenRecuperacionInvalido) {
+			HandleError(c, nil, http.StatusBadRequest, "El token es inválido o ha expirado")
+			return
+		}
+		HandlePasswordError(c, err, "No se pudo actualizar la contraseña")
 		return
 	}
 
-	configs.DB.Delete(&recuperacion)
 	c.JSON(http.StatusOK, gin.H{"message": "Contraseña actualizada correctamente"})
 }
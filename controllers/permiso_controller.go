@@ -0,0 +1,161 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"v1_prefabricadas/configs"
+	"v1_prefabricadas/dto"
+	"v1_prefabricadas/models"
+	"v1_prefabricadas/services"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Función para obtener todos los permisos disponibles
+func ObtenerPermisos(c *gin.Context) {
+	var permisos []models.Permiso
+
+	// Parámetros de paginación
+	paginacion, ok := obtenerPaginacion(c, limiteMaximoPaginacion, false)
+	if !ok {
+		return
+	}
+
+	if err := paginacion.paginar(configs.DB.Where("deleted_at IS NULL"), "nombre_permiso, id", "id", false, &permisos); err != nil {
+		HandleError(c, err, http.StatusInternalServerError, "Error al obtener los Permisos")
+		return
+	}
+
+	// Mostrar/enviar Permisos
+	paginacion.responder(c, "permisos", permisosResponse(permisos))
+}
+
+// Función para obtener los permisos asignados a un Rol
+func ObtenerPermisosRol(c *gin.Context) {
+	var rol models.Rol
+
+	idParamRol := c.Param("rolID")
+	rolID, err := strconv.ParseUint(idParamRol, 10, 64)
+	if err != nil {
+		HandleError(c, nil, http.StatusBadRequest, "ID Rol inválido")
+		return
+	}
+
+	// Parámetros de paginación
+	paginacion, ok := obtenerPaginacion(c, limiteMaximoPaginacion, false)
+	if !ok {
+		return
+	}
+
+	if err := configs.DB.Where("deleted_at IS NULL").First(&rol, rolID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			HandleError(c, nil, http.StatusNotFound, "Rol no encontrado")
+			return
+		}
+		HandleError(c, err, http.StatusInternalServerError, "Error al obtener el Rol")
+		return
+	}
+
+	// Permisos del Rol paginados en la consulta
+	var permisos []models.Permiso
+	query := configs.DB.
+		Joins("JOIN roles_permisos ON roles_permisos.permiso_id = permisos.id").
+		Where("roles_permisos.rol_id = ? AND permisos.deleted_at IS NULL", rol.ID)
+	if err := paginacion.paginar(query, "permisos.nombre_permiso, permisos.id", "permisos.id", false, &permisos); err != nil {
+		HandleError(c, err, http.StatusInternalServerError, "Error al obtener los Permisos del Rol")
+		return
+	}
+
+	// Mostrar/enviar Permisos del Rol
+	paginacion.responder(c, "permisos", permisosResponse(permisos))
+}
+
+// Función para reemplazar los permisos asignados a un Rol
+func AsignarPermisosRol(c *gin.Context) {
+	var request dto.AsignarPermisosRequest
+	var rol models.Rol
+	var permisos []models.Permiso
+
+	idParamRol := c.Param("rolID")
+	rolID, err := strconv.ParseUint(idParamRol, 10, 64)
+	if err != nil {
+		HandleError(c, nil, http.StatusBadRequest, "ID Rol inválido")
+		return
+	}
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		HandleError(c, err, http.StatusBadRequest, "Error de datos "+err.Error())
+		return
+	}
+
+	if err := configs.DB.Where("deleted_at IS NULL").First(&rol, rolID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			HandleError(c, nil, http.StatusNotFound, "Rol no encontrado")
+			return
+		}
+		HandleError(c, err, http.StatusInternalServerError, "Error al obtener el Rol")
+		return
+	}
+
+	// Verificar que todos los permisos existan
+	if len(request.Permisos) > 0 {
+		if err := configs.DB.Where("deleted_at IS NULL").Where("id IN ?", request.Permisos).Find(&permisos).Error; err != nil {
+			HandleError(c, err, http.StatusInternalServerError, "Error al obtener los Permisos")
+			return
+		}
+	}
+	if len(permisos) != len(request.Permisos) {
+		HandleError(c, nil, http.StatusBadRequest, "Uno o más permisos no existen")
+		return
+	}
+
+	// Solo se modifican roles que estén al alcance de quien los modifica, y con permisos que tenga
+	nombresPermisos := make([]string, 0, len(permisos))
+	for _, permiso := range permisos {
+		nombresPermisos = append(nombresPermisos, permiso.NombrePermiso)
+	}
+	if !puedeOtorgarRoles(c, rol.ID) || !puedeOtorgarPermisos(c, nombresPermisos) {
+		return
+	}
+
+	// Permisos actuales para dejar constancia del cambio en la auditoría
+	var permisosAnteriores []uint
+	if err := configs.DB.Table("roles_permisos").Where("rol_id = ?", rol.ID).Pluck("permiso_id", &permisosAnteriores).Error; err != nil {
+		HandleError(c, err, http.StatusInternalServerError, "Error al obtener los Permisos del Rol")
+		return
+	}
+
+	// Reemplazar los permisos del Rol y registrar el cambio en una sola transacción
+	err = configs.DB.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&rol).Association("Permiso").Replace(permisos); err != nil {
+			return err
+		}
+		return services.RegistrarAuditoria(tx, c, "roles_permisos", rol.ID, services.AccionAuditoriaEditar,
+			map[string]interface{}{"permisos": permisosAnteriores},
+			map[string]interface{}{"permisos": request.Permisos})
+	})
+	if err != nil {
+		HandleError(c, err, http.StatusInternalServerError, "Error, no se pudo actualizar los permisos del Rol")
+		return
+	}
+
+	// Mostrar/enviar mensaje de éxito y los permisos asignados
+	c.JSON(http.StatusOK, gin.H{
+		"message":  "Permisos del Rol actualizados exitosamente",
+		"permisos": permisosResponse(permisos),
+	})
+}
+
+func permisosResponse(permisos []models.Permiso) []dto.PermisoResponse {
+	response := []dto.PermisoResponse{}
+	for _, permiso := range permisos {
+		response = append(response, dto.PermisoResponse{
+			ID:                 permiso.ID,
+			NombrePermiso:      permiso.NombrePermiso,
+			DescripcionPermiso: permiso.DescripcionPermiso,
+		})
+	}
+	return response
+}
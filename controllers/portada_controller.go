@@ -4,7 +4,6 @@ import (
 	"errors"
 	"net/http"
 	"strconv"
-	"time"
 	"v1_prefabricadas/configs"
 	"v1_prefabricadas/dto"
 	"v1_prefabricadas/models"
@@ -35,6 +34,12 @@ func CrearPortada(c *gin.Context) {
 		return
 	}
 
+	// Por defecto la Portada se crea como borrador
+	publicacion, ok := publicacionSolicitada(c, request.Estado, request.PublishAt, request.UnpublishAt)
+	if !ok {
+		return
+	}
+
 	// Manejo de la imagen de perfil
 	fileHeader, err := c.FormFile("image")
 	if err != nil {
@@ -60,12 +65,15 @@ func CrearPortada(c *gin.Context) {
 	portada := models.Portada{
 		NombrePortada: request.NombrePortada,
 		//Image:         request.Image,
-		Image:     url,
-		EmpresaID: uint(empresaID),
+		Image:       url,
+		EmpresaID:   uint(empresaID),
+		Estado:      publicacion.Estado,
+		PublishAt:   publicacion.PublishAt,
+		UnpublishAt: publicacion.UnpublishAt,
 	}
 
 	// Agregamos Portada a la base de Datos
-	if err := configs.DB.Create(&portada).Error; err != nil {
+	if err := configs.DB.WithContext(c).Create(&portada).Error; err != nil {
 		HandleError(c, err, http.StatusInternalServerError, "No se pudo crear la Portada")
 		return
 	}
@@ -77,6 +85,9 @@ func CrearPortada(c *gin.Context) {
 		UpdatedAt:     portada.UpdatedAt,
 		NombrePortada: portada.NombrePortada,
 		Image:         portada.Image,
+		Estado:        portada.Estado,
+		PublishAt:     portada.PublishAt,
+		UnpublishAt:   portada.UnpublishAt,
 		EmpresaID:     portada.EmpresaID,
 	}
 
@@ -90,7 +101,8 @@ func CrearPortada(c *gin.Context) {
 // Función para obtener todas las Portadas
 func ObtenerPortadas(c *gin.Context) {
 	var portadas []models.Portada
-	var portadasResponse []dto.PortadaResponse
+	portadasResponse := []dto.PortadaResponse{}
+
 	idParam := c.Param("empresaID")
 	empresaID, err := strconv.ParseUint(idParam, 10, 64)
 	if err != nil {
@@ -98,8 +110,18 @@ func ObtenerPortadas(c *gin.Context) {
 		return
 	}
 
+	// Parámetros de paginación
+	paginacion, ok := obtenerPaginacion(c, limiteMaximoPaginacion, false)
+	if !ok {
+		return
+	}
+
 	// Buscamos todas las portadas de la empresa
-	if err := configs.DB.Where("empresa_id = ?", empresaID).Where("deleted_at IS NULL").Find(&portadas).Error; err != nil {
+	query := configs.DB.Where("empresa_id = ?", empresaID).Where("deleted_at IS NULL")
+	if soloPublicados(c) {
+		query = services.FiltrarPublicados(query, "portadas")
+	}
+	if err := paginacion.paginar(query, "id ASC", "id", false, &portadas); err != nil {
 		HandleError(c, err, http.StatusInternalServerError, "Portdas no encontradas")
 		return
 	}
@@ -111,12 +133,15 @@ func ObtenerPortadas(c *gin.Context) {
 			UpdatedAt:     portada.UpdatedAt,
 			NombrePortada: portada.NombrePortada,
 			Image:         portada.Image,
+			Estado:        portada.Estado,
+			PublishAt:     portada.PublishAt,
+			UnpublishAt:   portada.UnpublishAt,
 			EmpresaID:     portada.EmpresaID,
 		})
 	}
 
 	// Mostrar/enviar portadas
-	c.JSON(http.StatusOK, gin.H{"portadas": portadasResponse})
+	paginacion.responder(c, "portadas", portadasResponse)
 
 }
 
@@ -142,7 +167,11 @@ func ObtenerPortada(c *gin.Context) {
 	}
 
 	// Buscar la portada en la base de datos de acuerdo al ID enviado desde el path
-	if err := configs.DB.Where("empresa_id = ?", empresaID).Where("deleted_At IS NULL").First(&portada, portadaID).Error; err != nil {
+	query := configs.DB.Where("empresa_id = ?", empresaID).Where("deleted_At IS NULL")
+	if soloPublicados(c) {
+		query = services.FiltrarPublicados(query, "portadas")
+	}
+	if err := query.First(&portada, portadaID).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			HandleError(c, nil, http.StatusNotFound, "Portada no encontrado")
 			return
@@ -157,6 +186,9 @@ func ObtenerPortada(c *gin.Context) {
 		UpdatedAt:     portada.UpdatedAt,
 		NombrePortada: portada.NombrePortada,
 		Image:         portada.Image,
+		Estado:        portada.Estado,
+		PublishAt:     portada.PublishAt,
+		UnpublishAt:   portada.UnpublishAt,
 	}
 
 	// Responder/enviar Response con éxito
@@ -226,7 +258,7 @@ func ActualizarPortada(c *gin.Context) {
 	portada.NombrePortada = request.NombrePortada
 
 	// Guardar los cambios en la base de datos
-	if err := configs.DB.Save(&portada).Error; err != nil {
+	if err := configs.DB.WithContext(c).Save(&portada).Error; err != nil {
 		HandleError(c, nil, http.StatusInternalServerError, "Error: No se pudo actualizar los datos de la Portada")
 		return
 	}
@@ -238,6 +270,9 @@ func ActualizarPortada(c *gin.Context) {
 		UpdatedAt:     portada.UpdatedAt,
 		NombrePortada: portada.NombrePortada,
 		Image:         portada.Image,
+		Estado:        portada.Estado,
+		PublishAt:     portada.PublishAt,
+		UnpublishAt:   portada.UnpublishAt,
 		EmpresaID:     portada.EmpresaID,
 	}
 
@@ -282,13 +317,9 @@ func EliminarPortada(c *gin.Context) {
 		return
 	}
 
-	// Poner fecha y hora de la eliminación lógica
-	now := time.Now()
-	portada.DeletedAt = &now
-
-	// Guardar en la base de datos
-	if err := configs.DB.Save(&portada).Error; err != nil {
-		HandleError(c, err, http.StatusInternalServerError, "Error, no se pudo eliminar la Portada")
+	// Eliminar lógicamente y registrar en la papelera
+	if !enviarAPapelera(c, "portadas", portada.ID, "Error, no se pudo eliminar la Portada") {
+		return
 	}
 
 	// Mostrar mensaje de éxito de la eliminación lógica
@@ -4,7 +4,6 @@ import (
 	"errors"
 	"net/http"
 	"strconv"
-	"time"
 	"v1_prefabricadas/configs"
 	"v1_prefabricadas/dto"
 	"v1_prefabricadas/models"
@@ -39,7 +38,7 @@ func CrearPrecio(c *gin.Context) {
 	precio.PrefabricadaID = uint(prefabricadaID)
 
 	// Guardamos el precio en la base de datos
-	if err := configs.DB.Create(&precio).Error; err != nil {
+	if err := configs.DB.WithContext(c).Create(&precio).Error; err != nil {
 		HandleError(c, err, http.StatusInternalServerError, "Error, no se pudo guardar el Precio")
 		return
 	}
@@ -64,7 +63,7 @@ func CrearPrecio(c *gin.Context) {
 // Función para obtener todos los precios de una prefabricada
 func ObtenerPrecios(c *gin.Context) {
 	var precios []models.Precio
-	var preciosResponse []dto.PrecioResponse
+	preciosResponse := []dto.PrecioResponse{}
 
 	idParamPrefabricada := c.Param("prefabricadaID")
 	prefabricadaID, err := strconv.ParseUint(idParamPrefabricada, 10, 64)
@@ -73,14 +72,19 @@ func ObtenerPrecios(c *gin.Context) {
 		return
 	}
 
+	// Parámetros de paginación
+	paginacion, ok := obtenerPaginacion(c, limiteMaximoPaginacion, false)
+	if !ok {
+		return
+	}
+
 	// Buscar todos los precios en la base de datos
-	if err := configs.DB.
+	if err := paginacion.paginar(configs.DB.
 		Preload("Incluye", func(db *gorm.DB) *gorm.DB {
 			return db.Where("deleted_at IS NULL") // Condición para no cargar "incluye" eliminados lógicamente
 		}).
 		Where("prefabricada_id = ?", prefabricadaID).
-		Where("deleted_at IS NULL").
-		Find(&precios).Error; err != nil {
+		Where("deleted_at IS NULL"), "id ASC", "id", false, &precios); err != nil {
 		HandleError(c, err, http.StatusInternalServerError, "Precios no encontrados")
 		return
 	}
@@ -112,7 +116,7 @@ func ObtenerPrecios(c *gin.Context) {
 	}
 
 	// Mostrar/enviar precios
-	c.JSON(http.StatusOK, gin.H{"precios": preciosResponse})
+	paginacion.responder(c, "precios", preciosResponse)
 }
 
 // Función para obtener un solo Precio de acuerdo al ID
@@ -200,7 +204,7 @@ func ActualizarPrecio(c *gin.Context) {
 	precio.ValorPrefabricada = request.ValorPrefabricada
 
 	// Guardar datos actualizados en la base de datos
-	if err := configs.DB.Save(&precio).Error; err != nil {
+	if err := configs.DB.WithContext(c).Save(&precio).Error; err != nil {
 		HandleError(c, err, http.StatusBadRequest, "Error, no se pudo actualizar la información"+err.Error())
 	}
 
@@ -255,13 +259,8 @@ func EliminarPrecio(c *gin.Context) {
 		return
 	}
 
-	// Poner fecha y hora de la eliminación lógica
-	now := time.Now()
-	precio.DeletedAt = &now
-
-	// Guardar eliminación lógica en la base de datos
-	if err := configs.DB.Save(&precio).Error; err != nil {
-		HandleError(c, err, http.StatusInternalServerError, "Error, no se pudo eliminar el Precio")
+	// Enviar a la papelera junto con los registros que dependen de él
+	if !enviarAPapelera(c, "precios", precio.ID, "Error, no se pudo eliminar el Precio") {
 		return
 	}
 
@@ -2,12 +2,15 @@ package controllers
 
 import (
 	"errors"
+	"io"
+	"log"
 	"net/http"
 	"strconv"
-	"time"
+	"strings"
 	"v1_prefabricadas/configs"
 	"v1_prefabricadas/dto"
 	"v1_prefabricadas/models"
+	"v1_prefabricadas/services"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
@@ -31,7 +34,20 @@ func CrearPrefabricada(c *gin.Context) {
 		return
 	}
 
+	// Slug indicado o generado desde el nombre
+	slug, ok := slugParaGuardar(c, services.EntidadSlugPrefabricada, uint(empresaID), 0, nil, request.Slug, request.NombrePrefabricada, true)
+	if !ok {
+		return
+	}
+
+	// Por defecto la Prefabricada se crea como borrador
+	publicacion, ok := publicacionSolicitada(c, request.Estado, request.PublishAt, request.UnpublishAt)
+	if !ok {
+		return
+	}
+
 	// Crear la Prefabricada
+	prefabricada.Slug = &slug
 	prefabricada.NombrePrefabricada = request.NombrePrefabricada
 	prefabricada.M2 = request.M2
 	prefabricada.Garantia = request.Garantia
@@ -43,15 +59,20 @@ func CrearPrefabricada(c *gin.Context) {
 	prefabricada.EmpresaID = uint(empresaID)
 	prefabricada.EstiloID = request.EstiloID
 	prefabricada.TipoID = request.TipoID
+	prefabricada.Estado = publicacion.Estado
+	prefabricada.PublishAt = publicacion.PublishAt
+	prefabricada.UnpublishAt = publicacion.UnpublishAt
 
 	// Guardar en la base de datos la Prefabricada
-	if err := configs.DB.Create(&prefabricada).Error; err != nil {
-		HandleError(c, err, http.StatusInternalServerError, "Error, no se pudo crear la Prefabricada")
+	if err := configs.DB.WithContext(c).Create(&prefabricada).Error; err != nil {
+		handleErrorSlug(c, services.ErrorSlugDuplicado(configs.DB, err), "Error, no se pudo crear la Prefabricada")
+		return
 	}
 
 	// Response
 	prefabricadaResponse = dto.PrefabricadaResponse{
 		ID:                 prefabricada.ID,
+		Slug:               valorSlug(prefabricada.Slug),
 		NombrePrefabricada: prefabricada.NombrePrefabricada,
 		M2:                 prefabricada.M2,
 		Garantia:           prefabricada.Garantia,
@@ -63,6 +84,9 @@ func CrearPrefabricada(c *gin.Context) {
 		EmpresaID:          prefabricada.EmpresaID,
 		EstiloID:           prefabricada.EstiloID,
 		TipoID:             prefabricada.TipoID,
+		Estado:             prefabricada.Estado,
+		PublishAt:          prefabricada.PublishAt,
+		UnpublishAt:        prefabricada.UnpublishAt,
 	}
 
 	c.JSON(http.StatusOK, gin.H{"prefabricada": prefabricadaResponse})
@@ -80,157 +104,51 @@ func ObtenerPrefabricadas(c *gin.Context) {
 		return
 	}
 
-	// Obtener filtros opcionales
-	categoriaID := c.Query("categoria_id")
-	tipoID := c.Query("tipo_id")
-	destacada := c.Query("destacada")
-	oferta := c.Query("oferta")
-
-	// Obtener parámetros de paginación
-	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
-	if err != nil || page < 1 {
-		page = 1 // Si no se pasa una página válida, por defecto será la página 1
+	// Obtener filtros opcionales (categoría, tipo, estilo, destacada, oferta, m2, precio, características, estado) y orden
+	filtros, err := services.ParsearFiltrosPrefabricadas(c.Request.URL.Query())
+	if err != nil {
+		HandleError(c, nil, http.StatusBadRequest, err.Error())
+		return
 	}
+	filtros.SoloPublicadas = soloPublicados(c)
 
-	limit, err := strconv.Atoi(c.DefaultQuery("limit", "12")) // Default: 12 resultados por página
-	if err != nil || limit < 1 {
-		limit = 12 // Si no se pasa un límite válido, por defecto serán 12 resultados
+	// Obtener parámetros de paginación (por defecto 12 resultados por página)
+	paginacion, ok := obtenerPaginacion(c, 12, true)
+	if !ok {
+		return
+	}
+	// Este listado enviaba page y limit en la raíz de la respuesta
+	paginacion.metadatosEnRaiz = true
+	if paginacion.modoCursor && filtros.Orden != "" {
+		HandleError(c, nil, http.StatusBadRequest, "La paginación por cursor solo admite el orden por defecto")
+		return
 	}
-
-	// Calcular el offset (paginación)
-	offset := (page - 1) * limit
 
 	// Iniciar consulta base
-	query := configs.DB.
-		Preload("Imagen_prefabricada", func(db *gorm.DB) *gorm.DB {
-			return db.Where("deleted_at IS NULL") // Condición para no cargar imágenes eliminadas lógicamente
-		}).
-		Preload("Caracteristica", func(db *gorm.DB) *gorm.DB {
-			return db.Where("deleted_at IS NULL") // Condición para no cargar características eliminadas lógicamente
-		}).
-		Preload("Precio", func(db *gorm.DB) *gorm.DB {
-			return db.Where("precios.deleted_at IS NULL") // Filtra precios no eliminados
-		}).Preload("Precio.Incluye", func(db *gorm.DB) *gorm.DB {
-		return db.Where("incluyes.deleted_at IS NULL") // Filtra los "incluye" no eliminados
-	}).
+	query := precargarPrefabricada(configs.DB).
 		Where("prefabricadas.empresa_id = ?", empresaID).
 		Where("prefabricadas.deleted_at IS NULL")
 
-	// Aplicar filtro por categoria_id si está presente
-	if categoriaID != "" {
-		query = query.Where("prefabricadas.categoria_id = ?", categoriaID)
-	}
-
-	// Aplicar filtro por tipo_id si está presente
-	if tipoID != "" {
-		query = query.Joins("JOIN tipos ON tipos.id = prefabricadas.tipo_id").
-			Where("tipos.id = ?", tipoID)
-	}
-
-	// Aplicar filtro por destacada
-	if destacada != "" {
-		query = query.Where("prefabricadas.destacada = ?", destacada)
-	}
-
-	// Aplicar filtro por Oferta
-	if oferta != "" {
-		query = query.Where("prefabricadas.oferta = ?", oferta)
+	// Aplicar filtros y orden
+	query = services.AplicarFiltrosPrefabricadas(query, filtros)
+	if !paginacion.modoCursor {
+		query = services.OrdenarPrefabricadas(query, filtros.Orden)
 	}
 
 	// Ejecutar consulta con paginación
-	if err := query.Limit(limit).Offset(offset).Find(&prefabricadas).Error; err != nil {
+	if err := paginacion.paginar(query, "", "prefabricadas.id", false, &prefabricadas); err != nil {
 		HandleError(c, err, http.StatusInternalServerError, "Prefabricadas no encontradas")
 		return
 	}
 
-	// Verificar si no hay prefabricadas y devolver un array vacío
-	if len(prefabricadas) == 0 {
-		prefabricadasResponse = []dto.PrefabricadaResponse{}
-	} else {
-		// Crear response de prefabricadas
-		for _, prefabricada := range prefabricadas {
-			var imagenes_prefabricadasResponse []dto.Imagen_prefabricadaResponse
-			var caracteristicasResponse []dto.CaracteristicaResponse
-			var preciosResponse []dto.PrecioResponse
-
-			// Convertir imágenes de prefabricada a DTO
-			for _, imagen := range prefabricada.Imagen_prefabricada {
-				imagenes_prefabricadasResponse = append(imagenes_prefabricadasResponse, dto.Imagen_prefabricadaResponse{
-					ID:             imagen.ID,
-					CreatedAt:      imagen.CreatedAt,
-					UpdatedAt:      imagen.UpdatedAt,
-					Image:          imagen.Image,
-					PrefabricadaID: imagen.PrefabricadaID,
-				})
-			}
-
-			// Convertir características a DTO
-			for _, caracteristica := range prefabricada.Caracteristica {
-				caracteristicasResponse = append(caracteristicasResponse, dto.CaracteristicaResponse{
-					ID:             caracteristica.ID,
-					CreatedAt:      caracteristica.CreatedAt,
-					UpdatedAt:      caracteristica.UpdatedAt,
-					Clave:          caracteristica.Clave,
-					Valor:          caracteristica.Valor,
-					PrefabricadaID: caracteristica.PrefabricadaID,
-				})
-			}
-
-			// Convertir precios e incluyes a DTO
-			for _, precio := range prefabricada.Precio {
-				var incluyesResponse []dto.IncluyeResponse
-
-				for _, incluye := range precio.Incluye {
-					incluyesResponse = append(incluyesResponse, dto.IncluyeResponse{
-						ID:            incluye.ID,
-						CreatedAt:     incluye.CreatedAt,
-						UpdatedAt:     incluye.UpdatedAt,
-						NombreIncluye: incluye.NombreIncluye,
-						PrecioID:      incluye.PrecioID,
-					})
-				}
-
-				preciosResponse = append(preciosResponse, dto.PrecioResponse{
-					ID:                precio.ID,
-					CreatedAt:         precio.CreatedAt,
-					UpdatedAt:         precio.UpdatedAt,
-					NombrePrecio:      precio.NombrePrecio,
-					DescripcionPrecio: precio.DescripcionPrecio,
-					ValorPrefabricada: precio.ValorPrefabricada,
-					PrefabricadaID:    precio.PrefabricadaID,
-					Incluyes:          incluyesResponse,
-				})
-			}
-
-			// Añadir prefabricada a la respuesta
-			prefabricadasResponse = append(prefabricadasResponse, dto.PrefabricadaResponse{
-				ID:                    prefabricada.ID,
-				CreatedAt:             prefabricada.CreatedAt,
-				UpdatedAt:             prefabricada.UpdatedAt,
-				NombrePrefabricada:    prefabricada.NombrePrefabricada,
-				M2:                    prefabricada.M2,
-				Garantia:              prefabricada.Garantia,
-				Eslogan:               prefabricada.Eslogan,
-				Descripcion:           prefabricada.Descripcion,
-				Destacada:             prefabricada.Destacada,
-				Oferta:                prefabricada.Oferta,
-				CategoriaID:           prefabricada.CategoriaID,
-				EmpresaID:             prefabricada.EmpresaID,
-				EstiloID:              prefabricada.EstiloID,
-				TipoID:                prefabricada.TipoID,
-				ImagenesPrefabricadas: imagenes_prefabricadasResponse,
-				Caracteristicas:       caracteristicasResponse,
-				Precios:               preciosResponse,
-			})
-		}
+	// Crear response de prefabricadas (array vacío en caso de no haber resultados)
+	prefabricadasResponse = []dto.PrefabricadaResponse{}
+	for _, prefabricada := range prefabricadas {
+		prefabricadasResponse = append(prefabricadasResponse, prefabricadaAResponse(prefabricada))
 	}
 
 	// Mostrar/enviar response de Prefabricada con información de paginación
-	c.JSON(http.StatusOK, gin.H{
-		"prefabricadas": prefabricadasResponse, // Aquí ya será un array vacío en caso de no haber resultados
-		"page":          page,
-		"limit":         limit,
-	})
+	paginacion.responder(c, "prefabricadas", prefabricadasResponse)
 }
 
 // Función para obtener una Prefabricada de acuerdo al ID enviado
@@ -245,14 +163,18 @@ func ObtenerPrefabricada(c *gin.Context) {
 		return
 	}
 
-	idParamPrefabricada := c.Param("prefabricadaID")
-	prefabricadaID, err := strconv.ParseUint(idParamPrefabricada, 10, 64)
-	if err != nil {
-		HandleError(c, nil, http.StatusBadRequest, "ID Prefabricada inválido")
+	// La Prefabricada se puede obtener por su ID o por su slug
+	prefabricadaID, ok := resolverIDOSlug(c, "prefabricadaID", services.EntidadSlugPrefabricada, uint(empresaID), "Prefabricada no encontrada")
+	if !ok {
 		return
 	}
 
-	if err := configs.DB.
+	query := configs.DB
+	if soloPublicados(c) {
+		query = services.FiltrarPublicados(query, "prefabricadas")
+	}
+
+	if err := query.
 		Preload("Imagen_prefabricada", func(db *gorm.DB) *gorm.DB {
 			return db.Where("deleted_at IS NULL") // Condición para no cargar imágenes eliminadas lógicamente
 		}).
@@ -329,6 +251,7 @@ func ObtenerPrefabricada(c *gin.Context) {
 		ID:                    prefabricada.ID,
 		CreatedAt:             prefabricada.CreatedAt,
 		UpdatedAt:             prefabricada.UpdatedAt,
+		Slug:                  valorSlug(prefabricada.Slug),
 		NombrePrefabricada:    prefabricada.NombrePrefabricada,
 		M2:                    prefabricada.M2,
 		Garantia:              prefabricada.Garantia,
@@ -340,6 +263,9 @@ func ObtenerPrefabricada(c *gin.Context) {
 		EmpresaID:             prefabricada.EmpresaID,
 		EstiloID:              prefabricada.EstiloID,
 		TipoID:                prefabricada.TipoID,
+		Estado:                prefabricada.Estado,
+		PublishAt:             prefabricada.PublishAt,
+		UnpublishAt:           prefabricada.UnpublishAt,
 		ImagenesPrefabricadas: imagenes_prefabricadasResponse,
 		Caracteristicas:       caracteristicasResponse,
 		Precios:               preciosResponse,
@@ -385,7 +311,15 @@ func ActualizarPrefabricada(c *gin.Context) {
 		return
 	}
 
+	// Al cambiar el nombre se genera un nuevo slug, salvo que se indique uno
+	slugAnterior := prefabricada.Slug
+	slug, ok := slugParaGuardar(c, services.EntidadSlugPrefabricada, prefabricada.EmpresaID, prefabricada.ID, slugAnterior, request.Slug, request.NombrePrefabricada, request.NombrePrefabricada != prefabricada.NombrePrefabricada)
+	if !ok {
+		return
+	}
+
 	// Actualizar datos de la Prefabricada
+	prefabricada.Slug = &slug
 	prefabricada.NombrePrefabricada = request.NombrePrefabricada
 	prefabricada.M2 = request.M2
 	prefabricada.Garantia = request.Garantia
@@ -397,9 +331,15 @@ func ActualizarPrefabricada(c *gin.Context) {
 	prefabricada.EstiloID = request.EstiloID
 	prefabricada.TipoID = request.TipoID
 
-	// Guardar los cambios en la base de datos
-	if err := configs.DB.Save(&prefabricada).Error; err != nil {
-		HandleError(c, err, http.StatusInternalServerError, "No pudo actualizar datos de Prefabricada")
+	// Guardar los cambios en la base de datos, conservando el slug anterior para redirigir las URLs antiguas
+	err = configs.DB.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&prefabricada).Error; err != nil {
+			return err
+		}
+		return services.RegistrarCambioSlug(tx, services.EntidadSlugPrefabricada, prefabricada.EmpresaID, prefabricada.ID, slugAnterior, slug)
+	})
+	if err != nil {
+		handleErrorSlug(c, services.ErrorSlugDuplicado(configs.DB, err), "No pudo actualizar datos de Prefabricada")
 		return
 	}
 
@@ -407,6 +347,7 @@ func ActualizarPrefabricada(c *gin.Context) {
 		ID:                 prefabricada.ID,
 		CreatedAt:          prefabricada.CreatedAt,
 		UpdatedAt:          prefabricada.UpdatedAt,
+		Slug:               valorSlug(prefabricada.Slug),
 		NombrePrefabricada: prefabricada.NombrePrefabricada,
 		M2:                 prefabricada.M2,
 		Garantia:           prefabricada.Garantia,
@@ -417,6 +358,9 @@ func ActualizarPrefabricada(c *gin.Context) {
 		CategoriaID:        prefabricada.CategoriaID,
 		EstiloID:           prefabricada.EstiloID,
 		TipoID:             prefabricada.TipoID,
+		Estado:             prefabricada.Estado,
+		PublishAt:          prefabricada.PublishAt,
+		UnpublishAt:        prefabricada.UnpublishAt,
 	}
 
 	// Mostrar/enviar mensaje de éxto
@@ -460,16 +404,205 @@ func EliminarPrefabricada(c *gin.Context) {
 		return
 	}
 
-	// Poner facha y hora de la eliminación lógica
-	now := time.Now()
-	prefabricada.DeletedAt = &now
-
-	// Guardar facha y hora de la eliminación lógica de la Prefabricada en la base de datos
-	if err := configs.DB.Save(&prefabricada).Error; err != nil {
-		HandleError(c, err, http.StatusInternalServerError, "Error, no se pudo eliminar la Prefabricada")
+	// Enviar a la papelera junto con los registros que dependen de él
+	if !enviarAPapelera(c, "prefabricadas", prefabricada.ID, "Error, no se pudo eliminar la Prefabricada") {
 		return
 	}
 
 	// Mostrar/enviar mensaje de eliminación lógica exitosa
 	c.JSON(http.StatusOK, gin.H{"message": "Prefabricada eliminada exitosamente"})
 }
+
+// Función para crear una copia de una Prefabricada con sus imágenes, características, precios e incluyes
+func ClonarPrefabricada(c *gin.Context) {
+	var request dto.ClonarPrefabricadaRequest
+	var original models.Prefabricada
+
+	idParamEmpresa := c.Param("empresaID")
+	empresaID, err := strconv.ParseUint(idParamEmpresa, 10, 64)
+	if err != nil {
+		HandleError(c, nil, http.StatusBadRequest, "ID Empresa inválido")
+		return
+	}
+
+	idParamPrefabricada := c.Param("prefabricadaID")
+	prefabricadaID, err := strconv.ParseUint(idParamPrefabricada, 10, 64)
+	if err != nil {
+		HandleError(c, nil, http.StatusBadRequest, "ID Prefabricada inválido")
+		return
+	}
+
+	// El body es opcional: sin él se copia todo con el nombre de la original
+	if err := c.ShouldBindJSON(&request); err != nil && !errors.Is(err, io.EOF) {
+		HandleError(c, err, http.StatusBadRequest, "Error de datos "+err.Error())
+		return
+	}
+
+	// Buscar la Prefabricada original con sus relaciones no eliminadas
+	if err := precargarPrefabricada(configs.DB).Where("empresa_id = ?", empresaID).Where("deleted_at IS NULL").First(&original, prefabricadaID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			HandleError(c, nil, http.StatusNotFound, "Prefabricada no encontrada")
+			return
+		}
+		HandleError(c, err, http.StatusInternalServerError, "Error al obtener datos de la Prefabricada")
+		return
+	}
+
+	datos := services.ClonPrefabricada{
+		NombrePrefabricada: strings.TrimSpace(request.NombrePrefabricada),
+		M2:                 original.M2,
+	}
+	if datos.NombrePrefabricada == "" {
+		datos.NombrePrefabricada = original.NombrePrefabricada + " (copia)"
+	}
+	if request.M2 != nil {
+		datos.M2 = *request.M2
+	}
+
+	// Slug indicado o generado desde el nombre de la copia
+	slug, ok := slugParaGuardar(c, services.EntidadSlugPrefabricada, original.EmpresaID, 0, nil, request.Slug, datos.NombrePrefabricada, true)
+	if !ok {
+		return
+	}
+	datos.Slug = slug
+
+	// Las copias en S3 se hacen antes de la transacción; si algo falla después se borran para no dejar huérfanas
+	var copias []string
+	eliminarCopias := func() {
+		for _, copia := range copias {
+			if err := services.EliminarDeS3(copia); err != nil {
+				log.Printf("Error al eliminar la copia %s de S3: %v", copia, err)
+			}
+		}
+	}
+	if request.CopiarImagenes {
+		datos.Imagenes = map[uint]string{}
+		for _, imagen := range original.Imagen_prefabricada {
+			url, err := services.CopiarEnS3(imagen.Image, "imagenes_prefabricadas")
+			if err != nil {
+				eliminarCopias()
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to copy image in S3", "details": err.Error()})
+				return
+			}
+			// Las URLs que no son del bucket se reutilizan tal cual y no deben borrarse
+			if url != imagen.Image {
+				copias = append(copias, url)
+			}
+			datos.Imagenes[imagen.ID] = url
+		}
+	}
+
+	// Crear la copia completa o nada
+	var clon models.Prefabricada
+	err = configs.DB.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		clon, err = services.ClonarPrefabricada(tx, original, datos)
+		return err
+	})
+	if err != nil {
+		eliminarCopias()
+		handleErrorSlug(c, services.ErrorSlugDuplicado(configs.DB, err), "Error, no se pudo clonar la Prefabricada")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":      "Prefabricada clonada exitosamente",
+		"prefabricada": prefabricadaAResponse(clon),
+	})
+}
+
+// Precargar imágenes, características, precios e incluyes no eliminados de las Prefabricadas
+func precargarPrefabricada(db *gorm.DB) *gorm.DB {
+	return db.
+		Preload("Imagen_prefabricada", func(db *gorm.DB) *gorm.DB {
+			return db.Where("deleted_at IS NULL") // Condición para no cargar imágenes eliminadas lógicamente
+		}).
+		Preload("Caracteristica", func(db *gorm.DB) *gorm.DB {
+			return db.Where("deleted_at IS NULL") // Condición para no cargar características eliminadas lógicamente
+		}).
+		Preload("Precio", func(db *gorm.DB) *gorm.DB {
+			return db.Where("precios.deleted_at IS NULL") // Filtra precios no eliminados
+		}).
+		Preload("Precio.Incluye", func(db *gorm.DB) *gorm.DB {
+			return db.Where("incluyes.deleted_at IS NULL") // Filtra los "incluye" no eliminados
+		})
+}
+
+// Convertir una Prefabricada (con sus relaciones precargadas) a DTO
+func prefabricadaAResponse(prefabricada models.Prefabricada) dto.PrefabricadaResponse {
+	var imagenes_prefabricadasResponse []dto.Imagen_prefabricadaResponse
+	var caracteristicasResponse []dto.CaracteristicaResponse
+	var preciosResponse []dto.PrecioResponse
+
+	// Convertir imágenes de prefabricada a DTO
+	for _, imagen := range prefabricada.Imagen_prefabricada {
+		imagenes_prefabricadasResponse = append(imagenes_prefabricadasResponse, dto.Imagen_prefabricadaResponse{
+			ID:             imagen.ID,
+			CreatedAt:      imagen.CreatedAt,
+			UpdatedAt:      imagen.UpdatedAt,
+			Image:          imagen.Image,
+			PrefabricadaID: imagen.PrefabricadaID,
+		})
+	}
+
+	// Convertir características a DTO
+	for _, caracteristica := range prefabricada.Caracteristica {
+		caracteristicasResponse = append(caracteristicasResponse, dto.CaracteristicaResponse{
+			ID:             caracteristica.ID,
+			CreatedAt:      caracteristica.CreatedAt,
+			UpdatedAt:      caracteristica.UpdatedAt,
+			Clave:          caracteristica.Clave,
+			Valor:          caracteristica.Valor,
+			PrefabricadaID: caracteristica.PrefabricadaID,
+		})
+	}
+
+	// Convertir precios e incluyes a DTO
+	for _, precio := range prefabricada.Precio {
+		var incluyesResponse []dto.IncluyeResponse
+
+		for _, incluye := range precio.Incluye {
+			incluyesResponse = append(incluyesResponse, dto.IncluyeResponse{
+				ID:            incluye.ID,
+				CreatedAt:     incluye.CreatedAt,
+				UpdatedAt:     incluye.UpdatedAt,
+				NombreIncluye: incluye.NombreIncluye,
+				PrecioID:      incluye.PrecioID,
+			})
+		}
+
+		preciosResponse = append(preciosResponse, dto.PrecioResponse{
+			ID:                precio.ID,
+			CreatedAt:         precio.CreatedAt,
+			UpdatedAt:         precio.UpdatedAt,
+			NombrePrecio:      precio.NombrePrecio,
+			DescripcionPrecio: precio.DescripcionPrecio,
+			ValorPrefabricada: precio.ValorPrefabricada,
+			PrefabricadaID:    precio.PrefabricadaID,
+			Incluyes:          incluyesResponse,
+		})
+	}
+
+	return dto.PrefabricadaResponse{
+		ID:                    prefabricada.ID,
+		CreatedAt:             prefabricada.CreatedAt,
+		UpdatedAt:             prefabricada.UpdatedAt,
+		Slug:                  valorSlug(prefabricada.Slug),
+		NombrePrefabricada:    prefabricada.NombrePrefabricada,
+		M2:                    prefabricada.M2,
+		Garantia:              prefabricada.Garantia,
+		Eslogan:               prefabricada.Eslogan,
+		Descripcion:           prefabricada.Descripcion,
+		Destacada:             prefabricada.Destacada,
+		Oferta:                prefabricada.Oferta,
+		CategoriaID:           prefabricada.CategoriaID,
+		EmpresaID:             prefabricada.EmpresaID,
+		EstiloID:              prefabricada.EstiloID,
+		TipoID:                prefabricada.TipoID,
+		Estado:                prefabricada.Estado,
+		PublishAt:             prefabricada.PublishAt,
+		UnpublishAt:           prefabricada.UnpublishAt,
+		ImagenesPrefabricadas: imagenes_prefabricadasResponse,
+		Caracteristicas:       caracteristicasResponse,
+		Precios:               preciosResponse,
+	}
+}
@@ -0,0 +1,205 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"v1_prefabricadas/configs"
+	"v1_prefabricadas/dto"
+	"v1_prefabricadas/models"
+	"v1_prefabricadas/services"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const prefijoRutaAdministracion = "/administracion"
+
+// Las rutas públicas solo muestran lo publicado; las de administración muestran todos los estados
+func soloPublicados(c *gin.Context) bool {
+	return !strings.HasPrefix(c.FullPath(), prefijoRutaAdministracion)
+}
+
+// Validar el estado de publicación solicitado. Si es inválido responde 400 y devuelve false.
+func publicacionSolicitada(c *gin.Context, estado string, publishAt, unpublishAt *time.Time) (services.Publicacion, bool) {
+	publicacion, err := services.ResolverPublicacion(estado, publishAt, unpublishAt, time.Now())
+	if err != nil {
+		if errors.Is(err, services.ErrPublicacionInvalida) {
+			HandleError(c, nil, http.StatusBadRequest, err.Error())
+			return publicacion, false
+		}
+		HandleError(c, err, http.StatusInternalServerError, "Error al validar la publicación")
+		return publicacion, false
+	}
+	return publicacion, true
+}
+
+// Función para cambiar el estado de publicación de una Prefabricada (publicar, programar, archivar o volver a borrador)
+func ActualizarPublicacionPrefabricada(c *gin.Context) {
+	var request dto.ActualizarPublicacionRequest
+	var prefabricada models.Prefabricada
+
+	empresaID, err := strconv.ParseUint(c.Param("empresaID"), 10, 64)
+	if err != nil {
+		HandleError(c, nil, http.StatusBadRequest, "ID Empresa inválido")
+		return
+	}
+
+	prefabricadaID, err := strconv.ParseUint(c.Param("prefabricadaID"), 10, 64)
+	if err != nil {
+		HandleError(c, nil, http.StatusBadRequest, "ID Prefabricada inválido")
+		return
+	}
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		HandleError(c, err, http.StatusBadRequest, "Error de datos "+err.Error())
+		return
+	}
+
+	publicacion, ok := publicacionSolicitada(c, request.Estado, request.PublishAt, request.UnpublishAt)
+	if !ok {
+		return
+	}
+
+	if err := configs.DB.Where("empresa_id = ?", empresaID).Where("deleted_at IS NULL").First(&prefabricada, prefabricadaID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			HandleError(c, nil, http.StatusNotFound, "Prefabricada no encontrada")
+			return
+		}
+		HandleError(c, err, http.StatusInternalServerError, "Error al obtener datos de la Prefabricada")
+		return
+	}
+
+	prefabricada.Estado = publicacion.Estado
+	prefabricada.PublishAt = publicacion.PublishAt
+	prefabricada.UnpublishAt = publicacion.UnpublishAt
+
+	if err := configs.DB.WithContext(c).Save(&prefabricada).Error; err != nil {
+		HandleError(c, err, http.StatusInternalServerError, "No se pudo actualizar la publicación de la Prefabricada")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Publicación actualizada exitosamente",
+		"publicacion": dto.PublicacionResponse{
+			ID:          prefabricada.ID,
+			Estado:      prefabricada.Estado,
+			PublishAt:   prefabricada.PublishAt,
+			UnpublishAt: prefabricada.UnpublishAt,
+		},
+	})
+}
+
+// Función para cambiar el estado de publicación de una Noticia
+func ActualizarPublicacionNoticia(c *gin.Context) {
+	var request dto.ActualizarPublicacionRequest
+	var noticia models.Noticia
+
+	empresaID, err := strconv.ParseUint(c.Param("empresaID"), 10, 64)
+	if err != nil {
+		HandleError(c, nil, http.StatusBadRequest, "ID Empresa inválido")
+		return
+	}
+
+	noticiaID, err := strconv.ParseUint(c.Param("noticiaID"), 10, 64)
+	if err != nil {
+		HandleError(c, nil, http.StatusBadRequest, "ID Noticia inválido")
+		return
+	}
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		HandleError(c, err, http.StatusBadRequest, "Error de datos "+err.Error())
+		return
+	}
+
+	publicacion, ok := publicacionSolicitada(c, request.Estado, request.PublishAt, request.UnpublishAt)
+	if !ok {
+		return
+	}
+
+	if err := configs.DB.Where("empresa_id = ? AND id = ?", empresaID, noticiaID).Where("deleted_at IS NULL").First(&noticia).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			HandleError(c, nil, http.StatusNotFound, "Noticia no encontrada")
+			return
+		}
+		HandleError(c, err, http.StatusInternalServerError, "Error al tratar de buscar los datos de la Noticia")
+		return
+	}
+
+	noticia.Estado = publicacion.Estado
+	noticia.PublishAt = publicacion.PublishAt
+	noticia.UnpublishAt = publicacion.UnpublishAt
+
+	if err := configs.DB.WithContext(c).Save(&noticia).Error; err != nil {
+		HandleError(c, err, http.StatusInternalServerError, "No se pudo actualizar la publicación de la Noticia")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Publicación actualizada exitosamente",
+		"publicacion": dto.PublicacionResponse{
+			ID:          noticia.ID,
+			Estado:      noticia.Estado,
+			PublishAt:   noticia.PublishAt,
+			UnpublishAt: noticia.UnpublishAt,
+		},
+	})
+}
+
+// Función para cambiar el estado de publicación de una Portada
+func ActualizarPublicacionPortada(c *gin.Context) {
+	var request dto.ActualizarPublicacionRequest
+	var portada models.Portada
+
+	empresaID, err := strconv.ParseUint(c.Param("empresaID"), 10, 64)
+	if err != nil {
+		HandleError(c, nil, http.StatusBadRequest, "ID Empresa inválido")
+		return
+	}
+
+	portadaID, err := strconv.ParseUint(c.Param("portadaID"), 10, 64)
+	if err != nil {
+		HandleError(c, nil, http.StatusBadRequest, "ID Portada inválido")
+		return
+	}
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		HandleError(c, err, http.StatusBadRequest, "Error de datos "+err.Error())
+		return
+	}
+
+	publicacion, ok := publicacionSolicitada(c, request.Estado, request.PublishAt, request.UnpublishAt)
+	if !ok {
+		return
+	}
+
+	if err := configs.DB.Where("empresa_id = ?", empresaID).Where("deleted_at IS NULL").First(&portada, portadaID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			HandleError(c, nil, http.StatusNotFound, "Portada no encontrada")
+			return
+		}
+		HandleError(c, err, http.StatusInternalServerError, "Error al obtener datos de la Portada")
+		return
+	}
+
+	portada.Estado = publicacion.Estado
+	portada.PublishAt = publicacion.PublishAt
+	portada.UnpublishAt = publicacion.UnpublishAt
+
+	if err := configs.DB.WithContext(c).Save(&portada).Error; err != nil {
+		HandleError(c, err, http.StatusInternalServerError, "No se pudo actualizar la publicación de la Portada")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Publicación actualizada exitosamente",
+		"publicacion": dto.PublicacionResponse{
+			ID:          portada.ID,
+			Estado:      portada.Estado,
+			PublishAt:   portada.PublishAt,
+			UnpublishAt: portada.UnpublishAt,
+		},
+	})
+}
@@ -4,7 +4,6 @@ import (
 	"errors"
 	"net/http"
 	"strconv"
-	"time"
 	"v1_prefabricadas/configs"
 	"v1_prefabricadas/dto"
 	"v1_prefabricadas/models"
@@ -36,7 +35,7 @@ func CrearRed(c *gin.Context) {
 	}
 
 	// Agregamos los datos de la RedSocial a la Base de Datos
-	if err := configs.DB.Create(&red).Error; err != nil {
+	if err := configs.DB.WithContext(c).Create(&red).Error; err != nil {
 		HandleError(c, err, http.StatusInternalServerError, "No se pudo guardar los datos de la RedSocial")
 		return
 	}
@@ -59,7 +58,7 @@ func CrearRed(c *gin.Context) {
 // Función paea Obtener todas las redes sociales de la empresa
 func ObtenerRedes(c *gin.Context) {
 	var redes []models.Red
-	var redesResponse []dto.RedResponse
+	redesResponse := []dto.RedResponse{}
 
 	idParamEmpresa := c.Param("empresaID")
 	empresaID, err := strconv.ParseUint(idParamEmpresa, 10, 64)
@@ -68,8 +67,14 @@ func ObtenerRedes(c *gin.Context) {
 		return
 	}
 
+	// Parámetros de paginación
+	paginacion, ok := obtenerPaginacion(c, limiteMaximoPaginacion, false)
+	if !ok {
+		return
+	}
+
 	// Buscamos todas las redes sociales de la Empresa
-	if err := configs.DB.Where("empresa_id = ?", empresaID).Where("deleted_at IS NULL").Find(&redes).Error; err != nil {
+	if err := paginacion.paginar(configs.DB.Where("empresa_id = ?", empresaID).Where("deleted_at IS NULL"), "id ASC", "id", false, &redes); err != nil {
 		HandleError(c, err, http.StatusInternalServerError, "Redes sociales no encontradas")
 		return
 	}
@@ -85,7 +90,7 @@ func ObtenerRedes(c *gin.Context) {
 	}
 
 	// Reponder/enviar redes sociales
-	c.JSON(http.StatusOK, gin.H{"redes_sociales": redesResponse})
+	paginacion.responder(c, "redes_sociales", redesResponse)
 }
 
 // Función para Obtener datos de una red social de acuerdo a su ID
@@ -170,7 +175,7 @@ func ActualizarRed(c *gin.Context) {
 	red.Link = request.Link
 
 	// Guardar los datos en la base de Datos
-	if err := configs.DB.Save(&red).Error; err != nil {
+	if err := configs.DB.WithContext(c).Save(&red).Error; err != nil {
 		HandleError(c, err, http.StatusInternalServerError, "Error, no se pudo actualizar los datos de la Red Social")
 		return
 	}
@@ -223,13 +228,8 @@ func EliminarRed(c *gin.Context) {
 		return
 	}
 
-	// Establecer fecha y hora para la eliminación lógica
-	now := time.Now()
-	red.DeletedAt = &now
-
-	// Guardar fecha y hora de la eliminación lógica en la base de datos
-	if err := configs.DB.Save(&red).Error; err != nil {
-		HandleError(c, err, http.StatusInternalServerError, "No se pudo eliminar la Red Social")
+	// Eliminar lógicamente y registrar en la papelera
+	if !enviarAPapelera(c, "redes", red.ID, "No se pudo eliminar la Red Social") {
 		return
 	}
 
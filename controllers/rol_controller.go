@@ -4,10 +4,10 @@ import (
 	"errors"
 	"net/http"
 	"strconv"
-	"time"
 	"v1_prefabricadas/configs"
 	"v1_prefabricadas/dto"
 	"v1_prefabricadas/models"
+	"v1_prefabricadas/services"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
@@ -24,6 +24,17 @@ func CrearRol(c *gin.Context) {
 		return
 	}
 
+	// El nombre super_administrador otorga todos los permisos
+	if request.NombreRol == services.RolSuperAdministrador && !esSuperAdministrador(c) {
+		HandleError(c, nil, http.StatusForbidden, "Acceso denegado: solo un super_administrador puede gestionar ese Rol")
+		return
+	}
+
+	// Los nombres de rol no se repiten, ya que el token de acceso identifica los roles por su nombre
+	if !nombreRolDisponible(c, request.NombreRol, 0) {
+		return
+	}
+
 	// Creamos el Rol
 	rol := models.Rol{
 		NombreRol:      request.NombreRol,
@@ -31,7 +42,7 @@ func CrearRol(c *gin.Context) {
 	}
 
 	// Agregamos el Rol a la Base de Datos
-	if err := configs.DB.Create(&rol).Error; err != nil {
+	if err := configs.DB.WithContext(c).Create(&rol).Error; err != nil {
 		HandleError(c, err, http.StatusInternalServerError, "No se pudo crear el Rol")
 		return
 	}
@@ -59,16 +70,17 @@ func CrearRol(c *gin.Context) {
 // Función para obtener todos los roles
 func ObtenerRoles(c *gin.Context) {
 	var roles []models.Rol
-	var rolesResponse []dto.RolResponse
+	rolesResponse := []dto.RolResponse{}
 
-	// Buscar todos los roles en la base de datos
-	if err := configs.DB.Where("deleted_at IS NULL").Find(&roles).Error; err != nil {
-		HandleError(c, err, http.StatusInternalServerError, "Error al tratar de obtener los Roles")
+	// Parámetros de paginación
+	paginacion, ok := obtenerPaginacion(c, limiteMaximoPaginacion, false)
+	if !ok {
 		return
 	}
 
-	if len(roles) == 0 {
-		HandleError(c, nil, http.StatusNotFound, "Roles no encontrados")
+	// Buscar todos los roles en la base de datos
+	if err := paginacion.paginar(configs.DB.Where("deleted_at IS NULL"), "id ASC", "id", false, &roles); err != nil {
+		HandleError(c, err, http.StatusInternalServerError, "Error al tratar de obtener los Roles")
 		return
 	}
 
@@ -83,9 +95,7 @@ func ObtenerRoles(c *gin.Context) {
 	}
 
 	// Mostrar/enviar Roles
-	c.JSON(http.StatusOK, gin.H{
-		"roles": rolesResponse,
-	})
+	paginacion.responder(c, "roles", rolesResponse)
 }
 
 // Función para obtener el Rol de acuerdo a su ID
@@ -153,12 +163,25 @@ func ActualizarRol(c *gin.Context) {
 		return
 	}
 
+	// Solo se modifican roles al alcance de quien los modifica, y sin renombrarlos a super_administrador
+	if !puedeOtorgarRoles(c, rol.ID) {
+		return
+	}
+	if request.NombreRol == services.RolSuperAdministrador && !esSuperAdministrador(c) {
+		HandleError(c, nil, http.StatusForbidden, "Acceso denegado: solo un super_administrador puede gestionar ese Rol")
+		return
+	}
+
+	if !nombreRolDisponible(c, request.NombreRol, rol.ID) {
+		return
+	}
+
 	// Actualizar datos del Rol
 	rol.NombreRol = request.NombreRol
 	rol.DescripcionRol = request.DescripcionRol
 
 	// Guardar los cambios en la base de datos
-	if err := configs.DB.Save(&rol).Error; err != nil {
+	if err := configs.DB.WithContext(c).Save(&rol).Error; err != nil {
 		HandleError(c, err, http.StatusInternalServerError, "Error, no se puedo actalizar los datos del Rol")
 		return
 	}
@@ -200,19 +223,18 @@ func EliminarRol(c *gin.Context) {
 		return
 	}
 
+	if !puedeOtorgarRoles(c, rol.ID) {
+		return
+	}
+
 	// Verificar si el Rol ya se encuentra eliminado lógicamente
 	/* if rol.DeletedAt != nil && !rol.DeletedAt.IsZero() {
 		HandleError(c, nil, http.StatusBadRequest, "Rol seleccionado ya se encuentra eliminado")
 		return
 	} */
 
-	// Poner Fecha y hora de la eliminación lógica
-	now := time.Now()
-	rol.DeletedAt = &now
-
-	// Guarda Fecha y hora de la eliminación lógica del Rol
-	if err := configs.DB.Save(&rol).Error; err != nil {
-		HandleError(c, err, http.StatusInternalServerError, "Error, no se pudo eliminar el Rol, intente nuevamente más tarde")
+	// Eliminar lógicamente, siempre que no esté asignado a ningún usuario
+	if !eliminarCatalogoGlobal(c, "roles", rol.ID, "Error, no se pudo eliminar el Rol, intente nuevamente más tarde") {
 		return
 	}
 
@@ -221,3 +243,19 @@ func EliminarRol(c *gin.Context) {
 		"message": "Rol eliminado con éxito",
 	})
 }
+
+// Verificar que ningún otro Rol vigente tenga el nombre indicado. Si no, responde 409 y devuelve false.
+func nombreRolDisponible(c *gin.Context, nombre string, rolID uint) bool {
+	var repetidos int64
+	if err := configs.DB.Model(&models.Rol{}).
+		Where("nombre_rol = ? AND id <> ? AND deleted_at IS NULL", nombre, rolID).
+		Count(&repetidos).Error; err != nil {
+		HandleError(c, err, http.StatusInternalServerError, "Error al verificar el nombre del Rol")
+		return false
+	}
+	if repetidos > 0 {
+		HandleError(c, nil, http.StatusConflict, "Ya existe un Rol con ese nombre")
+		return false
+	}
+	return true
+}
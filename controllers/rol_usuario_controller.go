@@ -4,7 +4,6 @@ import (
 	"errors"
 	"net/http"
 	"strconv"
-	"time"
 	"v1_prefabricadas/configs"
 	"v1_prefabricadas/dto"
 	"v1_prefabricadas/models"
@@ -30,6 +29,11 @@ func CrearRol_usuario(c *gin.Context) {
 		return
 	}
 
+	// Solo se asignan roles cuyos permisos tenga quien los asigna, y a usuarios que no estén por encima
+	if !puedeOtorgarRoles(c, uint(rolID)) || !puedeGestionarUsuario(c, request.UsuarioID) {
+		return
+	}
+
 	// Creamos Rol_usuario
 	rol_usuario := models.Rol_usuario{
 		UsuarioID: request.UsuarioID,
@@ -37,14 +41,11 @@ func CrearRol_usuario(c *gin.Context) {
 	}
 
 	// Guardamos en la base de datos en nuevo Rol de Usuario
-	if err := configs.DB.Create(&rol_usuario).Error; err != nil {
+	if err := configs.DB.WithContext(c).Create(&rol_usuario).Error; err != nil {
 		HandleError(c, err, http.StatusInternalServerError, "No se pudo guardar el Rol de Usuario")
 		return
 	}
 
-	// Mostramos un mensaje de éxito
-	c.JSON(http.StatusOK, gin.H{"message": "Rol_usuario creado con éxito"})
-
 	// Response
 	response := dto.Rol_usuarioResponse{
 		ID:        rol_usuario.ID,
@@ -54,14 +55,17 @@ func CrearRol_usuario(c *gin.Context) {
 		RolID:     rol_usuario.RolID,
 	}
 
-	// Mostramos el Response
-	c.JSON(http.StatusCreated, gin.H{"rol_usuario": response})
+	// Mostramos un mensaje de éxito y el Response
+	c.JSON(http.StatusCreated, gin.H{
+		"message":     "Rol_usuario creado con éxito",
+		"rol_usuario": response,
+	})
 }
 
 // Función para obtener todos los usuarios de un rol
 func ObtenerRoles_usuarios(c *gin.Context) {
 	var roles_usuarios []models.Rol_usuario
-	var roles_usuariosResponse []dto.Rol_usuarioResponse
+	roles_usuariosResponse := []dto.Rol_usuarioResponse{}
 
 	idParamRol := c.Param("rolID")
 	rolID, err := strconv.ParseUint(idParamRol, 10, 64)
@@ -70,14 +74,15 @@ func ObtenerRoles_usuarios(c *gin.Context) {
 		return
 	}
 
-	// Buscar todos los usuarios de todos los roles
-	if err := configs.DB.Where("deleted_at IS NULL").Where("rol_id = ?", rolID).Find(&roles_usuarios).Error; err != nil {
-		HandleError(c, err, http.StatusInternalServerError, "Error, no se pudo obtener los Datos solicitados(Roles de usuarios)")
+	// Parámetros de paginación
+	paginacion, ok := obtenerPaginacion(c, limiteMaximoPaginacion, false)
+	if !ok {
 		return
 	}
 
-	if len(roles_usuarios) == 0 {
-		HandleError(c, nil, http.StatusNotFound, "Datos no encontrados")
+	// Buscar todos los usuarios de todos los roles
+	if err := paginacion.paginar(rolesUsuariosDeEmpresa(c, configs.DB.Where("deleted_at IS NULL").Where("rol_id = ?", rolID)), "id ASC", "id", false, &roles_usuarios); err != nil {
+		HandleError(c, err, http.StatusInternalServerError, "Error, no se pudo obtener los Datos solicitados(Roles de usuarios)")
 		return
 	}
 
@@ -92,9 +97,7 @@ func ObtenerRoles_usuarios(c *gin.Context) {
 	}
 
 	// Mostrar/enviar roles_usuarios
-	c.JSON(http.StatusOK, gin.H{
-		"roles_usuarios": roles_usuariosResponse,
-	})
+	paginacion.responder(c, "roles_usuarios", roles_usuariosResponse)
 
 }
 
@@ -118,7 +121,7 @@ func ObtenerRol_usuario(c *gin.Context) {
 	}
 
 	// Buscar el Usuario y el Rol en la base de Datos
-	if err := configs.DB.Where("deleted_at IS NULL").Where("rol_id = ? AND id = ?", rolID, rol_usuarioID).First(&rol_usuario).Error; err != nil {
+	if err := rolesUsuariosDeEmpresa(c, configs.DB.Where("deleted_at IS NULL").Where("rol_id = ? AND id = ?", rolID, rol_usuarioID)).First(&rol_usuario).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			HandleError(c, nil, http.StatusNotFound, "Rol_usuario no encontrado")
 			return
@@ -178,12 +181,17 @@ func ActualizarRol_usuario(c *gin.Context) {
 		return
 	}
 
+	// Tanto el usuario actual como el nuevo deben estar al alcance de quien modifica la asignación
+	if !puedeOtorgarRoles(c, uint(rolID)) || !puedeGestionarUsuario(c, rol_usuario.UsuarioID) || !puedeGestionarUsuario(c, request.UsuarioID) {
+		return
+	}
+
 	// actualizar los datos
 	rol_usuario.UsuarioID = request.UsuarioID
 	rol_usuario.RolID = uint(rolID)
 
 	// Guardar los cambios en la base de datos
-	if err := configs.DB.Save(&rol_usuario).Error; err != nil {
+	if err := configs.DB.WithContext(c).Save(&rol_usuario).Error; err != nil {
 		HandleError(c, err, http.StatusInternalServerError, "Error al actualizar datos, intente nuevamente")
 		return
 	}
@@ -232,15 +240,20 @@ func EliminarRol_usuario(c *gin.Context) {
 		return
 	}
 
-	// Poner fecha y hora de la eliminación lógica
-	now := time.Now()
-	rol_usuario.DeletedAt = &now
+	// Solo se quitan roles que quien los quita podría asignar
+	if !puedeOtorgarRoles(c, uint(rolID)) || !puedeGestionarUsuario(c, rol_usuario.UsuarioID) {
+		return
+	}
 
-	// Guardar en la base de datos la fecha y hora de la eliminación lógica
-	if err := configs.DB.Save(&rol_usuario).Error; err != nil {
+	// Eliminar lógicamente y registrar en la papelera de la empresa del usuario
+	var empresaID uint
+	if err := configs.DB.Model(&models.Usuario{}).Where("id = ?", rol_usuario.UsuarioID).Select("empresa_id").Scan(&empresaID).Error; err != nil {
 		HandleError(c, err, http.StatusInternalServerError, "Error al tratar de eliminar Rol_usuario")
 		return
 	}
+	if !enviarAPapeleraDeEmpresa(c, "roles_usuarios", rol_usuario.ID, empresaID, "Error al tratar de eliminar Rol_usuario") {
+		return
+	}
 
 	// Mostrar/enviar mensaje de eliminación éxitosa
 	c.JSON(http.StatusOK, gin.H{
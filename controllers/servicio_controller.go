@@ -4,7 +4,6 @@ import (
 	"errors"
 	"net/http"
 	"strconv"
-	"time"
 	"v1_prefabricadas/configs"
 	"v1_prefabricadas/dto"
 	"v1_prefabricadas/models"
@@ -37,7 +36,7 @@ func CrearServicio(c *gin.Context) {
 	}
 
 	// Agregamos el Servicio a la Base de Datos
-	if err := configs.DB.Create(&servicio).Error; err != nil {
+	if err := configs.DB.WithContext(c).Create(&servicio).Error; err != nil {
 		HandleError(c, err, http.StatusInternalServerError, "No se pudo crear el Servicio")
 		return
 	}
@@ -60,7 +59,8 @@ func CrearServicio(c *gin.Context) {
 // Función para obtener todos los servicio de la Empresa
 func ObtenerServicios(c *gin.Context) {
 	var servicios []models.Servicio
-	var serviciosResponse []dto.ServicioResponse
+	serviciosResponse := []dto.ServicioResponse{}
+
 	idParamEmpresa := c.Param("empresaID")
 	empresaID, err := strconv.ParseUint(idParamEmpresa, 10, 64)
 	if err != nil {
@@ -68,8 +68,14 @@ func ObtenerServicios(c *gin.Context) {
 		return
 	}
 
+	// Parámetros de paginación
+	paginacion, ok := obtenerPaginacion(c, limiteMaximoPaginacion, false)
+	if !ok {
+		return
+	}
+
 	// Buscar todos los servicios de la Empresa de acuerdoa su ID
-	if err := configs.DB.Where("empresa_id = ?", empresaID).Where("deleted_at IS NULL").Find(&servicios).Error; err != nil {
+	if err := paginacion.paginar(configs.DB.Where("empresa_id = ?", empresaID).Where("deleted_at IS NULL"), "id ASC", "id", false, &servicios); err != nil {
 		HandleError(c, err, http.StatusInternalServerError, "Servicios no encontrados")
 		return
 	}
@@ -84,7 +90,7 @@ func ObtenerServicios(c *gin.Context) {
 	}
 
 	// Mostrar/enviar respuesta
-	c.JSON(http.StatusOK, gin.H{"servicios": serviciosResponse})
+	paginacion.responder(c, "servicios", serviciosResponse)
 }
 
 // Obtener un Servicio por su ID
@@ -168,7 +174,7 @@ func ActualizarServicio(c *gin.Context) {
 	servicio.DescripcionServicio = request.DescripcionServicio
 
 	// Guardar los datos en la Base de Datos
-	if err := configs.DB.Save(&servicio).Error; err != nil {
+	if err := configs.DB.WithContext(c).Save(&servicio).Error; err != nil {
 		HandleError(c, err, http.StatusInternalServerError, "No se pudo actualizar los datos del Servicio")
 		return
 	}
@@ -220,13 +226,8 @@ func EliminarServicio(c *gin.Context) {
 		return
 	}
 
-	// Establecer facha y hora de la eliminación lógica
-	now := time.Now()
-	servicio.DeletedAt = &now
-
-	// Guardar fecha y hora de ekliminación en la base de datos
-	if err := configs.DB.Save(&servicio).Error; err != nil {
-		HandleError(c, err, http.StatusInternalServerError, "No se pudo eliminar Servicio")
+	// Eliminar lógicamente y registrar en la papelera
+	if !enviarAPapelera(c, "servicios", servicio.ID, "No se pudo eliminar Servicio") {
 		return
 	}
 
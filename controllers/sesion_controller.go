@@ -0,0 +1,135 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"v1_prefabricadas/configs"
+	"v1_prefabricadas/dto"
+	"v1_prefabricadas/models"
+	"v1_prefabricadas/services"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Función para obtener las sesiones activas del usuario autenticado
+func ObtenerMisSesiones(c *gin.Context) {
+	// Parámetros de paginación
+	paginacion, ok := obtenerPaginacion(c, limiteMaximoPaginacion, false)
+	if !ok {
+		return
+	}
+
+	var sesiones []models.Sesion
+	if err := paginacion.paginar(services.ConsultaSesionesActivas(c.GetUint("usuarioID")), services.OrdenSesiones, "id", true, &sesiones); err != nil {
+		HandleError(c, err, http.StatusInternalServerError, "Error al obtener las sesiones")
+		return
+	}
+
+	paginacion.responder(c, "sesiones", sesionesResponse(sesiones, c.GetUint("sesionID")))
+}
+
+// Función para cerrar una sesión específica del usuario autenticado
+func RevocarMiSesion(c *gin.Context) {
+	usuarioID := c.GetUint("usuarioID")
+
+	idParamSesion := c.Param("sesionID")
+	sesionID, err := strconv.ParseUint(idParamSesion, 10, 64)
+	if err != nil {
+		HandleError(c, nil, http.StatusBadRequest, "ID Sesión inválido")
+		return
+	}
+
+	var sesion models.Sesion
+	if err := configs.DB.Where("usuario_id = ? AND revoked_at IS NULL", usuarioID).First(&sesion, sesionID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			HandleError(c, nil, http.StatusNotFound, "Sesión no encontrada")
+			return
+		}
+		HandleError(c, err, http.StatusInternalServerError, "Error al obtener la sesión")
+		return
+	}
+
+	if err := services.RevocarSesion(sesion.ID, usuarioID); err != nil {
+		HandleError(c, err, http.StatusInternalServerError, "Error, no se pudo cerrar la sesión")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Sesión cerrada exitosamente"})
+}
+
+// Función para cerrar todas las sesiones del usuario autenticado excepto la actual
+func RevocarMisOtrasSesiones(c *gin.Context) {
+	if err := services.RevocarOtrasSesiones(c.GetUint("usuarioID"), c.GetUint("sesionID")); err != nil {
+		HandleError(c, err, http.StatusInternalServerError, "Error, no se pudo cerrar las sesiones")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Se cerraron las demás sesiones exitosamente"})
+}
+
+// Función para que el super administrador vea las sesiones activas de un Usuario
+func ObtenerSesionesUsuario(c *gin.Context) {
+	idParamUsuario := c.Param("usuarioID")
+	usuarioID, err := strconv.ParseUint(idParamUsuario, 10, 64)
+	if err != nil {
+		HandleError(c, nil, http.StatusBadRequest, "ID Usuario inválido")
+		return
+	}
+
+	// Parámetros de paginación
+	paginacion, ok := obtenerPaginacion(c, limiteMaximoPaginacion, false)
+	if !ok {
+		return
+	}
+
+	var sesiones []models.Sesion
+	if err := paginacion.paginar(services.ConsultaSesionesActivas(uint(usuarioID)), services.OrdenSesiones, "id", true, &sesiones); err != nil {
+		HandleError(c, err, http.StatusInternalServerError, "Error al obtener las sesiones del Usuario")
+		return
+	}
+
+	paginacion.responder(c, "sesiones", sesionesResponse(sesiones, c.GetUint("sesionID")))
+}
+
+// Función para que el super administrador cierre todas las sesiones de un Usuario (cuenta comprometida)
+func RevocarSesionesDeUsuario(c *gin.Context) {
+	idParamUsuario := c.Param("usuarioID")
+	usuarioID, err := strconv.ParseUint(idParamUsuario, 10, 64)
+	if err != nil {
+		HandleError(c, nil, http.StatusBadRequest, "ID Usuario inválido")
+		return
+	}
+
+	// Revocar y dejar constancia en la auditoría, ya que las sesiones no se auditan automáticamente
+	err = configs.DB.Transaction(func(tx *gorm.DB) error {
+		if err := services.RevocarSesionesUsuario(tx, uint(usuarioID)); err != nil {
+			return err
+		}
+		return services.RegistrarAuditoria(tx, c, "sesiones", uint(usuarioID), services.AccionAuditoriaEditar,
+			nil, map[string]interface{}{"sesiones_revocadas_usuario_id": usuarioID})
+	})
+	if err != nil {
+		HandleError(c, err, http.StatusInternalServerError, "Error, no se pudo cerrar las sesiones del Usuario")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Todas las sesiones del Usuario fueron cerradas"})
+}
+
+func sesionesResponse(sesiones []models.Sesion, sesionActual uint) []dto.SesionResponse {
+	response := []dto.SesionResponse{}
+	for _, sesion := range sesiones {
+		response = append(response, dto.SesionResponse{
+			ID:         sesion.ID,
+			CreatedAt:  sesion.CreatedAt,
+			LastSeenAt: sesion.LastSeenAt,
+			ExpiresAt:  sesion.ExpiresAt,
+			UserAgent:  sesion.UserAgent,
+			IP:         sesion.IP,
+			Actual:     sesion.ID == sesionActual,
+		})
+	}
+	return response
+}
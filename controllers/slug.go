@@ -0,0 +1,100 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"v1_prefabricadas/configs"
+	"v1_prefabricadas/services"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Obtener el ID del parámetro de la ruta, que puede ser el ID numérico o el slug del registro.
+// Si el slug es antiguo responde 301 hacia la misma URL con el slug vigente; en ese caso
+// (o si no se encuentra el registro) ya respondió y devuelve false.
+func resolverIDOSlug(c *gin.Context, parametro, entidad string, empresaID uint, mensajeNoEncontrado string) (uint, bool) {
+	valor := c.Param(parametro)
+	if id, err := strconv.ParseUint(valor, 10, 64); err == nil {
+		return uint(id), true
+	}
+
+	resuelto, err := services.ResolverSlug(entidad, empresaID, valor)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			HandleError(c, nil, http.StatusNotFound, mensajeNoEncontrado)
+			return 0, false
+		}
+		HandleError(c, err, http.StatusInternalServerError, "Error al buscar el slug")
+		return 0, false
+	}
+
+	if resuelto.Slug != valor {
+		destino := url.URL{
+			Path:     rutaConParametro(c, parametro, resuelto.Slug),
+			RawQuery: c.Request.URL.RawQuery,
+		}
+		c.Redirect(http.StatusMovedPermanently, destino.String())
+		return 0, false
+	}
+
+	return resuelto.ID, true
+}
+
+// Ruta de la solicitud con el segmento del parámetro reemplazado por el valor indicado. El segmento se
+// ubica en el patrón de la ruta, ya que el parámetro no siempre es el último (".../:prefabricadaID/ficha.pdf").
+func rutaConParametro(c *gin.Context, parametro, valor string) string {
+	segmentos := strings.Split(c.Request.URL.Path, "/")
+	patron := strings.Split(c.FullPath(), "/")
+	for i := range patron {
+		if patron[i] == ":"+parametro && i < len(segmentos) {
+			segmentos[i] = valor
+			return strings.Join(segmentos, "/")
+		}
+	}
+	segmentos[len(segmentos)-1] = valor
+	return strings.Join(segmentos, "/")
+}
+
+// Responder el error de asignar un slug: 409 si está en uso, 400 si es inválido
+func handleErrorSlug(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, services.ErrSlugEnUso):
+		HandleError(c, nil, http.StatusConflict, "El slug ya está en uso, intente con otro")
+	case errors.Is(err, services.ErrSlugInvalido):
+		HandleError(c, nil, http.StatusBadRequest, "Slug inválido: "+err.Error())
+	default:
+		HandleError(c, err, http.StatusInternalServerError, message)
+	}
+}
+
+// Slug para las respuestas (vacío si el registro aún no tiene)
+func valorSlug(slug *string) string {
+	if slug == nil {
+		return ""
+	}
+	return *slug
+}
+
+// Obtener el slug a guardar: el indicado en la solicitud, el actual si el texto del que se genera
+// no cambió o uno nuevo generado desde el texto. Si hay un error ya respondió y devuelve false.
+func slugParaGuardar(c *gin.Context, entidad string, empresaID, id uint, actual *string, solicitado, texto string, textoCambio bool) (string, bool) {
+	var slug string
+	var err error
+	switch {
+	case solicitado != "":
+		slug, err = services.ValidarSlug(configs.DB, entidad, empresaID, solicitado, id)
+	case actual != nil && *actual != "" && !textoCambio:
+		return *actual, true
+	default:
+		slug, err = services.GenerarSlugUnico(configs.DB, entidad, empresaID, texto, id)
+	}
+	if err != nil {
+		handleErrorSlug(c, err, "Error al generar el slug")
+		return "", false
+	}
+	return slug, true
+}
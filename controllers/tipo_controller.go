@@ -2,7 +2,6 @@ package controllers
 
 import (
 	"net/http"
-	"time"
 	"v1_prefabricadas/configs"
 	"v1_prefabricadas/dto"
 	"v1_prefabricadas/models"
@@ -35,7 +34,7 @@ func CrearTipo(c *gin.Context) {
 	}
 
 	// Guardamos Tipo en la Base de Datos
-	if err := configs.DB.Create(&tipo).Error; err != nil {
+	if err := configs.DB.WithContext(c).Create(&tipo).Error; err != nil {
 		handleErrorTipo(c, err, http.StatusInternalServerError, "No se pudo crear Empresa")
 		return
 	}
@@ -60,12 +59,18 @@ func CrearTipo(c *gin.Context) {
 func ObtenerTipos(c *gin.Context) {
 	var tipos []models.Tipo
 
-	if err := configs.DB.Where("deleted_at IS NULL").Find(&tipos).Error; err != nil {
+	// Parámetros de paginación
+	paginacion, ok := obtenerPaginacion(c, limiteMaximoPaginacion, false)
+	if !ok {
+		return
+	}
+
+	if err := paginacion.paginar(configs.DB.Where("deleted_at IS NULL"), "id ASC", "id", false, &tipos); err != nil {
 		handleErrorTipo(c, err, http.StatusInternalServerError, "No se pudieron obtener los tipos de estruturas")
 		return
 	}
 
-	var tiposResponse []dto.TipoResponse
+	tiposResponse := []dto.TipoResponse{}
 
 	for _, tipo := range tipos {
 		tiposResponse = append(tiposResponse, dto.TipoResponse{
@@ -77,7 +82,7 @@ func ObtenerTipos(c *gin.Context) {
 		})
 
 	}
-	c.JSON(http.StatusOK, gin.H{"tipos": tiposResponse})
+	paginacion.responder(c, "tipos", tiposResponse)
 }
 
 // Función para Obter el Tipo de Estructura de acuerso a su ID, excepto los eliminados logicamente
@@ -129,7 +134,7 @@ func ActualizarTipo(c *gin.Context) {
 	tipo.DescripcionMaterial = request.DescripcionMaterial
 
 	// Guardar los datos en la base de datos
-	if err := configs.DB.Save(tipo).Error; err != nil {
+	if err := configs.DB.WithContext(c).Save(tipo).Error; err != nil {
 		handleErrorTipo(c, err, http.StatusInternalServerError, "No se pudieron actualizar los datos del Tipo de Estructura")
 		return
 	}
@@ -161,13 +166,8 @@ func EliminarTipo(c *gin.Context) {
 		return
 	}
 
-	// Establecer Fecha y hora de la eliminación logica
-	now := time.Now()
-	tipo.DeletedAt = &now
-
-	// Actualizar el registro de tipo en la base de datos
-	if err := configs.DB.Save(&tipo).Error; err != nil {
-		handleErrorTipo(c, err, http.StatusInternalServerError, "No se pudo eliminar el Tipo de estructura")
+	// Eliminar lógicamente junto con sus vínculos con categorías, siempre que ninguna prefabricada lo use
+	if !eliminarCatalogoGlobal(c, "tipos", tipo.ID, "No se pudo eliminar el Tipo de estructura") {
 		return
 	}
 
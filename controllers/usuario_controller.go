@@ -4,11 +4,11 @@ import (
 	"errors"
 	"net/http"
 	"strconv"
-	"time"
 	"v1_prefabricadas/configs"
 	"v1_prefabricadas/dto"
 	"v1_prefabricadas/models"
 	"v1_prefabricadas/services"
+	"v1_prefabricadas/utils"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
@@ -65,12 +65,38 @@ func CrearUsuario(c *gin.Context) {
 	usuario.Image = url
 	usuario.EmpresaID = uint(empresaID)
 
-	// Guardar Usuario en la base de datos
-	if err := configs.DB.Create(&usuario).Error; err != nil {
-		HandleError(c, err, http.StatusInternalServerError, "Error, no se pudo crear el Usuario")
+	// Guardar Usuario y, si se indicó un email, sus credenciales con la invitación en una transacción
+	var tokenInvitacion string
+	err = configs.DB.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&usuario).Error; err != nil {
+			return err
+		}
+
+		if request.Email == "" {
+			return nil
+		}
+
+		credencial := models.Credencial{
+			Email:     request.Email,
+			UsuarioID: usuario.ID,
+		}
+		if err := tx.Create(&credencial).Error; err != nil {
+			return err
+		}
+
+		tokenInvitacion, err = services.CrearInvitacion(tx, credencial.ID)
+		return err
+	})
+	if err != nil {
+		HandleError(c, err, http.StatusInternalServerError, "Error, no se pudo crear el Usuario, si indicó un email intente con otro")
 		return
 	}
 
+	// El usuario define su contraseña y confirma su email desde el enlace de la invitación
+	if tokenInvitacion != "" {
+		go utils.EnviarEmailInvitacion(request.Email, services.URLInvitacion(tokenInvitacion))
+	}
+
 	usuarioResponse = dto.UsuarioResponse{
 		ID:              usuario.ID,
 		CreatedAt:       usuario.CreatedAt,
@@ -93,7 +119,7 @@ func CrearUsuario(c *gin.Context) {
 // Función para obtener todos los usuarios de una empresa
 func ObtenerUsuarios(c *gin.Context) {
 	var usuarios []models.Usuario
-	var usuariosResponse []dto.UsuarioResponse
+	usuariosResponse := []dto.UsuarioResponse{}
 
 	idParamEmpresa := c.Param("empresaID")
 	empresaID, err := strconv.ParseUint(idParamEmpresa, 10, 64)
@@ -102,15 +128,15 @@ func ObtenerUsuarios(c *gin.Context) {
 		return
 	}
 
-	// Buscar todos los usuarios de una empresa
-	if err := configs.DB.Where("empresa_id = ?", empresaID).Where("deleted_at IS NULL").Find(&usuarios).Error; err != nil {
-		HandleError(c, err, http.StatusInternalServerError, "Error, no se pudo obtener a los Usuarios")
+	// Parámetros de paginación
+	paginacion, ok := obtenerPaginacion(c, 20, true)
+	if !ok {
 		return
 	}
 
-	// Controllar si no se encuentran registros
-	if len(usuarios) == 0 {
-		HandleError(c, nil, http.StatusNotFound, "Sin Usuarios guardados")
+	// Buscar todos los usuarios de una empresa
+	if err := paginacion.paginar(configs.DB.Where("empresa_id = ?", empresaID).Where("deleted_at IS NULL"), "id ASC", "id", false, &usuarios); err != nil {
+		HandleError(c, err, http.StatusInternalServerError, "Error, no se pudo obtener a los Usuarios")
 		return
 	}
 
@@ -129,7 +155,7 @@ func ObtenerUsuarios(c *gin.Context) {
 	}
 
 	// Mostrar/enviar los Usuarios
-	c.JSON(http.StatusOK, gin.H{"usuarios": usuariosResponse})
+	paginacion.responder(c, "usuarios", usuariosResponse)
 }
 
 // Función para obtener un Usuario de acuerdo a su ID
@@ -197,6 +223,11 @@ func ActualizarUsuario(c *gin.Context) {
 		return
 	}
 
+	// No se gestionan usuarios con un rol superior
+	if !puedeGestionarUsuario(c, uint(usuarioID)) {
+		return
+	}
+
 	// Bind JSON del request a la estructura de dto
 	if err := c.ShouldBind(&request); err != nil {
 		HandleError(c, err, http.StatusBadRequest, "Error en los datos del formulario")
@@ -240,7 +271,7 @@ func ActualizarUsuario(c *gin.Context) {
 	usuario.SegundoApellido = request.SegundoApellido
 
 	// Guradar en la Base de datos
-	if err := configs.DB.Save(&usuario).Error; err != nil {
+	if err := configs.DB.WithContext(c).Save(&usuario).Error; err != nil {
 		HandleError(c, err, http.StatusInternalServerError, "Error, no se pudo actualizar los datos de Usuario")
 		return
 	}
@@ -282,6 +313,11 @@ func EliminarUsuario(c *gin.Context) {
 		return
 	}
 
+	// No se gestionan usuarios con un rol superior
+	if !puedeGestionarUsuario(c, uint(usuarioID)) {
+		return
+	}
+
 	// Buscar Usuario en la base de datos de acuerdo a su ID
 	if err := configs.DB.Where("empresa_id = ?", empresaID).Where("deleted_at IS NULL").Unscoped().First(&usuario, usuarioID).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -298,13 +334,14 @@ func EliminarUsuario(c *gin.Context) {
 		return
 	}
 
-	// Poner fecha y hora de la eliminación lógica
-	now := time.Now()
-	usuario.DeletedAt = &now
+	// Eliminar lógicamente con sus contactos y roles, y registrar en la papelera
+	if !enviarAPapelera(c, "usuarios", usuario.ID, "Error, no se pudo eliminar al usuario") {
+		return
+	}
 
-	// Guardar en la base de datos la fecha y hora de la eliminación lógica
-	if err := configs.DB.Save(&usuario).Error; err != nil {
-		HandleError(c, err, http.StatusInternalServerError, "Error, no se pudo eliminar al usuario")
+	// Cortar el acceso del usuario eliminado de forma inmediata
+	if err := services.RevocarSesionesUsuario(configs.DB, usuario.ID); err != nil {
+		HandleError(c, err, http.StatusInternalServerError, "Error, no se pudo cerrar las sesiones del usuario")
 		return
 	}
 
@@ -0,0 +1,22 @@
+package dto
+
+import "time"
+
+// Estructura para crear una API key de integración
+type CrearApiKeyRequest struct {
+	Nombre    string     `json:"nombre" binding:"required,max=100"`
+	Scopes    []string   `json:"scopes" binding:"required,min=1"` // ej. ["catalog:read", "noticias:write"]
+	ExpiresAt *time.Time `json:"expires_at"`                      // Opcional, sin fecha no expira
+}
+
+type ApiKeyResponse struct {
+	ID         uint       `json:"id"`
+	CreatedAt  time.Time  `json:"created_at"`
+	Nombre     string     `json:"nombre"`
+	Prefijo    string     `json:"prefijo"`
+	Scopes     []string   `json:"scopes"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+	EmpresaID  uint       `json:"empresa_id"`
+}
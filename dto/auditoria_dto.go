@@ -0,0 +1,20 @@
+package dto
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type AuditoriaResponse struct {
+	ID        uint            `json:"id"`
+	CreatedAt time.Time       `json:"created_at"`
+	UsuarioID uint            `json:"usuario_id"`
+	ApiKeyID  *uint           `json:"api_key_id"`
+	EmpresaID *uint           `json:"empresa_id"`
+	Entidad   string          `json:"entidad"`
+	EntidadID uint            `json:"entidad_id"`
+	Accion    string          `json:"accion"`
+	Antes     json.RawMessage `json:"antes"`
+	Despues   json.RawMessage `json:"despues"`
+	Ruta      string          `json:"ruta"`
+}
@@ -0,0 +1,13 @@
+package dto
+
+import "time"
+
+type BloqueoAccesoResponse struct {
+	ID               uint       `json:"id"`
+	Accion           string     `json:"accion"`
+	Tipo             string     `json:"tipo"`
+	Valor            string     `json:"valor"`
+	IntentosFallidos int        `json:"intentos_fallidos"`
+	UltimoIntentoAt  time.Time  `json:"ultimo_intento_at"`
+	BloqueadoHasta   *time.Time `json:"bloqueado_hasta"`
+}
@@ -0,0 +1,8 @@
+package dto
+
+// Estructura de un resultado de la búsqueda de Prefabricadas
+type ResultadoBusquedaPrefabricadaResponse struct {
+	Relevancia   float64              `json:"relevancia"`
+	Destacados   map[string]string    `json:"destacados"` // Campo -> fragmento con las coincidencias marcadas con <mark>
+	Prefabricada PrefabricadaResponse `json:"prefabricada"`
+}
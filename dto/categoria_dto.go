@@ -4,12 +4,14 @@ import "time"
 
 type CrearCategoriaRequest struct {
 	NombreCategoria      string                       `json:"nombre_categoria" binding:"required"`
+	Slug                 string                       `json:"slug"` // Opcional, por defecto se genera desde el nombre
 	DescripcionCategoria string                       `json:"descripcion_categoria"`
 	Tipos                []CrearTipo_categoriaRequest `json:"tipos"`
 }
 
 type ActualizarCategoriaRequest struct {
 	NombreCategoria      string                            `json:"nombre_categoria" binding:"required"`
+	Slug                 string                            `json:"slug"` // Opcional, por defecto se genera desde el nombre
 	DescripcionCategoria string                            `json:"descripcion_categoria"`
 	Tipos                []ActualizarTipo_categoriaRequest `json:"tipos"`
 }
@@ -18,6 +20,7 @@ type CategoriaResponse struct {
 	ID                   uint                     `json:"id"`
 	CreatedAt            time.Time                `json:"created_At"`
 	UpdatedAt            time.Time                `json:"updated_at"`
+	Slug                 string                   `json:"slug"`
 	NombreCategoria      string                   `json:"nombre_categoria"`
 	DescripcionCategoria string                   `json:"descripcion_categoria"`
 	Tipos                []Tipo_categoriaResponse `json:"tipos"`
@@ -2,22 +2,30 @@ package dto
 
 import "time"
 
-// Estructura para la solicitud de actualización de Credenciales(email y password) de usuario
+// Estructura para la solicitud de creación de Credenciales, la contraseña la define el usuario al aceptar la invitación
 type CrearCredencial struct {
-	Email    string `json:"email" binding:"required,email"`    // Validación de email
-	Password string `json:"password" binding:"required,min=6"` // Validar que la contraseña tenga al menos 6 caracteres
+	Email string `json:"email" binding:"required,email"` // Validación de email
 }
 
+// Estructura para que un administrador cambie el email de unas credenciales. La contraseña solo la define
+// el propio usuario (invitación, recuperación o /me)
 type ActualizarCredencialRequest struct {
-	Email    string `json:"email" binding:"required,email"` // Validación de email
-	Password string `json:"password,omitempty"`             // Permitir que el password sea opcional
+	Email string `json:"email" binding:"required,email"` // Validación de email
 }
 
 // DTO para mostrar la información de un usuario
 type CredencialResponse struct {
-	ID        uint      `json:"id"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
-	Email     string    `json:"email"`
-	UsuarioID uint      `json:"usuario_id" binding:"required"` // FK de Usuario requerido
+	ID              uint       `json:"id"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+	Email           string     `json:"email"`
+	EmailVerifiedAt *time.Time `json:"email_verified_at"`             // Nulo mientras la invitación no sea aceptada
+	UsuarioID       uint       `json:"usuario_id" binding:"required"` // FK de Usuario requerido
+}
+
+// Estructura para aceptar una invitación definiendo la contraseña
+type AceptarInvitacionRequest struct {
+	Token           string `json:"token" binding:"required"`
+	Password        string `json:"password" binding:"required"`
+	ConfirmPassword string `json:"confirm_password" binding:"required"`
 }
@@ -0,0 +1,52 @@
+package dto
+
+// Estructura para verificar el segundo factor durante el login
+type VerificarDosFactoresRequest struct {
+	ChallengeToken string `json:"challenge_token" binding:"required"`
+	Codigo         string `json:"codigo" binding:"required"` // Código TOTP de 6 dígitos o código de recuperación
+}
+
+// Estructura para iniciar el enrolamiento obligatorio durante el login
+type EnrolarDosFactoresLoginRequest struct {
+	ChallengeToken string `json:"challenge_token" binding:"required"`
+}
+
+// Estructura para confirmar o desactivar el segundo factor de un usuario autenticado
+type CodigoDosFactoresRequest struct {
+	Codigo string `json:"codigo" binding:"required"`
+}
+
+// Estructura con los datos para registrar el secreto en la aplicación autenticadora
+type EnrolamientoDosFactoresResponse struct {
+	Secreto string `json:"secreto"`
+	URI     string `json:"otpauth_uri"` // Se muestra como código QR en el frontend
+}
+
+// Estructura para la configuración de seguridad del sistema, solo se actualizan los campos enviados
+type ConfiguracionSeguridadRequest struct {
+	DosFactoresObligatorioSuperAdmin *bool                    `json:"2fa_obligatorio_super_administrador"`
+	PoliticaPassword                 *PoliticaPasswordRequest `json:"politica_password"`
+}
+
+type PoliticaPasswordRequest struct {
+	LongitudMinima    *int  `json:"longitud_minima" binding:"omitempty,min=6,max=72"`
+	RequiereMayuscula *bool `json:"requiere_mayuscula"`
+	RequiereMinuscula *bool `json:"requiere_minuscula"`
+	RequiereNumero    *bool `json:"requiere_numero"`
+	RequiereSimbolo   *bool `json:"requiere_simbolo"`
+	Historial         *int  `json:"historial" binding:"omitempty,min=0,max=24"` // Contraseñas anteriores que no se pueden reutilizar
+}
+
+type ConfiguracionSeguridadResponse struct {
+	DosFactoresObligatorioSuperAdmin bool                     `json:"2fa_obligatorio_super_administrador"`
+	PoliticaPassword                 PoliticaPasswordResponse `json:"politica_password"`
+}
+
+type PoliticaPasswordResponse struct {
+	LongitudMinima    int  `json:"longitud_minima"`
+	RequiereMayuscula bool `json:"requiere_mayuscula"`
+	RequiereMinuscula bool `json:"requiere_minuscula"`
+	RequiereNumero    bool `json:"requiere_numero"`
+	RequiereSimbolo   bool `json:"requiere_simbolo"`
+	Historial         int  `json:"historial"`
+}
@@ -0,0 +1,32 @@
+package dto
+
+// Estructura de una opción de faceta (categoría, estilo o tipo) con su cantidad de prefabricadas
+type OpcionFacetaResponse struct {
+	ID       uint   `json:"id"`
+	Nombre   string `json:"nombre"`
+	Cantidad int64  `json:"cantidad"`
+}
+
+// Estructura de un rango de faceta (hasta nulo indica un rango sin límite superior)
+type RangoFacetaResponse struct {
+	Desde    float64  `json:"desde"`
+	Hasta    *float64 `json:"hasta"`
+	Cantidad int64    `json:"cantidad"`
+}
+
+// Estructura con las cantidades por opción booleana
+type FacetaBooleanaResponse struct {
+	Si int64 `json:"true"`
+	No int64 `json:"false"`
+}
+
+type FacetasPrefabricadasResponse struct {
+	Total      int64                  `json:"total"`
+	Categorias []OpcionFacetaResponse `json:"categorias"`
+	Estilos    []OpcionFacetaResponse `json:"estilos"`
+	Tipos      []OpcionFacetaResponse `json:"tipos"`
+	Oferta     FacetaBooleanaResponse `json:"oferta"`
+	Destacada  FacetaBooleanaResponse `json:"destacada"`
+	M2         []RangoFacetaResponse  `json:"m2"`
+	Precio     []RangoFacetaResponse  `json:"precio"`
+}
@@ -0,0 +1,41 @@
+package dto
+
+import "time"
+
+type ImportacionResponse struct {
+	ID           uint                          `json:"id"`
+	CreatedAt    time.Time                     `json:"created_at"`
+	EmpresaID    uint                          `json:"empresa_id"`
+	UsuarioID    *uint                         `json:"usuario_id"`
+	ApiKeyID     *uint                         `json:"api_key_id"`
+	Archivo      string                        `json:"archivo"`
+	Formato      string                        `json:"formato"`
+	DryRun       bool                          `json:"dry_run"`
+	Estado       string                        `json:"estado"` // simulada, aplicada o rechazada
+	Filas        int                           `json:"filas"`
+	Creadas      int                           `json:"creadas"`
+	Actualizadas int                           `json:"actualizadas"`
+	Omitidas     int                           `json:"omitidas"`
+	Errores      int                           `json:"errores"`
+	Resultado    *ResultadoImportacionResponse `json:"resultado,omitempty"` // Solo en el detalle de una importación
+}
+
+type ResultadoImportacionResponse struct {
+	Cambios []CambioImportacionResponse `json:"cambios"`
+	Errores []ErrorImportacionResponse  `json:"errores"`
+}
+
+type CambioImportacionResponse struct {
+	Filas              []int    `json:"filas"`
+	Accion             string   `json:"accion"` // create, update o skip
+	PrefabricadaID     *uint    `json:"prefabricada_id"`
+	NombrePrefabricada string   `json:"nombre_prefabricada"`
+	Slug               string   `json:"slug"`
+	Detalles           []string `json:"detalles"`
+}
+
+type ErrorImportacionResponse struct {
+	Fila    int    `json:"fila"`
+	Columna string `json:"columna,omitempty"`
+	Mensaje string `json:"mensaje"`
+}
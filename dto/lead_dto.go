@@ -0,0 +1,26 @@
+package dto
+
+import "time"
+
+// Estructura para registrar un lead. Se requiere al menos un email o un teléfono para contactarlo.
+type CrearLeadRequest struct {
+	Nombre         string `json:"nombre" binding:"required,max=150"`
+	Email          string `json:"email" binding:"omitempty,email,max=150"`
+	Telefono       string `json:"telefono" binding:"omitempty,max=30"`
+	Mensaje        string `json:"mensaje" binding:"max=5000"`
+	Origen         string `json:"origen" binding:"max=100"`
+	PrefabricadaID *uint  `json:"prefabricada_id"` // Opcional, prefabricada por la que consulta
+}
+
+type LeadResponse struct {
+	ID             uint      `json:"id"`
+	CreatedAt      time.Time `json:"created_at"`
+	Nombre         string    `json:"nombre"`
+	Email          string    `json:"email"`
+	Telefono       string    `json:"telefono"`
+	Mensaje        string    `json:"mensaje"`
+	Origen         string    `json:"origen"`
+	PrefabricadaID *uint     `json:"prefabricada_id"`
+	ApiKeyID       *uint     `json:"api_key_id"`
+	EmpresaID      uint      `json:"empresa_id"`
+}
@@ -0,0 +1,28 @@
+package dto
+
+// DTO con los datos del usuario autenticado
+type MeResponse struct {
+	Usuario    UsuarioResponse    `json:"usuario"`
+	Credencial CredencialResponse `json:"credencial"`
+	Empresa    EmpresaResponse    `json:"empresa"`
+	Roles      []string           `json:"roles"`
+	Contactos  []ContactoResponse `json:"contactos"`
+}
+
+// Estructura para que el usuario cambie su contraseña
+type ActualizarMiPasswordRequest struct {
+	PasswordActual  string `json:"password_actual" binding:"required"`
+	PasswordNuevo   string `json:"password_nuevo" binding:"required"`
+	ConfirmPassword string `json:"confirm_password" binding:"required"`
+}
+
+// Estructura para que el usuario cambie su email, se confirma desde el enlace enviado a la nueva dirección
+type ActualizarMiEmailRequest struct {
+	Email          string `json:"email" binding:"required,email"`
+	PasswordActual string `json:"password_actual" binding:"required"`
+}
+
+// Estructura para confirmar un cambio de email
+type VerificarEmailRequest struct {
+	Token string `json:"token" binding:"required"`
+}
@@ -3,8 +3,12 @@ package dto
 import "time"
 
 type CrearNoticiaRequest struct {
-	TituloNoticia     string `json:"titulo_noticia" binding:"required"`
-	DesarrolloNoticia string `json:"desarrollo_noticia" binding:"required"`
+	TituloNoticia     string     `json:"titulo_noticia" binding:"required"`
+	DesarrolloNoticia string     `json:"desarrollo_noticia" binding:"required"`
+	Slug              string     `json:"slug"`         // Opcional, por defecto se genera desde el título
+	Estado            string     `json:"estado"`       // Opcional: draft (por defecto), scheduled, published o archived
+	PublishAt         *time.Time `json:"publish_at"`   // Fecha de publicación (requerida para scheduled)
+	UnpublishAt       *time.Time `json:"unpublish_at"` // Fecha de retiro de la publicación
 	//EmpresaID         uint   `json:"empresa_id" binding:"required"`
 	//UsuarioID         uint                         `json:"usuario_id" binding:"required"`
 	//Imagenes []CrearImagen_noticiaRequest `json:"imagenes"`
@@ -13,15 +17,20 @@ type CrearNoticiaRequest struct {
 type ActualizarNoticiaRequest struct {
 	TituloNoticia     string `json:"titulo_noticia" binding:"required"`
 	DesarrolloNoticia string `json:"desarrollo_noticia" binding:"required"`
+	Slug              string `json:"slug"` // Opcional, por defecto se genera desde el título
 	//Imagenes          []CrearImagen_noticiaRequest `json:"imagenes"`
 }
 
 type NoticiaResponse struct {
-	ID                uint      `json:"id"`
-	CreatedAt         time.Time `json:"created_at"`
-	UpdatedAt         time.Time `json:"updated_at"`
-	TituloNoticia     string    `json:"titulo_noticia" binding:"required"`
-	DesarrolloNoticia string    `json:"desarrollo_noticia" binding:"required"`
+	ID                uint       `json:"id"`
+	CreatedAt         time.Time  `json:"created_at"`
+	UpdatedAt         time.Time  `json:"updated_at"`
+	Slug              string     `json:"slug"`
+	TituloNoticia     string     `json:"titulo_noticia" binding:"required"`
+	DesarrolloNoticia string     `json:"desarrollo_noticia" binding:"required"`
+	Estado            string     `json:"estado"`
+	PublishAt         *time.Time `json:"publish_at"`
+	UnpublishAt       *time.Time `json:"unpublish_at"`
 	// UsuarioID         uint      `json:"usuario_id" binding:"required"`
 	EmpresaID uint `json:"empresa_id"`
 	//Imagenes          []CrearImagen_noticiaRequest `json:"imagenes"`
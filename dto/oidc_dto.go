@@ -0,0 +1,28 @@
+package dto
+
+// Estructura con la URL del proveedor y el estado firmado que el frontend debe devolver al completar el flujo
+type IniciarOIDCResponse struct {
+	AuthorizationURL string `json:"authorization_url"`
+	StateToken       string `json:"state_token"`
+}
+
+// Estructura para completar el login (o la vinculación) con los parámetros recibidos del proveedor
+type CompletarOIDCRequest struct {
+	Code       string `json:"code" binding:"required"`
+	State      string `json:"state" binding:"required"`
+	StateToken string `json:"state_token" binding:"required"`
+}
+
+// Estructura para la configuración de OpenID Connect de una Empresa
+type ConfiguracionOIDCRequest struct {
+	Habilitado            *bool    `json:"habilitado" binding:"required"`
+	DominiosPermitidos    []string `json:"dominios_permitidos" binding:"dive,fqdn"` // ej. ["casasemilia.cl"]
+	VinculacionAutomatica *bool    `json:"vinculacion_automatica" binding:"required"`
+}
+
+type ConfiguracionOIDCResponse struct {
+	Habilitado            bool     `json:"habilitado"`
+	DominiosPermitidos    []string `json:"dominios_permitidos"`
+	VinculacionAutomatica bool     `json:"vinculacion_automatica"`
+	EmpresaID             uint     `json:"empresa_id"`
+}
@@ -0,0 +1,22 @@
+package dto
+
+import "time"
+
+type PapeleraResponse struct {
+	ID                    uint      `json:"id"`
+	EmpresaID             uint      `json:"empresa_id"`
+	Entidad               string    `json:"entidad"`
+	EntidadID             uint      `json:"entidad_id"`
+	Nombre                string    `json:"nombre"`
+	UsuarioID             *uint     `json:"usuario_id"`
+	EliminadoAt           time.Time `json:"eliminado_at"`
+	EliminacionDefinitiva time.Time `json:"eliminacion_definitiva"` // Fecha desde la que se elimina sin posibilidad de restaurar
+}
+
+type ConfiguracionPapeleraRequest struct {
+	DiasRetencion int `json:"dias_retencion" binding:"required,min=1,max=3650"`
+}
+
+type ConfiguracionPapeleraResponse struct {
+	DiasRetencion int `json:"dias_retencion"`
+}
@@ -0,0 +1,13 @@
+package dto
+
+// Estructura para asignar los permisos de un Rol (reemplaza los permisos actuales)
+type AsignarPermisosRequest struct {
+	Permisos []uint `json:"permisos" binding:"required"` // IDs de los permisos
+}
+
+// Estructura para mostrar la información de un Permiso
+type PermisoResponse struct {
+	ID                 uint   `json:"id"`
+	NombrePermiso      string `json:"nombre_permiso"`
+	DescripcionPermiso string `json:"descripcion_permiso"`
+}
@@ -5,7 +5,10 @@ import "time"
 type CrearPortadaRequest struct {
 	NombrePortada string `form:"nombre_portada" binding:"required"` // Para multipart/form-data
 	//Image         string `json:"image"`
-	EmpresaID uint `form:"empresa_id"` // Si envías este campo en el formulario
+	EmpresaID   uint       `form:"empresa_id"`   // Si envías este campo en el formulario
+	Estado      string     `form:"estado"`       // Opcional: draft (por defecto), scheduled, published o archived
+	PublishAt   *time.Time `form:"publish_at"`   // Fecha RFC 3339 de publicación (requerida para scheduled)
+	UnpublishAt *time.Time `form:"unpublish_at"` // Fecha RFC 3339 de retiro de la publicación
 }
 
 type ActualizarPortadaRequest struct {
@@ -15,10 +18,13 @@ type ActualizarPortadaRequest struct {
 }
 
 type PortadaResponse struct {
-	ID            uint      `json:"id"`
-	CreatedAt     time.Time `json:"created_at"`
-	UpdatedAt     time.Time `json:"updated_at"`
-	NombrePortada string    `json:"nombre_portada"`
-	Image         string    `json:"image"`
-	EmpresaID     uint      `json:"empresa_id"`
+	ID            uint       `json:"id"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+	NombrePortada string     `json:"nombre_portada"`
+	Image         string     `json:"image"`
+	Estado        string     `json:"estado"`
+	PublishAt     *time.Time `json:"publish_at"`
+	UnpublishAt   *time.Time `json:"unpublish_at"`
+	EmpresaID     uint       `json:"empresa_id"`
 }
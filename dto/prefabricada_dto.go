@@ -4,6 +4,7 @@ import "time"
 
 type CrearPrefabricadaRequest struct {
 	NombrePrefabricada string `json:"nombre_prefabricada" binding:"required"`
+	Slug               string `json:"slug"` // Opcional, por defecto se genera desde el nombre
 	M2                 int    `json:"m2" binding:"required"`
 	Garantia           string `json:"garantia" binding:"required"`
 	Eslogan            string `json:"eslogan"`
@@ -12,12 +13,16 @@ type CrearPrefabricadaRequest struct {
 	Oferta             bool   `json:"oferta"`
 	CategoriaID        uint   `json:"categoria_id" binding:"required"`
 	//EmpresaID          uint   `json:"empresa_id" binding:"required"`
-	EstiloID uint `json:"estilo_id" binding:"required"`
-	TipoID   uint `json:"tipo_id" binding:"required"`
+	EstiloID    uint       `json:"estilo_id" binding:"required"`
+	TipoID      uint       `json:"tipo_id" binding:"required"`
+	Estado      string     `json:"estado"`       // Opcional: draft (por defecto), scheduled, published o archived
+	PublishAt   *time.Time `json:"publish_at"`   // Fecha de publicación (requerida para scheduled)
+	UnpublishAt *time.Time `json:"unpublish_at"` // Fecha de retiro de la publicación
 }
 
 type ActualizarPrefabricadaRequest struct {
 	NombrePrefabricada string `json:"nombre_prefabricada" binding:"required"`
+	Slug               string `json:"slug"` // Opcional, por defecto se genera desde el nombre
 	M2                 int    `json:"m2" binding:"required"`
 	Garantia           string `json:"garantia" binding:"required"`
 	Eslogan            string `json:"eslogan"`
@@ -29,10 +34,18 @@ type ActualizarPrefabricadaRequest struct {
 	EstiloID uint `json:"estilo_id" binding:"required"`
 	TipoID   uint `json:"tipo_id"`
 }
+type ClonarPrefabricadaRequest struct {
+	NombrePrefabricada string `json:"nombre_prefabricada"`          // Opcional, por defecto el nombre de la original con "(copia)"
+	Slug               string `json:"slug"`                         // Opcional, por defecto se genera desde el nombre
+	M2                 *int   `json:"m2" binding:"omitempty,min=1"` // Opcional, por defecto los m2 de la original
+	CopiarImagenes     bool   `json:"copiar_imagenes"`              // Copiar las imágenes a nuevos archivos en S3 en vez de reutilizarlas
+}
+
 type PrefabricadaResponse struct {
 	ID                    uint                          `json:"id"`
 	CreatedAt             time.Time                     `json:"created_at"`
 	UpdatedAt             time.Time                     `json:"updated_at"`
+	Slug                  string                        `json:"slug"`
 	NombrePrefabricada    string                        `json:"nombre_prefabricada" binding:"required"`
 	M2                    int                           `json:"m2" binding:"required"`
 	Garantia              string                        `json:"garantia" binding:"required"`
@@ -44,6 +57,9 @@ type PrefabricadaResponse struct {
 	EmpresaID             uint                          `json:"empresa_id" binding:"required"`
 	EstiloID              uint                          `json:"estilo_id" binding:"required"`
 	TipoID                uint                          `json:"tipo_id"`
+	Estado                string                        `json:"estado"`
+	PublishAt             *time.Time                    `json:"publish_at"`
+	UnpublishAt           *time.Time                    `json:"unpublish_at"`
 	ImagenesPrefabricadas []Imagen_prefabricadaResponse `json:"imagenes_prefabricadas"`
 	Caracteristicas       []CaracteristicaResponse      `json:"caracteristicas"`
 	Precios               []PrecioResponse              `json:"precios"`
@@ -0,0 +1,16 @@
+package dto
+
+import "time"
+
+type ActualizarPublicacionRequest struct {
+	Estado      string     `json:"estado"`       // draft, scheduled, published o archived (vacío: se deduce de publish_at)
+	PublishAt   *time.Time `json:"publish_at"`   // Fecha de publicación (requerida para scheduled)
+	UnpublishAt *time.Time `json:"unpublish_at"` // Fecha de retiro de la publicación
+}
+
+type PublicacionResponse struct {
+	ID          uint       `json:"id"`
+	Estado      string     `json:"estado"`
+	PublishAt   *time.Time `json:"publish_at"`
+	UnpublishAt *time.Time `json:"unpublish_at"`
+}
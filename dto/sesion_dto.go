@@ -0,0 +1,14 @@
+package dto
+
+import "time"
+
+// DTO para mostrar una sesión (dispositivo) del usuario
+type SesionResponse struct {
+	ID         uint       `json:"id"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastSeenAt *time.Time `json:"last_seen_at"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	UserAgent  string     `json:"user_agent"`
+	IP         string     `json:"ip"`
+	Actual     bool       `json:"actual"` // La sesión desde la que se hace la consulta
+}
@@ -11,6 +11,7 @@ type CrearUsuarioRequest struct {
 	PrimerApellido  string                `form:"primer_apellido" binding:"required"`
 	SegundoApellido string                `form:"segundo_nombre"`
 	Image           *multipart.FileHeader `form:"image"`
+	Email           string                `form:"email" binding:"omitempty,email"` // Si se indica, se envía una invitación de acceso
 }
 
 type ActualizarUsuarioRequest struct {
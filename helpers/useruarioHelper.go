@@ -38,6 +38,14 @@ func ValidarUsuarioID(c *gin.Context) (uint, error) {
 		}
 	}
 
+	// Validar que la Empresa de la ruta (si existe) sea la del usuario
+	if paramEmpresa := c.Param("empresaID"); paramEmpresa != "" {
+		empresaID, ok := c.Get("empresaID")
+		if !ok || paramEmpresa != strconv.FormatUint(uint64(empresaID.(uint)), 10) {
+			return 0, fmt.Errorf("usuario no autorizado para esta empresa")
+		}
+	}
+
 	// Validar que el usuario solo acceda a sus propios recursos si no es superadmin
 	paramUsuarioID, err := strconv.ParseUint(c.Param("usuarioID"), 10, 64)
 	if err != nil || uint(paramUsuarioID) != usuarioID {
@@ -4,6 +4,7 @@ import (
 	"log"
 	"v1_prefabricadas/configs"
 	"v1_prefabricadas/routers"
+	"v1_prefabricadas/services"
 
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
@@ -18,6 +19,16 @@ func init() {
 
 	// Conectar a la base de datos
 	configs.ConnectToDB()
+
+	// Cargar las llaves de firma de los tokens; sin ellas la API no debe arrancar
+	if err := services.InicializarTokens(); err != nil {
+		log.Fatalf("No se pudo inicializar el servicio de tokens: %v", err)
+	}
+
+	// Registrar los cambios hechos desde el panel de administración
+	if err := services.RegistrarCallbacksAuditoria(configs.DB); err != nil {
+		log.Fatalf("No se pudo registrar la auditoría: %v", err)
+	}
 }
 
 func main() {
@@ -31,5 +42,11 @@ func main() {
 		})
 	})
 
+	// Publicar y archivar en segundo plano según las fechas programadas
+	services.IniciarProgramadorPublicacion()
+
+	// Eliminar definitivamente lo que lleva en la papelera más días que la retención configurada
+	services.IniciarPurgaPapelera()
+
 	router.Run(":8080")
 }
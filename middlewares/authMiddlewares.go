@@ -1,26 +1,24 @@
 package middlewares
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
-	"os"
 	"strings"
 	"time"
+	"v1_prefabricadas/services"
 
 	"github.com/gin-gonic/gin"
-	"github.com/golang-jwt/jwt/v4"
 )
 
-var jwtKey = []byte(os.Getenv("JWT_SECRET"))
-
-type Claims struct {
-	UsuarioID uint     `json:"usuario_id"`
-	Rol       []string `json:"roles"` // Cambiado a slice para múltiples roles
-	jwt.RegisteredClaims
-}
-
 func AuthMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
+		// Las integraciones pueden autenticarse con una API key en lugar de un JWT
+		if apiKey := obtenerApiKey(c); apiKey != "" {
+			autenticarApiKey(c, apiKey)
+			return
+		}
+
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "No se proporcionó token"})
@@ -35,23 +33,37 @@ func AuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
-		token, err := jwt.ParseWithClaims(tokenString[1], &Claims{}, func(token *jwt.Token) (interface{}, error) {
-			return jwtKey, nil
-		})
-		if err != nil {
+		// Verifica la firma con la llave indicada en el kid del token
+		claims := &services.ClaimsAcceso{}
+		if err := services.VerificarToken(tokenString[1], claims); err != nil {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "Token inválido"})
 			c.Abort()
 			return
 		}
 
-		// Verifica si el token es válido y tiene claims
-		if claims, ok := token.Claims.(*Claims); ok && token.Valid {
-			if claims.ExpiresAt.After(time.Now()) {
+		// Verifica que el token tenga claims de acceso
+		if claims.UsuarioID != 0 && claims.SesionID != 0 {
+			if claims.ExpiresAt != nil && claims.ExpiresAt.After(time.Now()) {
+				// Verificar que la sesión no haya sido revocada (logout, despido o cambio de contraseña)
+				activa, err := services.SesionActiva(claims.SesionID, claims.UsuarioID, c.ClientIP())
+				if err != nil {
+					c.JSON(http.StatusInternalServerError, gin.H{"error": "No se pudo verificar la sesión"})
+					c.Abort()
+					return
+				}
+				if !activa {
+					c.JSON(http.StatusUnauthorized, gin.H{"error": "Sesión revocada"})
+					c.Abort()
+					return
+				}
+
 				// Almacena usuarioID y roles en el contexto
 				c.Set("usuarioID", claims.UsuarioID)
-				c.Set("roles", claims.Rol)                                             // Almacena el slice de roles en el contexto
+				c.Set("empresaID", claims.EmpresaID)
+				c.Set("sesionID", claims.SesionID)
+				c.Set("roles", claims.Roles)                                           // Almacena el slice de roles en el contexto
 				fmt.Println("ID de usuario almacenado en contexto:", claims.UsuarioID) // Log de éxito
-				fmt.Println("Roles almacenados en contexto:", claims.Rol)              // Log de éxito
+				fmt.Println("Roles almacenados en contexto:", claims.Roles)            // Log de éxito
 			} else {
 				c.JSON(http.StatusUnauthorized, gin.H{"error": "Token expirado"})
 				c.Abort()
@@ -67,6 +79,50 @@ func AuthMiddleware() gin.HandlerFunc {
 	}
 }
 
+// SoloUsuarioMiddleware rechaza las API keys en rutas que actúan sobre el propio usuario autenticado
+func SoloUsuarioMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetUint("usuarioID") == 0 {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Esta ruta requiere iniciar sesión como usuario"})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// Obtener la API key desde la cabecera X-API-Key o desde "Authorization: Bearer ce_..."
+func obtenerApiKey(c *gin.Context) string {
+	if apiKey := c.GetHeader("X-API-Key"); apiKey != "" {
+		return apiKey
+	}
+	if token, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer "); ok && strings.HasPrefix(token, services.PrefijoApiKey) {
+		return token
+	}
+	return ""
+}
+
+// Autenticar una API key: la llave actúa sobre su empresa con los permisos que otorgan sus scopes
+func autenticarApiKey(c *gin.Context, llave string) {
+	apiKey, permisos, err := services.AutenticarApiKey(llave)
+	if err != nil {
+		if errors.Is(err, services.ErrApiKeyInvalida) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "No se pudo verificar la API key"})
+		}
+		c.Abort()
+		return
+	}
+
+	c.Set("apiKeyID", apiKey.ID)
+	c.Set("empresaID", apiKey.EmpresaID)
+	c.Set("roles", []string{}) // Sin roles: solo cuentan los permisos de sus scopes
+	c.Set("permisos", permisos)
+
+	c.Next()
+}
+
 // Verificar si el usuarioID y los roles están almacenados en el contexto
 func VerificarContexto(c *gin.Context) {
 	usuarioID, usuarioIDExists := c.Get("usuarioID")
@@ -0,0 +1,106 @@
+package middlewares
+
+import (
+	"net/http"
+	"strconv"
+	"v1_prefabricadas/configs"
+	"v1_prefabricadas/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// EmpresaMiddleware impide que un usuario opere sobre una Empresa distinta a la suya.
+// El super_administrador puede operar sobre todas las empresas.
+func EmpresaMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		roles, _ := c.Get("roles")
+		rolesList, _ := roles.([]string)
+		for _, rol := range rolesList {
+			if rol == services.RolSuperAdministrador {
+				c.Next()
+				return
+			}
+		}
+
+		empresaID, exists := c.Get("empresaID")
+		if !exists || c.Param("empresaID") != strconv.FormatUint(uint64(empresaID.(uint)), 10) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Acceso denegado: la Empresa no corresponde al usuario"})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// PrefabricadaEmpresaMiddleware verifica que la Prefabricada de la ruta pertenezca a la Empresa de la ruta
+func PrefabricadaEmpresaMiddleware() gin.HandlerFunc {
+	return verificarRecursoPadre("prefabricadas", "prefabricadaID", "empresa_id", "empresaID", "Prefabricada no encontrada", false)
+}
+
+// PrefabricadaPublicadaEmpresaMiddleware verifica además que la Prefabricada esté publicada (rutas públicas)
+func PrefabricadaPublicadaEmpresaMiddleware() gin.HandlerFunc {
+	return verificarRecursoPadre("prefabricadas", "prefabricadaID", "empresa_id", "empresaID", "Prefabricada no encontrada", true)
+}
+
+// PrecioPrefabricadaMiddleware verifica que el Precio de la ruta pertenezca a la Prefabricada de la ruta
+func PrecioPrefabricadaMiddleware() gin.HandlerFunc {
+	return verificarRecursoPadre("precios", "precioID", "prefabricada_id", "prefabricadaID", "Precio no encontrado", false)
+}
+
+// NoticiaEmpresaMiddleware verifica que la Noticia de la ruta pertenezca a la Empresa de la ruta
+func NoticiaEmpresaMiddleware() gin.HandlerFunc {
+	return verificarRecursoPadre("noticias", "noticiaID", "empresa_id", "empresaID", "Noticia no encontrada", false)
+}
+
+// NoticiaPublicadaEmpresaMiddleware verifica además que la Noticia esté publicada (rutas públicas)
+func NoticiaPublicadaEmpresaMiddleware() gin.HandlerFunc {
+	return verificarRecursoPadre("noticias", "noticiaID", "empresa_id", "empresaID", "Noticia no encontrada", true)
+}
+
+// UsuarioEmpresaMiddleware verifica que el Usuario de la ruta pertenezca a la Empresa de la ruta
+func UsuarioEmpresaMiddleware() gin.HandlerFunc {
+	return verificarRecursoPadre("usuarios", "usuarioID", "empresa_id", "empresaID", "Usuario no encontrado", false)
+}
+
+// verificarRecursoPadre comprueba que el registro identificado por paramID exista, no esté eliminado
+// y que su columna padre coincida con el parámetro paramPadre de la ruta.
+// Con soloPublicado también exige que el registro sea visible públicamente según su estado de publicación.
+func verificarRecursoPadre(tabla, paramID, columnaPadre, paramPadre, mensaje string, soloPublicado bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := strconv.ParseUint(c.Param(paramID), 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido: " + paramID})
+			c.Abort()
+			return
+		}
+
+		padreID, err := strconv.ParseUint(c.Param(paramPadre), 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido: " + paramPadre})
+			c.Abort()
+			return
+		}
+
+		query := configs.DB.Table(tabla).
+			Where("id = ? AND "+columnaPadre+" = ? AND deleted_at IS NULL", id, padreID)
+		if soloPublicado {
+			query = services.FiltrarPublicados(query, tabla)
+		}
+
+		var count int64
+		if err := query.Count(&count).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al verificar el recurso"})
+			c.Abort()
+			return
+		}
+
+		if count == 0 {
+			c.JSON(http.StatusNotFound, gin.H{"error": mensaje})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
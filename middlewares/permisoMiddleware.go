@@ -0,0 +1,67 @@
+package middlewares
+
+import (
+	"net/http"
+	"strconv"
+	"v1_prefabricadas/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequirePermission permite el acceso si alguno de los roles asignados al usuario tiene el permiso indicado.
+// Un permiso con sufijo ":own" (ej. "contactos:write:own") solo autoriza rutas cuyo :usuarioID sea el del propio usuario.
+// El super_administrador tiene todos los permisos.
+func RequirePermission(permiso string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		roles, ok := c.Get("roles")
+		if !ok {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Rol no encontrado en el contexto"})
+			c.Abort()
+			return
+		}
+
+		rolesList, ok := roles.([]string)
+		if !ok {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Formato de roles inválido"})
+			c.Abort()
+			return
+		}
+
+		for _, rol := range rolesList {
+			if rol == services.RolSuperAdministrador {
+				c.Next()
+				return
+			}
+		}
+
+		// Los permisos se cargan una sola vez por request
+		permisos, ok := c.Get("permisos")
+		if !ok {
+			cargados, err := services.ObtenerPermisosUsuario(c.GetUint("usuarioID"))
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "No se pudo obtener los permisos"})
+				c.Abort()
+				return
+			}
+			c.Set("permisos", cargados)
+			permisos = cargados
+		}
+		permisosUsuario := permisos.(map[string]bool)
+
+		if permisosUsuario[permiso] {
+			c.Next()
+			return
+		}
+
+		// Permiso limitado a los recursos del propio usuario
+		if permisosUsuario[permiso+services.SufijoPermisoPropio] {
+			if c.Param("usuarioID") == strconv.FormatUint(uint64(c.GetUint("usuarioID")), 10) {
+				c.Next()
+				return
+			}
+		}
+
+		c.JSON(http.StatusForbidden, gin.H{"error": "Acceso denegado: se requiere el permiso " + permiso})
+		c.Abort()
+	}
+}
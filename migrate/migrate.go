@@ -2,8 +2,12 @@ package main
 
 import (
 	"log"
+	"strings"
 	"v1_prefabricadas/configs"
 	"v1_prefabricadas/models"
+	"v1_prefabricadas/services"
+
+	"gorm.io/gorm"
 )
 
 func init() {
@@ -13,6 +17,10 @@ func init() {
 func main() {
 	log.Println("Iniciando migraciones...")
 
+	// Las credenciales creadas antes de la verificación de email se consideran verificadas
+	verificarCredencialesExistentes := configs.DB.Migrator().HasTable(&models.Credencial{}) &&
+		!configs.DB.Migrator().HasColumn(&models.Credencial{}, "EmailVerifiedAt")
+
 	err := configs.DB.AutoMigrate(
 		&models.Caracteristica{},
 		&models.Categoria{},
@@ -35,11 +43,49 @@ func main() {
 		&models.Tipo_categoria{},
 		&models.Usuario{},
 		&models.Recuperacion{},
+		&models.Sesion{},
+		&models.BloqueoAcceso{},
+		&models.Permiso{},
+		&models.Codigo_recuperacion{},
+		&models.Configuracion{},
+		&models.Auditoria{},
+		&models.Historial_password{},
+		&models.Invitacion{},
+		&models.Api_key{},
+		&models.Configuracion_oidc{},
+		&models.Slug_historico{},
+		&models.Papelera{},
+		&models.Importacion{},
+		&models.Lead{},
 	)
 	if err != nil {
 		log.Fatalf("Error durante la migración: %v", err)
 	}
 
+	// La búsqueda de texto completo ignora tildes solo si las columnas indexadas lo hacen
+	if err := asegurarIntercalacionBusqueda(); err != nil {
+		log.Fatalf("Error al configurar la intercalación de las tablas de búsqueda: %v", err)
+	}
+
+	// Las prefabricadas, noticias y categorías creadas antes de los slugs reciben uno desde su nombre o título
+	for _, entidad := range []string{services.EntidadSlugPrefabricada, services.EntidadSlugNoticia, services.EntidadSlugCategoria} {
+		asignados, err := services.AsignarSlugsFaltantes(entidad)
+		if err != nil {
+			log.Fatalf("Error al asignar los slugs de %s: %v", entidad, err)
+		}
+		if asignados > 0 {
+			log.Printf("Slugs asignados a %d registros de %s", asignados, entidad)
+		}
+	}
+
+	if verificarCredencialesExistentes {
+		if err := configs.DB.Model(&models.Credencial{}).
+			Where("email_verified_at IS NULL").
+			Update("email_verified_at", gorm.Expr("created_at")).Error; err != nil {
+			log.Fatalf("Error al marcar las credenciales existentes como verificadas: %v", err)
+		}
+	}
+
 	log.Println("Migraciones completadas exitosamente")
 
 	// Seed initial data
@@ -80,6 +126,8 @@ func seedInitialData() {
 
 	log.Println("Roles iniciales verificados y creados correctamente")
 
+	seedPermisos()
+
 	// Verificar si la empresa con ID 1 ya está creada
 	var empresa models.Empresa
 	if err := configs.DB.First(&empresa, 1).Error; err != nil {
@@ -146,3 +194,94 @@ func seedInitialData() {
 
 	log.Println("Datos iniciales creados/verificados exitosamente")
 }
+
+// Tablas con índices FULLTEXT usados por la búsqueda de prefabricadas
+var tablasBusqueda = []string{"prefabricadas", "caracteristicas", "estilos", "tipos"}
+
+// Convertir a utf8mb4_unicode_ci las tablas de búsqueda que tengan columnas de texto sensibles a tildes
+// (intercalaciones binarias, "_cs" o "_as_ci"), para que "mediterranea" encuentre "mediterránea"
+func asegurarIntercalacionBusqueda() error {
+	for _, tabla := range tablasBusqueda {
+		var intercalaciones []string
+		if err := configs.DB.Raw(`
+			SELECT DISTINCT COLLATION_NAME FROM information_schema.COLUMNS
+			WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = ? AND COLLATION_NAME IS NOT NULL`, tabla).
+			Scan(&intercalaciones).Error; err != nil {
+			return err
+		}
+
+		sensible := false
+		for _, intercalacion := range intercalaciones {
+			if !strings.HasSuffix(intercalacion, "_ci") || strings.Contains(intercalacion, "_as_") {
+				sensible = true
+			}
+		}
+		if !sensible {
+			continue
+		}
+
+		log.Printf("Convirtiendo la tabla %s a utf8mb4_unicode_ci para la búsqueda sin tildes", tabla)
+		if err := configs.DB.Exec("ALTER TABLE " + tabla + " CONVERT TO CHARACTER SET utf8mb4 COLLATE utf8mb4_unicode_ci").Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Permisos del sistema y roles a los que se asignan inicialmente
+var permisosIniciales = []struct {
+	nombre      string
+	descripcion string
+	roles       []uint
+}{
+	{"catalogo:read", "Ver tipos, categorías, estilos, empresas y prefabricadas", []uint{1, 2, 3}},
+	{"taxonomias:write", "Gestionar tipos, categorías y estilos (compartidos por todas las empresas)", []uint{1}},
+	{"prefabricadas:write", "Gestionar prefabricadas, imágenes, características, precios e incluyes", []uint{1, 2}},
+	{"empresas:write", "Gestionar empresas, servicios, redes y portadas", []uint{1, 2}},
+	{"noticias:read", "Ver noticias de la empresa", []uint{1, 2, 3}},
+	{"noticias:write", "Gestionar noticias e imágenes de noticias", []uint{1, 2}},
+	{"usuarios:read", "Ver usuarios de la empresa", []uint{1, 2}},
+	{"usuarios:read:own", "Ver el propio usuario", []uint{3}},
+	{"usuarios:write", "Gestionar usuarios de la empresa", []uint{1, 2}},
+	{"contactos:write", "Gestionar contactos de cualquier usuario", []uint{1, 2}},
+	{"contactos:write:own", "Gestionar los contactos propios", []uint{3}},
+	{"credenciales:write", "Gestionar credenciales de acceso", []uint{1, 2}},
+	{"roles:read", "Ver roles, permisos y asignaciones", []uint{1, 2}},
+	{"roles:write", "Gestionar roles y sus permisos (compartidos por todas las empresas)", []uint{1}},
+	{"roles_usuarios:write", "Asignar y quitar roles a los usuarios de la empresa", []uint{1, 2}},
+	{"auditoria:read", "Ver el historial de cambios de la empresa", []uint{1, 2}},
+	{"leads:read", "Ver los leads recibidos por la empresa", []uint{1, 2, 3}},
+	{"leads:write", "Registrar leads de la empresa", []uint{1, 2}},
+	{"api_keys:write", "Gestionar las API keys de integración de la empresa", []uint{1, 2}},
+	{"papelera:read", "Ver los elementos eliminados de la empresa", []uint{1, 2}},
+	{"papelera:write", "Restaurar los elementos eliminados de la empresa", []uint{1, 2}},
+}
+
+func seedPermisos() {
+	for _, p := range permisosIniciales {
+		var permiso models.Permiso
+		if err := configs.DB.Where("nombre_permiso = ?", p.nombre).First(&permiso).Error; err == nil {
+			// El permiso ya existe, no se modifican las asignaciones hechas por los administradores
+			continue
+		}
+
+		permiso = models.Permiso{
+			NombrePermiso:      p.nombre,
+			DescripcionPermiso: p.descripcion,
+		}
+		if result := configs.DB.Create(&permiso); result.Error != nil {
+			log.Fatalf("Error al crear el permiso %s: %v", p.nombre, result.Error)
+		}
+
+		var roles []models.Rol
+		if err := configs.DB.Where("id IN ?", p.roles).Find(&roles).Error; err != nil {
+			log.Fatalf("Error al obtener los roles del permiso %s: %v", p.nombre, err)
+		}
+		if err := configs.DB.Model(&permiso).Association("Rol").Append(roles); err != nil {
+			log.Fatalf("Error al asignar el permiso %s: %v", p.nombre, err)
+		}
+	}
+
+	log.Println("Permisos iniciales verificados y creados correctamente")
+}
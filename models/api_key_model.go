@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+type Api_key struct {
+	ID          uint       `gorm:"primaryKey;autoIncrement;column:id" json:"id"`
+	CreatedAt   time.Time  `gorm:"column:created_at" json:"created_at"`
+	UpdatedAt   time.Time  `gorm:"column:updated_at" json:"updated_at"`
+	Nombre      string     `gorm:"size:100;not null;column:nombre" json:"nombre"`    // Integración que usa la llave (ej. "Build sitio web")
+	Prefijo     string     `gorm:"size:16;not null;column:prefijo" json:"prefijo"`   // Inicio de la llave, para reconocerla sin mostrarla completa
+	KeyHash     string     `gorm:"unique;not null;size:64;column:key_hash" json:"-"` // SHA-256 de la llave, nunca la llave en claro
+	Scopes      string     `gorm:"not null;column:scopes" json:"scopes"`             // Scopes separados por coma
+	ExpiresAt   *time.Time `gorm:"column:expires_at" json:"expires_at,omitempty"`    // Opcional, sin fecha no expira
+	LastUsedAt  *time.Time `gorm:"column:last_used_at" json:"last_used_at,omitempty"`
+	RevokedAt   *time.Time `gorm:"column:revoked_at" json:"revoked_at,omitempty"`
+	CreadoPorID uint       `gorm:"column:creado_por_id" json:"creado_por_id"` // Usuario que generó la llave
+	EmpresaID   uint       `gorm:"not null;index;column:empresa_id" json:"empresa_id"`
+	Empresa     Empresa    `gorm:"foreignKey:EmpresaID"`
+}
+
+func (Api_key) TableName() string {
+	return "api_keys"
+}
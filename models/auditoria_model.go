@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+type Auditoria struct {
+	ID        uint      `gorm:"primaryKey;autoIncrement;column:id" json:"id"`
+	CreatedAt time.Time `gorm:"index;column:created_at" json:"created_at"`
+	UsuarioID uint      `gorm:"index;column:usuario_id" json:"usuario_id"` // Usuario que realizó el cambio
+	ApiKeyID  *uint     `gorm:"index;column:api_key_id" json:"api_key_id"` // API key que realizó el cambio, si no fue un usuario
+	EmpresaID *uint     `gorm:"index;column:empresa_id" json:"empresa_id"`
+	Entidad   string    `gorm:"size:100;index:idx_auditoria_entidad;column:entidad" json:"entidad"` // Nombre de la tabla
+	EntidadID uint      `gorm:"index:idx_auditoria_entidad;column:entidad_id" json:"entidad_id"`
+	Accion    string    `gorm:"size:20;index;column:accion" json:"accion"` // create | update | delete | restore
+	Antes     *string   `gorm:"type:json;column:antes" json:"antes"`       // Valores anteriores de los campos modificados
+	Despues   *string   `gorm:"type:json;column:despues" json:"despues"`   // Valores nuevos de los campos modificados
+	Ruta      string    `gorm:"column:ruta" json:"ruta"`
+}
+
+func (Auditoria) TableName() string {
+	return "auditorias"
+}
@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+type BloqueoAcceso struct {
+	ID               uint       `gorm:"primaryKey;autoIncrement;column:id" json:"id"`
+	CreatedAt        time.Time  `gorm:"column:created_at" json:"created_at"`
+	UpdatedAt        time.Time  `gorm:"column:updated_at" json:"updated_at"`
+	Accion           string     `gorm:"size:30;not null;uniqueIndex:idx_bloqueo_clave;column:accion" json:"accion"` // login | recuperacion | 2fa
+	Tipo             string     `gorm:"size:10;not null;uniqueIndex:idx_bloqueo_clave;column:tipo" json:"tipo"`     // email | ip
+	Valor            string     `gorm:"size:255;not null;uniqueIndex:idx_bloqueo_clave;column:valor" json:"valor"`
+	IntentosFallidos int        `gorm:"not null;default:0;column:intentos_fallidos" json:"intentos_fallidos"`
+	UltimoIntentoAt  time.Time  `gorm:"column:ultimo_intento_at" json:"ultimo_intento_at"`
+	BloqueadoHasta   *time.Time `gorm:"column:bloqueado_hasta" json:"bloqueado_hasta,omitempty"`
+}
+
+func (BloqueoAcceso) TableName() string {
+	return "bloqueos_acceso"
+}
@@ -7,8 +7,8 @@ type Caracteristica struct {
 	CreatedAt      time.Time    `gorm:"column:created_at" json:"created_at"`
 	UpdatedAt      time.Time    `gorm:"column:updated_at" json:"updated_at"`
 	DeletedAt      *time.Time   `gorm:"column:deleted_at" json:"deleted_at,omitempty"`
-	Clave          string       `gorm:"column:clave" json:"clave"`
-	Valor          string       `gorm:"column:valor" json:"valor"`
+	Clave          string       `gorm:"column:clave;index:idx_ft_caracteristica,class:FULLTEXT" json:"clave"`
+	Valor          string       `gorm:"column:valor;index:idx_ft_caracteristica,class:FULLTEXT" json:"valor"`
 	PrefabricadaID uint         `gorm:"column:prefabricada_id" json:"prefabricada_id"`
 	Prefabricada   Prefabricada `gorm:"foreignKey:PrefabricadaID"`
 }
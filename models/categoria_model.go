@@ -7,6 +7,7 @@ type Categoria struct {
 	CreatedAt            time.Time        `gorm:"column:created_at" json:"created_at"`
 	UpdatedAt            time.Time        `gorm:"column:updated_at" json:"updated_at"`
 	DeletedAt            *time.Time       `gorm:"column:deleted_at" json:"deleted_at,omitempty"`
+	Slug                 *string          `gorm:"column:slug;size:191;uniqueIndex:idx_categoria_slug" json:"slug"`
 	NombreCategoria      string           `gorm:"column:nombre_categoria" json:"nombre_categoria"`
 	DescripcionCategoria string           `gorm:"column:descripcion_categoria" json:"descripcion_categoria"`
 	Tipo_categoria       []Tipo_categoria `gorm:"foreignKey:CategoriaID;contraint:OnDelete:CASCADE"`
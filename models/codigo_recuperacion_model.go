@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+type Codigo_recuperacion struct {
+	ID           uint       `gorm:"primaryKey;autoIncrement;column:id" json:"id"`
+	CreatedAt    time.Time  `gorm:"column:created_at" json:"created_at"`
+	CodigoHash   string     `gorm:"size:64;not null;column:codigo_hash" json:"-"`
+	UsadoAt      *time.Time `gorm:"column:usado_at" json:"usado_at,omitempty"`
+	CredencialID uint       `gorm:"not null;index;column:credencial_id" json:"credencial_id"`
+}
+
+func (Codigo_recuperacion) TableName() string {
+	return "codigos_recuperacion"
+}
@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+type Configuracion struct {
+	ID        uint      `gorm:"primaryKey;autoIncrement;column:id" json:"id"`
+	CreatedAt time.Time `gorm:"column:created_at" json:"created_at"`
+	UpdatedAt time.Time `gorm:"column:updated_at" json:"updated_at"`
+	Clave     string    `gorm:"unique;size:100;not null;column:clave" json:"clave"`
+	Valor     string    `gorm:"column:valor" json:"valor"`
+}
+
+func (Configuracion) TableName() string {
+	return "configuraciones"
+}
@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// Configuración del inicio de sesión con OpenID Connect (Google Workspace) de una Empresa
+type Configuracion_oidc struct {
+	ID                    uint      `gorm:"primaryKey;autoIncrement;column:id" json:"id"`
+	CreatedAt             time.Time `gorm:"column:created_at" json:"created_at"`
+	UpdatedAt             time.Time `gorm:"column:updated_at" json:"updated_at"`
+	Habilitado            bool      `gorm:"not null;default:false;column:habilitado" json:"habilitado"`
+	DominiosPermitidos    string    `gorm:"column:dominios_permitidos" json:"dominios_permitidos"`                              // Dominios separados por coma (ej. "casasemilia.cl")
+	VinculacionAutomatica bool      `gorm:"not null;default:false;column:vinculacion_automatica" json:"vinculacion_automatica"` // Vincular la cuenta externa en el primer login por email
+	EmpresaID             uint      `gorm:"not null;uniqueIndex;column:empresa_id" json:"empresa_id"`
+	Empresa               Empresa   `gorm:"foreignKey:EmpresaID"`
+}
+
+func (Configuracion_oidc) TableName() string {
+	return "configuraciones_oidc"
+}
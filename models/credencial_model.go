@@ -8,9 +8,20 @@ type Credencial struct {
 	UpdatedAt time.Time  `gorm:"column:updated_at" json:"updated_at"`
 	DeletedAt *time.Time `gorm:"column:deleted_at" json:"deleted_at,omitempty"`
 	Email     string     `gorm:"unique;not null;column:email" json:"email"`
-	Password  string     `gorm:"not null" json:"password"`
+	Password  string     `gorm:"not null" json:"password"` // Vacío mientras la invitación no sea aceptada
 	UsuarioID uint       `gorm:"unique;not null;column:usuario_id" json:"usuario_id"`
 	Usuario   *Usuario   `gorm:"foreignKey:UsuarioID"`
+	// Identidad externa vinculada (OpenID Connect)
+	OIDCIssuer  *string `gorm:"size:191;uniqueIndex:idx_credencial_oidc;column:oidc_issuer" json:"-"`
+	OIDCSubject *string `gorm:"size:191;uniqueIndex:idx_credencial_oidc;column:oidc_subject" json:"-"`
+	// Confirmación del email al aceptar la invitación
+	EmailVerifiedAt *time.Time   `gorm:"column:email_verified_at" json:"email_verified_at,omitempty"`
+	Invitacion      []Invitacion `gorm:"foreignKey:CredencialID;constraint:OnDelete:CASCADE" json:"-"`
+	// Autenticación de dos factores (TOTP)
+	TOTPSecreto         string                `gorm:"size:64;column:totp_secreto" json:"-"`
+	TOTPHabilitadoAt    *time.Time            `gorm:"column:totp_habilitado_at" json:"totp_habilitado_at,omitempty"`
+	TOTPUltimoPaso      int64                 `gorm:"column:totp_ultimo_paso;default:0" json:"-"` // Evita reutilizar un mismo código
+	Codigo_recuperacion []Codigo_recuperacion `gorm:"foreignKey:CredencialID;constraint:OnDelete:CASCADE" json:"-"`
 }
 
 func (Credencial) TableName() string {
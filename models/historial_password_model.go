@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+type Historial_password struct {
+	ID           uint      `gorm:"primaryKey;autoIncrement;column:id" json:"id"`
+	CreatedAt    time.Time `gorm:"column:created_at" json:"created_at"`
+	PasswordHash string    `gorm:"not null;column:password_hash" json:"-"` // Hash bcrypt de una contraseña usada
+	CredencialID uint      `gorm:"not null;index;column:credencial_id" json:"credencial_id"`
+}
+
+func (Historial_password) TableName() string {
+	return "historial_passwords"
+}
@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+// Historial de importaciones del catálogo desde hojas de cálculo (simuladas o aplicadas)
+type Importacion struct {
+	ID           uint      `gorm:"primaryKey;autoIncrement;column:id" json:"id"`
+	CreatedAt    time.Time `gorm:"index;column:created_at" json:"created_at"`
+	EmpresaID    uint      `gorm:"not null;index;column:empresa_id" json:"empresa_id"`
+	UsuarioID    *uint     `gorm:"index;column:usuario_id" json:"usuario_id"` // Nulo si la importación se hizo con una API key
+	ApiKeyID     *uint     `gorm:"index;column:api_key_id" json:"api_key_id"`
+	Archivo      string    `gorm:"column:archivo" json:"archivo"`
+	Formato      string    `gorm:"size:10;column:formato" json:"formato"` // csv | xlsx
+	DryRun       bool      `gorm:"column:dry_run" json:"dry_run"`
+	Estado       string    `gorm:"size:20;index;column:estado" json:"estado"` // simulada | aplicada | rechazada
+	Filas        int       `gorm:"column:filas" json:"filas"`
+	Creadas      int       `gorm:"column:creadas" json:"creadas"`
+	Actualizadas int       `gorm:"column:actualizadas" json:"actualizadas"`
+	Omitidas     int       `gorm:"column:omitidas" json:"omitidas"`
+	Errores      int       `gorm:"column:errores" json:"errores"`
+	Resultado    *string   `gorm:"type:json;column:resultado" json:"resultado"` // Cambios y errores por fila
+}
+
+func (Importacion) TableName() string {
+	return "importaciones"
+}
@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+type Invitacion struct {
+	ID           uint       `gorm:"primaryKey;autoIncrement;column:id" json:"id"`
+	CreatedAt    time.Time  `gorm:"column:created_at" json:"created_at"`
+	TokenHash    string     `gorm:"unique;not null;size:64;column:token_hash" json:"-"` // SHA-256 del token enviado por email
+	ExpiresAt    time.Time  `gorm:"not null;column:expires_at" json:"expires_at"`
+	InvalidadoAt *time.Time `gorm:"column:invalidado_at" json:"invalidado_at,omitempty"` // Se marca al aceptarse o al reenviarse la invitación
+	EmailNuevo   string     `gorm:"column:email_nuevo" json:"email_nuevo,omitempty"`     // Solo en cambios de email: dirección a confirmar
+	CredencialID uint       `gorm:"not null;index;column:credencial_id" json:"credencial_id"`
+}
+
+func (Invitacion) TableName() string {
+	return "invitaciones"
+}
@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+// Lead es una solicitud de contacto de un interesado, recibida desde el sitio web o un portal de partners
+type Lead struct {
+	ID             uint       `gorm:"primaryKey;autoIncrement;column:id" json:"id"`
+	CreatedAt      time.Time  `gorm:"column:created_at" json:"created_at"`
+	UpdatedAt      time.Time  `gorm:"column:updated_at" json:"updated_at"`
+	DeletedAt      *time.Time `gorm:"column:deleted_at" json:"deleted_at,omitempty"`
+	Nombre         string     `gorm:"size:150;not null;column:nombre" json:"nombre"`
+	Email          string     `gorm:"size:150;column:email" json:"email"`
+	Telefono       string     `gorm:"size:30;column:telefono" json:"telefono"`
+	Mensaje        string     `gorm:"type:text;column:mensaje" json:"mensaje"`
+	Origen         string     `gorm:"size:100;column:origen" json:"origen"`                          // Sitio o integración que envió el lead (ej. "sitio web")
+	PrefabricadaID *uint      `gorm:"index;column:prefabricada_id" json:"prefabricada_id,omitempty"` // Sin clave foránea: el lead se conserva si la prefabricada se purga
+	ApiKeyID       *uint      `gorm:"column:api_key_id" json:"api_key_id,omitempty"`                 // API key con la que se registró, si corresponde
+	EmpresaID      uint       `gorm:"not null;index;column:empresa_id" json:"empresa_id"`
+	Empresa        Empresa    `gorm:"foreignKey:EmpresaID"`
+}
+
+func (Lead) TableName() string {
+	return "leads"
+}
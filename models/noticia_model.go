@@ -7,10 +7,14 @@ type Noticia struct {
 	CreatedAt         time.Time  `gorm:"column:created_at" json:"created_at"`
 	UpdatedAt         time.Time  `gorm:"column:updated_at" json:"updated_at"`
 	DeletedAt         *time.Time `gorm:"column:deleted_at" json:"deleted_at,omitempty"`
+	Slug              *string    `gorm:"column:slug;size:191;uniqueIndex:idx_noticia_empresa_slug,priority:2" json:"slug"`
 	TituloNoticia     string     `gorm:"column:titulo_noticia" json:"titulo_noticia"`
 	DesarrolloNoticia string     `gorm:"column:desarrollo_noticia" json:"desarrollo_noticia"`
+	Estado            string     `gorm:"column:estado;size:20;not null;default:published;index" json:"estado"` // draft, scheduled, published o archived
+	PublishAt         *time.Time `gorm:"column:publish_at" json:"publish_at"`
+	UnpublishAt       *time.Time `gorm:"column:unpublish_at" json:"unpublish_at"`
 	// UsuarioID         uint             `gorm:"column:usuario_id" json:"usuario_id"`
-	EmpresaID uint `gorm:"column:empresa_id" json:"empresa_id"`
+	EmpresaID uint `gorm:"column:empresa_id;uniqueIndex:idx_noticia_empresa_slug,priority:1" json:"empresa_id"`
 	// Usuario           Usuario          `gorm:"foreignKey:UsuarioID"`
 	Empresa        Empresa          `gorm:"foreignKey:EmpresaID"`
 	Imagen_noticia []Imagen_noticia `gorm:"foreignKey:NoticiaID;constraint:OnDelete:CASCADE"`
@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// Elemento eliminado lógicamente desde el panel. Sus descendientes se eliminan en cascada con la misma
+// fecha (EliminadoAt), lo que permite restaurar el subárbol completo sin revivir lo eliminado antes por separado.
+type Papelera struct {
+	ID          uint      `gorm:"primaryKey;autoIncrement;column:id" json:"id"`
+	CreatedAt   time.Time `gorm:"column:created_at" json:"created_at"`
+	EmpresaID   uint      `gorm:"not null;index;column:empresa_id" json:"empresa_id"`
+	Entidad     string    `gorm:"size:100;not null;index:idx_papelera_entidad;column:entidad" json:"entidad"` // Nombre de la tabla
+	EntidadID   uint      `gorm:"not null;index:idx_papelera_entidad;column:entidad_id" json:"entidad_id"`
+	Nombre      string    `gorm:"column:nombre" json:"nombre"`                            // Nombre o título del registro al eliminarlo
+	UsuarioID   *uint     `gorm:"index;column:usuario_id" json:"usuario_id"`              // Usuario que eliminó (nulo si fue una API key)
+	EliminadoAt time.Time `gorm:"index;not null;column:eliminado_at" json:"eliminado_at"` // Igual al deleted_at del registro y sus descendientes
+}
+
+func (Papelera) TableName() string {
+	return "papelera"
+}
@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+type Permiso struct {
+	ID                 uint       `gorm:"primaryKey;autoIncrement;column:id" json:"id"`
+	CreatedAt          time.Time  `gorm:"column:created_at" json:"created_at"`
+	UpdatedAt          time.Time  `gorm:"column:updated_at" json:"updated_at"`
+	DeletedAt          *time.Time `gorm:"column:deleted_at" json:"deleted_at,omitempty"`
+	NombrePermiso      string     `gorm:"unique;size:100;not null;column:nombre_permiso" json:"nombre_permiso"` // Formato recurso:accion[:own]
+	DescripcionPermiso string     `gorm:"column:descripcion_permiso" json:"descripcion_permiso"`
+	Rol                []Rol      `gorm:"many2many:roles_permisos;"`
+}
+
+func (Permiso) TableName() string {
+	return "permisos"
+}
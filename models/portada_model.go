@@ -9,6 +9,9 @@ type Portada struct {
 	DeletedAt     *time.Time `gorm:"column:deleted_at" json:"deleted_at,omitempty"`
 	NombrePortada string     `gorm:"column:nombre_portada" json:"nombre_portada"`
 	Image         string     `gorm:"column:image" json:"image"`
+	Estado        string     `gorm:"column:estado;size:20;not null;default:published;index" json:"estado"` // draft, scheduled, published o archived
+	PublishAt     *time.Time `gorm:"column:publish_at" json:"publish_at"`
+	UnpublishAt   *time.Time `gorm:"column:unpublish_at" json:"unpublish_at"`
 	EmpresaID     uint       `gorm:"column:empresa_id" json:"empresa_id"`
 	Empresa       Empresa    `gorm:"foreignKey:EmpresaID"`
 }
@@ -7,15 +7,19 @@ type Prefabricada struct {
 	CreatedAt           time.Time             `gorm:"column:created_at" json:"created_at"`
 	UpdatedAt           time.Time             `gorm:"column:updated_at" json:"updated_at"`
 	DeletedAt           *time.Time            `gorm:"column:deleted_at" json:"deleted_at,omitempty"`
-	NombrePrefabricada  string                `gorm:"column:nombre_prefabricada" json:"nombre_prefabricada"`
+	Slug                *string               `gorm:"column:slug;size:191;uniqueIndex:idx_prefabricada_empresa_slug,priority:2" json:"slug"`
+	NombrePrefabricada  string                `gorm:"column:nombre_prefabricada;index:idx_ft_prefabricada,class:FULLTEXT;index:idx_ft_prefabricada_nombre,class:FULLTEXT" json:"nombre_prefabricada"`
 	M2                  int                   `gorm:"column:m2" json:"m2"`
 	Garantia            string                `gorm:"column:garantia" json:"garantia"`
-	Eslogan             string                `gorm:"column:eslogan" json:"eslogan"`
-	Descripcion         string                `gorm:"column:descripcion" json:"descripcion"`
+	Eslogan             string                `gorm:"column:eslogan;index:idx_ft_prefabricada,class:FULLTEXT" json:"eslogan"`
+	Descripcion         string                `gorm:"column:descripcion;index:idx_ft_prefabricada,class:FULLTEXT" json:"descripcion"`
 	Destacada           bool                  `gorm:"column:destacada;default:false" json:"destacada"`
 	Oferta              bool                  `gorm:"column:oferta;default:false" json:"oferta"`
+	Estado              string                `gorm:"column:estado;size:20;not null;default:published;index" json:"estado"` // draft, scheduled, published o archived
+	PublishAt           *time.Time            `gorm:"column:publish_at" json:"publish_at"`
+	UnpublishAt         *time.Time            `gorm:"column:unpublish_at" json:"unpublish_at"`
 	CategoriaID         uint                  `gorm:"column:categoria_id" json:"categoria_id"`
-	EmpresaID           uint                  `gorm:"column:empresa_id" json:"empresa_id"`
+	EmpresaID           uint                  `gorm:"column:empresa_id;uniqueIndex:idx_prefabricada_empresa_slug,priority:1" json:"empresa_id"`
 	EstiloID            uint                  `gorm:"column:estilo_id" json:"estilo_id"`
 	TipoID              uint                  `gorm:"column:tipo_id" json:"tipo_id"`
 	Categoria           Categoria             `gorm:"foreignKey:CategoriaID"`
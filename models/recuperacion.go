@@ -3,9 +3,10 @@ package models
 import "time"
 
 type Recuperacion struct {
-	ID        uint      `gorm:"primaryKey"`
-	Token     string    `gorm:"unique;not null"`
-	UsuarioID uint      `gorm:"not null"`
-	ExpiresAt time.Time `gorm:"not null"`
-	CreatedAt time.Time
+	ID           uint       `gorm:"primaryKey"`
+	TokenHash    string     `gorm:"unique;not null;size:64;column:token"` // SHA-256 del token enviado por email, nunca el token en claro
+	UsuarioID    uint       `gorm:"not null;index"`
+	ExpiresAt    time.Time  `gorm:"not null"`
+	InvalidadoAt *time.Time `gorm:"column:invalidado_at"` // Se marca al usarse o al emitirse un token más nuevo
+	CreatedAt    time.Time
 }
@@ -10,6 +10,7 @@ type Rol struct {
 	NombreRol      string        `gorm:"column:nombre_rol" json:"nombre_rol"`
 	DescripcionRol string        `gorm:"column:descripcion_rol" json:"descripcion_rol"`
 	Rol_usuario    []Rol_usuario `gorm:"foreignKey:RolID;constraint:OnDelete:CASCADE"` // Eliminar Rol_usuario si se elimina el rol
+	Permiso        []Permiso     `gorm:"many2many:roles_permisos;"`                    // Permisos otorgados al rol
 }
 
 func (Rol) TableName() string {
@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+type Sesion struct {
+	ID                       uint       `gorm:"primaryKey;autoIncrement;column:id" json:"id"`
+	CreatedAt                time.Time  `gorm:"column:created_at" json:"created_at"`
+	UpdatedAt                time.Time  `gorm:"column:updated_at" json:"updated_at"`
+	RefreshTokenHash         string     `gorm:"unique;not null;size:64;column:refresh_token_hash" json:"-"`
+	RefreshTokenAnteriorHash string     `gorm:"size:64;index;column:refresh_token_anterior_hash" json:"-"` // Permite detectar la reutilización de un token ya rotado
+	UserAgent                string     `gorm:"column:user_agent" json:"user_agent"`
+	IP                       string     `gorm:"size:45;column:ip" json:"ip"`
+	LastSeenAt               *time.Time `gorm:"column:last_seen_at" json:"last_seen_at"` // Último uso de la sesión (access o refresh token)
+	ExpiresAt                time.Time  `gorm:"not null;column:expires_at" json:"expires_at"`
+	RevokedAt                *time.Time `gorm:"column:revoked_at" json:"revoked_at,omitempty"`
+	UsuarioID                uint       `gorm:"not null;index;column:usuario_id" json:"usuario_id"`
+	Usuario                  Usuario    `gorm:"foreignKey:UsuarioID"`
+}
+
+func (Sesion) TableName() string {
+	return "sesiones"
+}
@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// Slug anterior de una prefabricada, noticia o categoría, para redirigir las URLs antiguas al slug vigente
+type Slug_historico struct {
+	ID        uint      `gorm:"primaryKey;autoIncrement;column:id" json:"id"`
+	CreatedAt time.Time `gorm:"column:created_at" json:"created_at"`
+	Entidad   string    `gorm:"not null;size:32;uniqueIndex:idx_slug_historico,priority:1;column:entidad" json:"entidad"`         // Tabla de la entidad (prefabricadas, noticias o categorias)
+	EmpresaID uint      `gorm:"not null;default:0;uniqueIndex:idx_slug_historico,priority:2;column:empresa_id" json:"empresa_id"` // 0 para las categorías, que no pertenecen a una empresa
+	Slug      string    `gorm:"not null;size:191;uniqueIndex:idx_slug_historico,priority:3;column:slug" json:"slug"`
+	EntidadID uint      `gorm:"not null;index;column:entidad_id" json:"entidad_id"`
+}
+
+func (Slug_historico) TableName() string {
+	return "slugs_historicos"
+}
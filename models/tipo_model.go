@@ -7,7 +7,7 @@ type Tipo struct {
 	CreatedAt           time.Time        `gorm:"column:created_at" json:"created_at"`
 	UpdatedAt           time.Time        `gorm:"column:updated_at" json:"updated_at"`
 	DeletedAt           *time.Time       `gorm:"column:deleted_at" json:"deleted_at,omitempty"`
-	MaterialEstructura  string           `gorm:"column:material_estructura" json:"material_estructura"`
+	MaterialEstructura  string           `gorm:"column:material_estructura;index:idx_ft_tipo,class:FULLTEXT" json:"material_estructura"`
 	DescripcionMaterial string           `gorm:"column:descripcion_material" json:"descripcion_material"`
 	Tipo_categoria      []Tipo_categoria `gorm:"foreignKey:TipoID;constraint:OnDelete:CASCADE"`
 	Prefabricada        []Prefabricada   `gorm:"foreignKey:TipoID;constraint:OnDelete:CASCADE"`
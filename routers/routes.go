@@ -25,7 +25,7 @@ func SetupRouter() *gin.Engine {
 		}, // Dominios permitidos
 		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"}, // Métodos HTTP permitidos
 		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization"}, // Headers permitidos
-		ExposeHeaders:    []string{"Content-Length", "Authorization"},         // Headers expuestos al frontend
+		ExposeHeaders:    []string{"Content-Length", "Authorization", "Link"}, // Headers expuestos al frontend (Link: paginación)
 		AllowCredentials: true,                                                // Permitir cookies o credenciales
 		MaxAge:           24 * time.Hour,                                      // Tiempo de caché para preflight
 	}))
@@ -38,10 +38,59 @@ func SetupRouter() *gin.Engine {
 		c.Next()
 	}) */
 
+	// Verificación de pertenencia de los recursos anidados a su recurso padre
+	prefabricadaDeEmpresa := middlewares.PrefabricadaEmpresaMiddleware()
+	precioDePrefabricada := middlewares.PrecioPrefabricadaMiddleware()
+	noticiaDeEmpresa := middlewares.NoticiaEmpresaMiddleware()
+	prefabricadaPublicada := middlewares.PrefabricadaPublicadaEmpresaMiddleware() // Rutas públicas: solo prefabricadas publicadas
+	noticiaPublicada := middlewares.NoticiaPublicadaEmpresaMiddleware()           // Rutas públicas: solo noticias publicadas
+	usuarioDeEmpresa := middlewares.UsuarioEmpresaMiddleware()
+
+	// Llaves públicas para verificar los tokens (JSON Web Key Set)
+	router.GET("/.well-known/jwks.json", controllers.ObtenerJWKS)
+
 	// Ruta para el login y recuperador de password(email y password :json)
 	router.POST("/login", controllers.Login)
+	router.POST("/refresh", controllers.RefrescarToken)                                                           // Rotar refresh token y obtener un nuevo access token
+	router.POST("/logout", middlewares.AuthMiddleware(), middlewares.SoloUsuarioMiddleware(), controllers.Logout) // Revocar la sesión actual
+
+	// Segundo paso del login con autenticación de dos factores (token de desafío)
+	router.POST("/login/2fa", controllers.VerificarLogin2FA)       // Verificar código TOTP o de recuperación
+	router.POST("/login/2fa/enrolar", controllers.EnrolarLogin2FA) // Iniciar enrolamiento obligatorio
+	router.POST("/login/2fa/activar", controllers.ActivarLogin2FA) // Confirmar enrolamiento obligatorio
+
+	// Login con proveedor externo OpenID Connect (Google Workspace)
+	router.GET("/login/oidc", controllers.IniciarLoginOIDC) // Obtener URL de autorización y estado firmado
+	router.POST("/login/oidc", controllers.LoginOIDC)       // Completar el login con el código recibido del proveedor
+
+	// Gestión de la autenticación de dos factores del usuario autenticado
+	dosFactores := router.Group("/2fa", middlewares.AuthMiddleware(), middlewares.SoloUsuarioMiddleware())
+	{
+		dosFactores.POST("/enrolar", controllers.Enrolar2FA)                                // Generar secreto y URI de aprovisionamiento
+		dosFactores.POST("/activar", controllers.Activar2FA)                                // Confirmar con un código y obtener códigos de recuperación
+		dosFactores.POST("/desactivar", controllers.Desactivar2FA)                          // Deshabilitar el segundo factor
+		dosFactores.POST("/codigos-recuperacion", controllers.RegenerarCodigosRecuperacion) // Regenerar códigos de recuperación
+	}
+
+	// Rutas del usuario autenticado
+	me := router.Group("/me", middlewares.AuthMiddleware(), middlewares.SoloUsuarioMiddleware())
+	{
+		me.GET("", controllers.ObtenerMe)                             // Obtener usuario, empresa, roles y contactos
+		me.PUT("", controllers.ActualizarMe)                          // Actualizar datos de perfil e imagen
+		me.PUT("/password", controllers.ActualizarMiPassword)         // Cambiar contraseña indicando la actual
+		me.PUT("/email", controllers.ActualizarMiEmail)               // Cambiar email (requiere confirmar la nueva dirección)
+		me.GET("/sessions", controllers.ObtenerMisSesiones)           // Obtener sesiones activas (dispositivos)
+		me.DELETE("/sessions", controllers.RevocarMisOtrasSesiones)   // Cerrar todas las sesiones excepto la actual
+		me.DELETE("/sessions/:sesionID", controllers.RevocarMiSesion) // Cerrar una sesión específica
+		me.GET("/oidc", controllers.IniciarVincularOIDC)              // Obtener URL de autorización para vincular una cuenta externa
+		me.POST("/oidc", controllers.VincularOIDC)                    // Vincular la cuenta externa al usuario
+		me.DELETE("/oidc", controllers.DesvincularOIDC)               // Desvincular la cuenta externa
+	}
+
 	router.POST("/password-recovery", controllers.SolicitarRecuperacion)
 	router.POST("/reset-password", controllers.CambiarContrasena)
+	router.POST("/invitacion/aceptar", controllers.AceptarInvitacion) // Confirmar email y definir contraseña del usuario invitado
+	router.POST("/verificar-email", controllers.VerificarEmail)       // Confirmar un cambio de email
 
 	// Rutas para tipos de estructuras
 	tipos := router.Group("/tipos")
@@ -91,7 +140,7 @@ func SetupRouter() *gin.Engine {
 			noticiasEmpresa.GET("/", controllers.ObtenerNoticiasEmpresa)          // Función para obtener todas las noticias de una empresa
 			noticiasEmpresa.GET("/:noticiaID", controllers.ObtenerNoticiaEmpresa) // Función para obtener una noticia de empresa
 
-			imagenesNoticiasEmpresa := noticiasEmpresa.Group("/:noticiaID/imagenesNoticiasEmpresa")
+			imagenesNoticiasEmpresa := noticiasEmpresa.Group("/:noticiaID/imagenesNoticiasEmpresa", noticiaPublicada)
 			{
 				imagenesNoticiasEmpresa.GET("/", controllers.ObtenerImagenesNoticias)              // Obtener todas las imagenes de una Noticia
 				imagenesNoticiasEmpresa.GET("/:imagenNoticiaID", controllers.ObtenerImagenNoticia) // Obtener una Imagen de una Noticia
@@ -100,29 +149,32 @@ func SetupRouter() *gin.Engine {
 
 		prefabricadas := empresas.Group("/:empresaID/prefabricadas")
 		{
-			prefabricadas.GET("/", controllers.ObtenerPrefabricadas)               // Obtener todas las Prefabricadas de la Empresa
-			prefabricadas.GET("", controllers.ObtenerPrefabricadas)                // Obtener todas las Prefabricadas de la Empresa (sin slash al final)
-			prefabricadas.GET("/:prefabricadaID", controllers.ObtenerPrefabricada) // Obtener Prefabricada de acuerdo a su ID
-
-			imagenesPrefabricadas := prefabricadas.Group("/:prefabricadaID/imagenesPrefabricadas")
+			prefabricadas.GET("/", controllers.ObtenerPrefabricadas)                              // Obtener todas las Prefabricadas de la Empresa
+			prefabricadas.GET("", controllers.ObtenerPrefabricadas)                               // Obtener todas las Prefabricadas de la Empresa (sin slash al final)
+			prefabricadas.GET("/buscar", controllers.BuscarPrefabricadas)                         // Buscar Prefabricadas por texto libre (q)
+			prefabricadas.GET("/facetas", controllers.ObtenerFacetasPrefabricadas)                // Cantidades por opción de filtro del catálogo
+			prefabricadas.GET("/:prefabricadaID", controllers.ObtenerPrefabricada)                // Obtener Prefabricada de acuerdo a su ID
+			prefabricadas.GET("/:prefabricadaID/ficha.pdf", controllers.ObtenerFichaPrefabricada) // Ficha imprimible en PDF de la Prefabricada (por ID o slug)
+
+			imagenesPrefabricadas := prefabricadas.Group("/:prefabricadaID/imagenesPrefabricadas", prefabricadaPublicada)
 			{
 				imagenesPrefabricadas.GET("/", controllers.ObtenerImagenesPrefabricadas)                  // Obtener todas las Imagenes de una Prefabricada
 				imagenesPrefabricadas.GET("/:imagenPrefabricadaID", controllers.ObtenerImagePrefabricada) // Obtener una imagen de acuerdo a su ID de la prefabricada
 			}
 
-			caracteristicas := prefabricadas.Group("/:prefabricadaID/caracteristicas")
+			caracteristicas := prefabricadas.Group("/:prefabricadaID/caracteristicas", prefabricadaPublicada)
 			{
 				caracteristicas.GET("/", controllers.ObtenerCaracteristicas)                 // Obtener todas las características de la Prefabricada
 				caracteristicas.GET("/:caracteristicaID", controllers.ObtenerCaracteristica) // Obtener característica de acuerdo al ID
 
 			}
 
-			precios := prefabricadas.Group("/:prefabricadaID/precios")
+			precios := prefabricadas.Group("/:prefabricadaID/precios", prefabricadaPublicada)
 			{
 				precios.GET("/", controllers.ObtenerPrecios)         // Otener todos los Precios de una Prefabricada
 				precios.GET("/:precioID", controllers.ObtenerPrecio) // Obtener un Precio de acuerdo a su ID
 
-				incluyes := precios.Group("/:precioID/incluyes")
+				incluyes := precios.Group("/:precioID/incluyes", precioDePrefabricada)
 				{
 					incluyes.GET("/", controllers.ObtenerIncluyes)          // Obtener todos los Incluyes de un Precio
 					incluyes.GET("/:incluyeID", controllers.ObtenerIncluye) // Obtener Incluye de acuerdo a su ID
@@ -135,7 +187,7 @@ func SetupRouter() *gin.Engine {
 			usuarios.GET("/", controllers.ObtenerUsuarios)          // Obtener todos los Usuarios de una Empresa
 			usuarios.GET("/:usuarioID", controllers.ObtenerUsuario) // Obtener Usuario de acuerdo a su ID
 
-			contactos := usuarios.Group("/:usuarioID/contactos")
+			contactos := usuarios.Group("/:usuarioID/contactos", usuarioDeEmpresa)
 			{
 				contactos.GET("/", controllers.ObtenerContactos)           // Obtener todos los Contactos de un Usuario
 				contactos.GET("/:contactoID", controllers.ObtenerContacto) // Obtener Contacto de acuerdo a su ID
@@ -156,181 +208,277 @@ func SetupRouter() *gin.Engine {
 		}
 	}
 
+	// Permisos requeridos por las rutas de administración
+	leerCatalogo := middlewares.RequirePermission("catalogo:read")
+	escribirTaxonomias := middlewares.RequirePermission("taxonomias:write")
+	escribirPrefabricadas := middlewares.RequirePermission("prefabricadas:write")
+	escribirEmpresas := middlewares.RequirePermission("empresas:write")
+	leerNoticias := middlewares.RequirePermission("noticias:read")
+	escribirNoticias := middlewares.RequirePermission("noticias:write")
+	leerUsuarios := middlewares.RequirePermission("usuarios:read")
+	escribirUsuarios := middlewares.RequirePermission("usuarios:write")
+	escribirContactos := middlewares.RequirePermission("contactos:write")
+	escribirCredenciales := middlewares.RequirePermission("credenciales:write")
+	leerRoles := middlewares.RequirePermission("roles:read")
+	escribirRoles := middlewares.RequirePermission("roles:write")
+	escribirRolesUsuarios := middlewares.RequirePermission("roles_usuarios:write")
+	leerAuditoria := middlewares.RequirePermission("auditoria:read")
+	escribirApiKeys := middlewares.RequirePermission("api_keys:write")
+	leerLeads := middlewares.RequirePermission("leads:read")
+	escribirLeads := middlewares.RequirePermission("leads:write")
+	leerPapelera := middlewares.RequirePermission("papelera:read")
+	escribirPapelera := middlewares.RequirePermission("papelera:write")
+	empresaDelUsuario := middlewares.EmpresaMiddleware() // Aislamiento entre empresas
+	// Los roles, tipos, categorías y estilos son compartidos por todas las empresas: solo el super_administrador los modifica
+	soloSuperAdmin := middlewares.SuperAdminMiddleware()
+
 	// Rutas Administración del sistema
-	admin := router.Group("/administracion", middlewares.AuthMiddleware(), middlewares.SuperAdminMiddleware())
+	admin := router.Group("/administracion", middlewares.AuthMiddleware())
 	{
 		admin.GET("/", controllers.AdminObtenerServicios) // Página principal del panel
 
+		// Rutas para bloqueos de acceso por intentos fallidos
+		bloqueos := admin.Group("/bloqueos", middlewares.SuperAdminMiddleware())
+		{
+			bloqueos.GET("/", controllers.ObtenerBloqueosAcceso)          // Obtener bloqueos de acceso vigentes
+			bloqueos.DELETE("/:bloqueoID", controllers.DesbloquearAcceso) // Desbloquear un email o IP
+		}
+
+		// Historial de cambios hechos desde el panel
+		admin.GET("/auditoria", leerAuditoria, controllers.ObtenerAuditoria)
+
+		// Papelera: elementos eliminados de la empresa, que se pueden restaurar hasta que se eliminan definitivamente
+		papelera := admin.Group("/papelera")
+		{
+			papelera.GET("/", leerPapelera, controllers.ObtenerPapelera)                                     // Listar elementos eliminados
+			papelera.POST("/:papeleraID/restaurar", escribirPapelera, controllers.RestaurarElementoPapelera) // Restaurar un elemento con todo lo que se eliminó junto a él
+		}
+
+		// Configuración de seguridad del sistema
+		configuracion := admin.Group("/configuracion", middlewares.SuperAdminMiddleware())
+		{
+			configuracion.GET("/seguridad", controllers.ObtenerConfiguracionSeguridad)    // Obtener configuración de seguridad
+			configuracion.PUT("/seguridad", controllers.ActualizarConfiguracionSeguridad) // Actualizar configuración de seguridad
+			configuracion.GET("/papelera", controllers.ObtenerConfiguracionPapelera)      // Obtener días de retención de la papelera
+			configuracion.PUT("/papelera", controllers.ActualizarConfiguracionPapelera)   // Actualizar días de retención de la papelera
+		}
+
 		// Rutas para tipos de estructuras
 		tipos := admin.Group("/tipos")
 		{
-			tipos.POST("/", controllers.CrearTipo)         // Crear un Tipo de estructura
-			tipos.GET("/", controllers.ObtenerTipos)       // Obtener todos los Tipos de estructuras
-			tipos.GET("/:id", controllers.ObtenerTipo)     // Obtener Tipo estructura de acuerdo a su ID
-			tipos.PUT("/:id", controllers.ActualizarTipo)  // Actualizar datos de Tipos de estructuras
-			tipos.DELETE("/:id", controllers.EliminarTipo) // Eliminar Tipo estructura de acuerdo a su ID(Eliminación lógica)
+			tipos.POST("/", soloSuperAdmin, escribirTaxonomias, controllers.CrearTipo)         // Crear un Tipo de estructura
+			tipos.GET("/", leerCatalogo, controllers.ObtenerTipos)                             // Obtener todos los Tipos de estructuras
+			tipos.GET("/:id", leerCatalogo, controllers.ObtenerTipo)                           // Obtener Tipo estructura de acuerdo a su ID
+			tipos.PUT("/:id", soloSuperAdmin, escribirTaxonomias, controllers.ActualizarTipo)  // Actualizar datos de Tipos de estructuras
+			tipos.DELETE("/:id", soloSuperAdmin, escribirTaxonomias, controllers.EliminarTipo) // Eliminar Tipo estructura de acuerdo a su ID(Eliminación lógica)
 		}
 
 		// Rutas para categorias
 		categorias := admin.Group("/categorias")
 		{
-			categorias.POST("/", controllers.CrearCategoria)         // Crear Categoria
-			categorias.GET("/", controllers.ObtenerCategorias)       // Obtener todas las Categorias
-			categorias.GET("/:id", controllers.ObtenerCategoria)     // Obtener Categoria de acuerdo al ID
-			categorias.PUT("/:id", controllers.ActualizarCategoria)  // Actualizar datos de Categoría
-			categorias.DELETE("/:id", controllers.EliminarCategoria) // Eliminar lógicamente Categoria de acuerdo a su ID
+			categorias.POST("/", soloSuperAdmin, escribirTaxonomias, controllers.CrearCategoria)         // Crear Categoria
+			categorias.GET("/", leerCatalogo, controllers.ObtenerCategorias)                             // Obtener todas las Categorias
+			categorias.GET("/:id", leerCatalogo, controllers.ObtenerCategoria)                           // Obtener Categoria de acuerdo al ID
+			categorias.PUT("/:id", soloSuperAdmin, escribirTaxonomias, controllers.ActualizarCategoria)  // Actualizar datos de Categoría
+			categorias.DELETE("/:id", soloSuperAdmin, escribirTaxonomias, controllers.EliminarCategoria) // Eliminar lógicamente Categoria de acuerdo a su ID
 		}
 
 		estilos := admin.Group("/estilos")
 		{
-			estilos.POST("/", controllers.CrearEstilo)         // Crear Estilo
-			estilos.GET("/", controllers.ObtenerEstilos)       // Obtener todos los Estilos
-			estilos.GET("/:id", controllers.ObtenerEstilo)     // Obtener Estilo de acuerdo a su ID
-			estilos.PUT("/:id", controllers.ActualizarEstilo)  // Actualizar datos de Estilo de acuerdo a su ID
-			estilos.DELETE("/:id", controllers.EliminarEstilo) // Eliminar lógicamente un Estilo de acuerdo al ID
+			estilos.POST("/", soloSuperAdmin, escribirTaxonomias, controllers.CrearEstilo)         // Crear Estilo
+			estilos.GET("/", leerCatalogo, controllers.ObtenerEstilos)                             // Obtener todos los Estilos
+			estilos.GET("/:id", leerCatalogo, controllers.ObtenerEstilo)                           // Obtener Estilo de acuerdo a su ID
+			estilos.PUT("/:id", soloSuperAdmin, escribirTaxonomias, controllers.ActualizarEstilo)  // Actualizar datos de Estilo de acuerdo a su ID
+			estilos.DELETE("/:id", soloSuperAdmin, escribirTaxonomias, controllers.EliminarEstilo) // Eliminar lógicamente un Estilo de acuerdo al ID
 		}
 
 		empresas := admin.Group("/empresas")
 		{
-			empresas.POST("/", controllers.CrearEmpresa)                // Crear Empresa
-			empresas.GET("/", controllers.ObtenerEmpresas)              // Obtener todas las Empresas
-			empresas.GET("/:empresaID", controllers.ObtenerEmpresa)     // Obtener datos de Empresa de acuerdo a su ID
-			empresas.PUT("/:empresaID", controllers.ActualizarEmpresa)  // Actualizar datos de Empresa
-			empresas.DELETE("/:empresaID", controllers.EliminarEmpresa) // Eliminar Empresa de acuerdo a su ID
+			empresas.POST("/", escribirEmpresas, controllers.CrearEmpresa)                                   // Crear Empresa
+			empresas.GET("/", leerCatalogo, controllers.ObtenerEmpresas)                                     // Obtener todas las Empresas
+			empresas.GET("/:empresaID", empresaDelUsuario, leerCatalogo, controllers.ObtenerEmpresa)         // Obtener datos de Empresa de acuerdo a su ID
+			empresas.PUT("/:empresaID", empresaDelUsuario, escribirEmpresas, controllers.ActualizarEmpresa)  // Actualizar datos de Empresa
+			empresas.DELETE("/:empresaID", empresaDelUsuario, escribirEmpresas, controllers.EliminarEmpresa) // Eliminar Empresa de acuerdo a su ID
+
+			// API keys para integraciones de la Empresa
+			apiKeys := empresas.Group("/:empresaID/api-keys", empresaDelUsuario, escribirApiKeys)
+			{
+				apiKeys.POST("/", controllers.CrearApiKey)              // Crear API key (se muestra una sola vez)
+				apiKeys.GET("/", controllers.ObtenerApiKeys)            // Obtener las API keys de la Empresa
+				apiKeys.DELETE("/:apiKeyID", controllers.RevocarApiKey) // Revocar una API key
+			}
 
-			servicios := empresas.Group("/:empresaID/servicios")
+			// Leads (solicitudes de contacto) recibidos desde el sitio web o integraciones de la Empresa
+			leads := empresas.Group("/:empresaID/leads", empresaDelUsuario)
 			{
-				servicios.POST("/", controllers.CrearServicio)                 // Crear un servicio de la Empresa
-				servicios.GET("/", controllers.ObtenerServicios)               // Obtener todos los Servicios
-				servicios.GET("/:servicioID", controllers.ObtenerServicio)     // Obtener Servicio de acuerdo a su ID
-				servicios.PUT("/:servicioID", controllers.ActualizarServicio)  // Actualizar datos del Servicio de acuerdo a su ID
-				servicios.DELETE("/:servicioID", controllers.EliminarServicio) // Eliminar lógicamente un servicio de acuerdo a su ID
+				leads.POST("/", escribirLeads, controllers.CrearLead)     // Registrar un lead
+				leads.GET("/", leerLeads, controllers.ObtenerLeads)       // Obtener los leads de la Empresa
+				leads.GET("/:leadID", leerLeads, controllers.ObtenerLead) // Obtener un lead
 			}
 
-			redes := empresas.Group("/:empresaID/redes")
+			// Inicio de sesión con OpenID Connect de la Empresa
+			empresas.GET("/:empresaID/oidc", empresaDelUsuario, escribirEmpresas, controllers.ObtenerConfiguracionOIDC)    // Obtener configuración OIDC
+			empresas.PUT("/:empresaID/oidc", empresaDelUsuario, escribirEmpresas, controllers.ActualizarConfiguracionOIDC) // Habilitar y definir dominios permitidos
+
+			servicios := empresas.Group("/:empresaID/servicios", empresaDelUsuario)
 			{
-				redes.POST("/", controllers.CrearRed)            // Crea Red Social de la Empresa
-				redes.GET("/", controllers.ObtenerRedes)         // Obtener todas las redes sociales de la empresa
-				redes.GET("/:redID", controllers.ObtenerRed)     // Obtener Red social de acuerdo a su ID
-				redes.PUT("/:redID", controllers.ActualizarRed)  // Actualizar datos de una red social de acuerdo a su ID
-				redes.DELETE("/:redID", controllers.EliminarRed) // Eliminar Red Social de acuerdo al ID enviado
+				servicios.POST("/", escribirEmpresas, controllers.CrearServicio)                 // Crear un servicio de la Empresa
+				servicios.GET("/", leerCatalogo, controllers.ObtenerServicios)                   // Obtener todos los Servicios
+				servicios.GET("/:servicioID", leerCatalogo, controllers.ObtenerServicio)         // Obtener Servicio de acuerdo a su ID
+				servicios.PUT("/:servicioID", escribirEmpresas, controllers.ActualizarServicio)  // Actualizar datos del Servicio de acuerdo a su ID
+				servicios.DELETE("/:servicioID", escribirEmpresas, controllers.EliminarServicio) // Eliminar lógicamente un servicio de acuerdo a su ID
 			}
 
-			portadas := empresas.Group("/:empresaID/portadas")
+			redes := empresas.Group("/:empresaID/redes", empresaDelUsuario)
 			{
-				portadas.POST("/", controllers.CrearPortada)                // Crear Portada
-				portadas.GET("/", controllers.ObtenerPortadas)              // Obtener todas las portadas de la Empresa
-				portadas.GET("/:portadaID", controllers.ObtenerPortada)     // Obtener Portada de acuerdo a su ID
-				portadas.PUT("/:portadaID", controllers.ActualizarPortada)  // Actualizar datos de una Portada
-				portadas.DELETE("/:portadaID", controllers.EliminarPortada) // Eliminar una portada
+				redes.POST("/", escribirEmpresas, controllers.CrearRed)            // Crea Red Social de la Empresa
+				redes.GET("/", leerCatalogo, controllers.ObtenerRedes)             // Obtener todas las redes sociales de la empresa
+				redes.GET("/:redID", leerCatalogo, controllers.ObtenerRed)         // Obtener Red social de acuerdo a su ID
+				redes.PUT("/:redID", escribirEmpresas, controllers.ActualizarRed)  // Actualizar datos de una red social de acuerdo a su ID
+				redes.DELETE("/:redID", escribirEmpresas, controllers.EliminarRed) // Eliminar Red Social de acuerdo al ID enviado
 			}
 
-			noticiasEmpresa := empresas.Group("/:empresaID/noticiasEmpresa")
+			portadas := empresas.Group("/:empresaID/portadas", empresaDelUsuario)
 			{
-				noticiasEmpresa.POST("/", controllers.CrearNoticia)                   // Crear una Noticia
-				noticiasEmpresa.GET("/", controllers.ObtenerNoticiasEmpresa)          // Función para obtener todas las noticias de una empresa
-				noticiasEmpresa.GET("/:noticiaID", controllers.ObtenerNoticiaEmpresa) // Función para obtener una noticia de empresa
-				noticiasEmpresa.PUT("/:noticiaID", controllers.ActualizarNoticia)     // Actualizar datos de Una Noticias
-				noticiasEmpresa.DELETE("/:noticiaID", controllers.EliminarNoticia)    // Eliminar lógicamente una Noticia de acuerdo a su ID
+				portadas.POST("/", escribirEmpresas, controllers.CrearPortada)                                      // Crear Portada
+				portadas.GET("/", leerCatalogo, controllers.ObtenerPortadas)                                        // Obtener todas las portadas de la Empresa
+				portadas.GET("/:portadaID", leerCatalogo, controllers.ObtenerPortada)                               // Obtener Portada de acuerdo a su ID
+				portadas.PUT("/:portadaID", escribirEmpresas, controllers.ActualizarPortada)                        // Actualizar datos de una Portada
+				portadas.PUT("/:portadaID/publicacion", escribirEmpresas, controllers.ActualizarPublicacionPortada) // Publicar, programar o archivar una Portada
+				portadas.DELETE("/:portadaID", escribirEmpresas, controllers.EliminarPortada)                       // Eliminar una portada
+			}
 
-				imagenesNoticiasEmpresa := noticiasEmpresa.Group("/:noticiaID/imagenesNoticiasEmpresa")
+			noticiasEmpresa := empresas.Group("/:empresaID/noticiasEmpresa", empresaDelUsuario)
+			{
+				noticiasEmpresa.POST("/", escribirNoticias, controllers.CrearNoticia)                                      // Crear una Noticia
+				noticiasEmpresa.GET("/", leerNoticias, controllers.ObtenerNoticiasEmpresa)                                 // Función para obtener todas las noticias de una empresa
+				noticiasEmpresa.GET("/:noticiaID", leerNoticias, controllers.ObtenerNoticiaEmpresa)                        // Función para obtener una noticia de empresa
+				noticiasEmpresa.PUT("/:noticiaID", escribirNoticias, controllers.ActualizarNoticia)                        // Actualizar datos de Una Noticias
+				noticiasEmpresa.PUT("/:noticiaID/publicacion", escribirNoticias, controllers.ActualizarPublicacionNoticia) // Publicar, programar o archivar una Noticia
+				noticiasEmpresa.DELETE("/:noticiaID", escribirNoticias, controllers.EliminarNoticia)                       // Eliminar lógicamente una Noticia de acuerdo a su ID
+
+				imagenesNoticiasEmpresa := noticiasEmpresa.Group("/:noticiaID/imagenesNoticiasEmpresa", noticiaDeEmpresa)
 				{
-					imagenesNoticiasEmpresa.POST("/", controllers.CrearImagenNoticia)                      // Crear una imagen para una Noticia
-					imagenesNoticiasEmpresa.GET("/", controllers.ObtenerImagenesNoticias)                  // Obtener todas las imagenes de una Noticia
-					imagenesNoticiasEmpresa.GET("/:imagenNoticiaID", controllers.ObtenerImagenNoticia)     // Obtener una Imagen de una Noticia
-					imagenesNoticiasEmpresa.PUT("/:imagenNoticiaID", controllers.ActualizarImagenNoticia)  // Actualizar una imagen de una Noticia
-					imagenesNoticiasEmpresa.DELETE("/:imagenNoticiaID", controllers.EliminarImagenNoticia) // Eliminar Logicamente una Imagen de una Noticia
+					imagenesNoticiasEmpresa.POST("/", escribirNoticias, controllers.CrearImagenNoticia)                      // Crear una imagen para una Noticia
+					imagenesNoticiasEmpresa.GET("/", leerNoticias, controllers.ObtenerImagenesNoticias)                      // Obtener todas las imagenes de una Noticia
+					imagenesNoticiasEmpresa.GET("/:imagenNoticiaID", leerNoticias, controllers.ObtenerImagenNoticia)         // Obtener una Imagen de una Noticia
+					imagenesNoticiasEmpresa.PUT("/:imagenNoticiaID", escribirNoticias, controllers.ActualizarImagenNoticia)  // Actualizar una imagen de una Noticia
+					imagenesNoticiasEmpresa.DELETE("/:imagenNoticiaID", escribirNoticias, controllers.EliminarImagenNoticia) // Eliminar Logicamente una Imagen de una Noticia
 				}
 			}
 
-			prefabricadas := empresas.Group("/:empresaID/prefabricadas")
+			prefabricadas := empresas.Group("/:empresaID/prefabricadas", empresaDelUsuario)
 			{
-				prefabricadas.POST("/", controllers.CrearPrefabricada)                     // Crear una prefabricada
-				prefabricadas.GET("/", controllers.ObtenerPrefabricadas)                   // Obtener todas las Prefabricadas de la Empresa
-				prefabricadas.GET("", controllers.ObtenerPrefabricadas)                    // Obtener todas las Prefabricadas de la Empresa (sin slash al final)
-				prefabricadas.GET("/:prefabricadaID", controllers.ObtenerPrefabricada)     // Obtener Prefabricada de acuerdo a su ID
-				prefabricadas.PUT("/:prefabricadaID", controllers.ActualizarPrefabricada)  // Actualizar datos de Prefabricada
-				prefabricadas.DELETE("/:prefabricadaID", controllers.EliminarPrefabricada) // Eliminar lógicamente una Prefabricada de acuerdo al ID enviado
-
-				imagenesPrefabricadas := prefabricadas.Group("/:prefabricadaID/imagenesPrefabricadas")
+				prefabricadas.POST("/", escribirPrefabricadas, controllers.CrearPrefabricada)                                           // Crear una prefabricada
+				prefabricadas.GET("/", leerCatalogo, controllers.ObtenerPrefabricadas)                                                  // Obtener todas las Prefabricadas de la Empresa
+				prefabricadas.GET("", leerCatalogo, controllers.ObtenerPrefabricadas)                                                   // Obtener todas las Prefabricadas de la Empresa (sin slash al final)
+				prefabricadas.GET("/buscar", leerCatalogo, controllers.BuscarPrefabricadas)                                             // Buscar Prefabricadas por texto libre (q)
+				prefabricadas.GET("/facetas", leerCatalogo, controllers.ObtenerFacetasPrefabricadas)                                    // Cantidades por opción de filtro del catálogo
+				prefabricadas.GET("/export/:formato", leerCatalogo, controllers.ExportarPrefabricadas)                                  // Exportar las Prefabricadas filtradas en csv, xlsx o json
+				prefabricadas.POST("/importar", escribirPrefabricadas, controllers.ImportarPrefabricadas)                               // Importar Prefabricadas desde CSV o XLSX (dry_run=true para simular)
+				prefabricadas.GET("/importaciones", escribirPrefabricadas, controllers.ObtenerImportaciones)                            // Historial de importaciones
+				prefabricadas.GET("/importaciones/:importacionID", escribirPrefabricadas, controllers.ObtenerImportacion)               // Obtener una importación con sus cambios y errores por fila
+				prefabricadas.GET("/:prefabricadaID", leerCatalogo, controllers.ObtenerPrefabricada)                                    // Obtener Prefabricada de acuerdo a su ID
+				prefabricadas.PUT("/:prefabricadaID", escribirPrefabricadas, controllers.ActualizarPrefabricada)                        // Actualizar datos de Prefabricada
+				prefabricadas.PUT("/:prefabricadaID/publicacion", escribirPrefabricadas, controllers.ActualizarPublicacionPrefabricada) // Publicar, programar o archivar una Prefabricada
+				prefabricadas.POST("/:prefabricadaID/clonar", escribirPrefabricadas, controllers.ClonarPrefabricada)                    // Copiar una Prefabricada con sus precios, incluyes, características e imágenes
+				prefabricadas.DELETE("/:prefabricadaID", escribirPrefabricadas, controllers.EliminarPrefabricada)                       // Eliminar lógicamente una Prefabricada de acuerdo al ID enviado
+
+				imagenesPrefabricadas := prefabricadas.Group("/:prefabricadaID/imagenesPrefabricadas", prefabricadaDeEmpresa)
 				{
-					imagenesPrefabricadas.POST("/", controllers.CrearImagen_prefabricada)                          // Crear imagen prefabricada
-					imagenesPrefabricadas.GET("/", controllers.ObtenerImagenesPrefabricadas)                       // Obtener todas las Imagenes de una Prefabricada
-					imagenesPrefabricadas.GET("/:imagenPrefabricadaID", controllers.ObtenerImagePrefabricada)      // Obtener una imagen de acuerdo a su ID de la prefabricada
-					imagenesPrefabricadas.PUT("/:imagenPrefabricadaID", controllers.ActualizarImagenPrefabricada)  // Actualizar datos de una imagen
-					imagenesPrefabricadas.DELETE("/:imagenPrefabricadaID", controllers.EliminarImagenPrefabricada) // Eliminar lógicamente una imagen_prefabricada
+					imagenesPrefabricadas.POST("/", escribirPrefabricadas, controllers.CrearImagen_prefabricada)                          // Crear imagen prefabricada
+					imagenesPrefabricadas.GET("/", leerCatalogo, controllers.ObtenerImagenesPrefabricadas)                                // Obtener todas las Imagenes de una Prefabricada
+					imagenesPrefabricadas.GET("/:imagenPrefabricadaID", leerCatalogo, controllers.ObtenerImagePrefabricada)               // Obtener una imagen de acuerdo a su ID de la prefabricada
+					imagenesPrefabricadas.PUT("/:imagenPrefabricadaID", escribirPrefabricadas, controllers.ActualizarImagenPrefabricada)  // Actualizar datos de una imagen
+					imagenesPrefabricadas.DELETE("/:imagenPrefabricadaID", escribirPrefabricadas, controllers.EliminarImagenPrefabricada) // Eliminar lógicamente una imagen_prefabricada
 				}
 
-				caracteristicas := prefabricadas.Group("/:prefabricadaID/caracteristicas")
+				caracteristicas := prefabricadas.Group("/:prefabricadaID/caracteristicas", prefabricadaDeEmpresa)
 				{
-					caracteristicas.POST("/", controllers.CrearCaracteristica)                       // Crear una nueva característica
-					caracteristicas.GET("/", controllers.ObtenerCaracteristicas)                     // Obtener todas las características de la Prefabricada
-					caracteristicas.GET("/:caracteristicaID", controllers.ObtenerCaracteristica)     // Obtener característica de acuerdo al ID
-					caracteristicas.PUT("/:caracteristicaID", controllers.ActualizarCaracteristica)  // Actualizar datos de Caracterítica
-					caracteristicas.DELETE("/:caracteristicaID", controllers.EliminarCaracteristica) // Eliminar lógicamente una Característica
+					caracteristicas.POST("/", escribirPrefabricadas, controllers.CrearCaracteristica)                       // Crear una nueva característica
+					caracteristicas.GET("/", leerCatalogo, controllers.ObtenerCaracteristicas)                              // Obtener todas las características de la Prefabricada
+					caracteristicas.GET("/:caracteristicaID", leerCatalogo, controllers.ObtenerCaracteristica)              // Obtener característica de acuerdo al ID
+					caracteristicas.PUT("/:caracteristicaID", escribirPrefabricadas, controllers.ActualizarCaracteristica)  // Actualizar datos de Caracterítica
+					caracteristicas.DELETE("/:caracteristicaID", escribirPrefabricadas, controllers.EliminarCaracteristica) // Eliminar lógicamente una Característica
 				}
 
-				precios := prefabricadas.Group("/:prefabricadaID/precios")
+				precios := prefabricadas.Group("/:prefabricadaID/precios", prefabricadaDeEmpresa)
 				{
-					precios.POST("/", controllers.CrearPrecio)               // Crear un precio a una Prefabricada
-					precios.GET("/", controllers.ObtenerPrecios)             // Otener todos los Precios de una Prefabricada
-					precios.GET("/:precioID", controllers.ObtenerPrecio)     // Obtener un Precio de acuerdo a su ID
-					precios.PUT("/:precioID", controllers.ActualizarPrecio)  // Actualizar un Precio de acuerdo al ID
-					precios.DELETE("/:precioID", controllers.EliminarPrecio) // Eliminar un Precio de acuerdo al ID
+					precios.POST("/", escribirPrefabricadas, controllers.CrearPrecio)               // Crear un precio a una Prefabricada
+					precios.GET("/", leerCatalogo, controllers.ObtenerPrecios)                      // Otener todos los Precios de una Prefabricada
+					precios.GET("/:precioID", leerCatalogo, controllers.ObtenerPrecio)              // Obtener un Precio de acuerdo a su ID
+					precios.PUT("/:precioID", escribirPrefabricadas, controllers.ActualizarPrecio)  // Actualizar un Precio de acuerdo al ID
+					precios.DELETE("/:precioID", escribirPrefabricadas, controllers.EliminarPrecio) // Eliminar un Precio de acuerdo al ID
 
-					incluyes := precios.Group("/:precioID/incluyes")
+					incluyes := precios.Group("/:precioID/incluyes", precioDePrefabricada)
 					{
-						incluyes.POST("/", controllers.CrearIncluye)                // Crear un Incluye de un precio
-						incluyes.GET("/", controllers.ObtenerIncluyes)              // Obtener todos los Incluyes de un Precio
-						incluyes.GET("/:incluyeID", controllers.ObtenerIncluye)     // Obtener Incluye de acuerdo a su ID
-						incluyes.PUT("/:incluyeID", controllers.ActualizarIncluye)  // Actualizar Incluye
-						incluyes.DELETE("/:incluyeID", controllers.EliminarIncluye) // Eliminar lógicamente un Incluye
+						incluyes.POST("/", escribirPrefabricadas, controllers.CrearIncluye)                // Crear un Incluye de un precio
+						incluyes.GET("/", leerCatalogo, controllers.ObtenerIncluyes)                       // Obtener todos los Incluyes de un Precio
+						incluyes.GET("/:incluyeID", leerCatalogo, controllers.ObtenerIncluye)              // Obtener Incluye de acuerdo a su ID
+						incluyes.PUT("/:incluyeID", escribirPrefabricadas, controllers.ActualizarIncluye)  // Actualizar Incluye
+						incluyes.DELETE("/:incluyeID", escribirPrefabricadas, controllers.EliminarIncluye) // Eliminar lógicamente un Incluye
 					}
 				}
 			}
 
-			usuarios := empresas.Group("/:empresaID/usuarios")
+			usuarios := empresas.Group("/:empresaID/usuarios", empresaDelUsuario)
 			{
-				usuarios.POST("/", controllers.CrearUsuario)                // Crear un nuevo usuarios
-				usuarios.GET("/", controllers.ObtenerUsuarios)              // Obtener todos los Usuarios de una Empresa
-				usuarios.GET("/:usuarioID", controllers.ObtenerUsuario)     // Obtener Usuario de acuerdo a su ID
-				usuarios.PUT("/:usuarioID", controllers.ActualizarUsuario)  // Actualizar datos de Usuario de acuerdo a su ID
-				usuarios.DELETE("/:usuarioID", controllers.EliminarUsuario) // Eliminar Usuario lógicamente de acuerdo a su ID
+				usuarios.POST("/", escribirUsuarios, controllers.CrearUsuario)                // Crear un nuevo usuarios
+				usuarios.GET("/", leerUsuarios, controllers.ObtenerUsuarios)                  // Obtener todos los Usuarios de una Empresa
+				usuarios.GET("/:usuarioID", leerUsuarios, controllers.ObtenerUsuario)         // Obtener Usuario de acuerdo a su ID
+				usuarios.PUT("/:usuarioID", escribirUsuarios, controllers.ActualizarUsuario)  // Actualizar datos de Usuario de acuerdo a su ID
+				usuarios.DELETE("/:usuarioID", escribirUsuarios, controllers.EliminarUsuario) // Eliminar Usuario lógicamente de acuerdo a su ID
+
+				// Sesiones del Usuario, solo para el super administrador
+				sesiones := usuarios.Group("/:usuarioID/sesiones", usuarioDeEmpresa, middlewares.SuperAdminMiddleware())
+				{
+					sesiones.GET("/", controllers.ObtenerSesionesUsuario)      // Obtener sesiones activas del Usuario
+					sesiones.DELETE("/", controllers.RevocarSesionesDeUsuario) // Cerrar todas las sesiones del Usuario
+				}
 
-				contactos := usuarios.Group("/:usuarioID/contactos")
+				contactos := usuarios.Group("/:usuarioID/contactos", usuarioDeEmpresa)
 				{
-					contactos.POST("/", controllers.CrearContacto)                 // Crear datos de Contacto de Usuario
-					contactos.GET("/", controllers.ObtenerContactos)               // Obtener todos los Contactos de un Usuario
-					contactos.GET("/:contactoID", controllers.ObtenerContacto)     // Obtener Contacto de acuerdo a su ID
-					contactos.PUT("/:contactoID", controllers.ActualizarContacto)  // Actualizar datos de Contacto
-					contactos.DELETE("/:contactoID", controllers.EliminarContacto) // Eliminar Datos de Contacto lógicamente
+					contactos.POST("/", escribirContactos, controllers.CrearContacto)                 // Crear datos de Contacto de Usuario
+					contactos.GET("/", escribirContactos, controllers.ObtenerContactos)               // Obtener todos los Contactos de un Usuario
+					contactos.GET("/:contactoID", escribirContactos, controllers.ObtenerContacto)     // Obtener Contacto de acuerdo a su ID
+					contactos.PUT("/:contactoID", escribirContactos, controllers.ActualizarContacto)  // Actualizar datos de Contacto
+					contactos.DELETE("/:contactoID", escribirContactos, controllers.EliminarContacto) // Eliminar Datos de Contacto lógicamente
 				}
 
-				credenciales := usuarios.Group("/:usuarioID/credenciales")
+				credenciales := usuarios.Group("/:usuarioID/credenciales", usuarioDeEmpresa)
 				{
-					credenciales.GET("/", controllers.ObtenerCredenciales)                 // Obtener credenciales de un usuario
-					credenciales.POST("/", controllers.CrearCredencial)                    // Crear Credenciales de Usuario
-					credenciales.PUT("/:credencialID", controllers.ActualizarCredenciales) // Actualizar credenciales de acceso
+					credenciales.GET("/", escribirCredenciales, controllers.ObtenerCredenciales)                         // Obtener credenciales de un usuario
+					credenciales.POST("/", escribirCredenciales, controllers.CrearCredencial)                            // Crear Credenciales de Usuario
+					credenciales.PUT("/:credencialID", escribirCredenciales, controllers.ActualizarCredenciales)         // Actualizar credenciales de acceso
+					credenciales.POST("/:credencialID/invitacion", escribirCredenciales, controllers.ReenviarInvitacion) // Reenviar la invitación de acceso
 				}
 
 			}
 		}
 
+		admin.GET("/permisos", leerRoles, controllers.ObtenerPermisos) // Obtener todos los Permisos disponibles
+
 		roles := admin.Group("/roles")
 		{
-			roles.POST("/", controllers.CrearRol)            // Crea un nuevo Rol
-			roles.GET("/", controllers.ObtenerRoles)         // Obtener todos los Roles
-			roles.GET("/:rolID", controllers.ObtenerRol)     // Obtener Rol de acuerdo a su ID
-			roles.PUT("/:rolID", controllers.ActualizarRol)  // Actualizar datos de un Rol
-			roles.DELETE("/:rolID", controllers.EliminarRol) // Eliminar Rol lógicamente
+			roles.POST("/", soloSuperAdmin, escribirRoles, controllers.CrearRol)            // Crea un nuevo Rol
+			roles.GET("/", leerRoles, controllers.ObtenerRoles)                             // Obtener todos los Roles
+			roles.GET("/:rolID", leerRoles, controllers.ObtenerRol)                         // Obtener Rol de acuerdo a su ID
+			roles.PUT("/:rolID", soloSuperAdmin, escribirRoles, controllers.ActualizarRol)  // Actualizar datos de un Rol
+			roles.DELETE("/:rolID", soloSuperAdmin, escribirRoles, controllers.EliminarRol) // Eliminar Rol lógicamente
+
+			permisosRol := roles.Group("/:rolID/permisos")
+			{
+				permisosRol.GET("/", leerRoles, controllers.ObtenerPermisosRol)                     // Obtener los permisos de un Rol
+				permisosRol.PUT("/", soloSuperAdmin, escribirRoles, controllers.AsignarPermisosRol) // Reemplazar los permisos de un Rol
+			}
 
 			rolesUsuarios := roles.Group("/:rolID/roles_usuarios")
 			{
-				rolesUsuarios.POST("/", controllers.CrearRol_usuario)                    // Asignarle un Rol a un Usuario
-				rolesUsuarios.GET("/", controllers.ObtenerRoles_usuarios)                // Obtener todos los roles y sus usuarios
-				rolesUsuarios.GET("/:rol_usuarioID", controllers.ObtenerRol_usuario)     // Obtener Rol_usuario
-				rolesUsuarios.PUT("/:rol_usuarioID", controllers.ActualizarRol_usuario)  // Actualizar datos Rol_usuario
-				rolesUsuarios.DELETE("/:rol_usuarioID", controllers.EliminarRol_usuario) // Eliminar lógicamente un rol_usuario
+				rolesUsuarios.POST("/", escribirRolesUsuarios, controllers.CrearRol_usuario)                    // Asignarle un Rol a un Usuario
+				rolesUsuarios.GET("/", leerRoles, controllers.ObtenerRoles_usuarios)                            // Obtener todos los roles y sus usuarios
+				rolesUsuarios.GET("/:rol_usuarioID", leerRoles, controllers.ObtenerRol_usuario)                 // Obtener Rol_usuario
+				rolesUsuarios.PUT("/:rol_usuarioID", escribirRolesUsuarios, controllers.ActualizarRol_usuario)  // Actualizar datos Rol_usuario
+				rolesUsuarios.DELETE("/:rol_usuarioID", escribirRolesUsuarios, controllers.EliminarRol_usuario) // Eliminar lógicamente un rol_usuario
 			}
 		}
 	}
@@ -0,0 +1,105 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"time"
+	"v1_prefabricadas/configs"
+	"v1_prefabricadas/models"
+
+	"gorm.io/gorm"
+)
+
+// Prefijo de las API keys, permite distinguirlas de un JWT en la cabecera Authorization
+const PrefijoApiKey = "ce_"
+
+// Cada cuánto se actualiza last_used_at, para no escribir en la base de datos en cada request
+const intervaloUltimoUso = time.Minute
+
+var ErrApiKeyInvalida = errors.New("API key inválida, revocada o expirada")
+
+// ScopesApiKey relaciona cada scope que se puede otorgar a una API key con los permisos que concede
+var ScopesApiKey = map[string][]string{
+	"catalog:read":   {"catalogo:read"},
+	"catalog:write":  {"catalogo:read", "prefabricadas:write"},
+	"noticias:read":  {"noticias:read"},
+	"noticias:write": {"noticias:read", "noticias:write"},
+	"leads:write":    {"leads:write"},
+}
+
+// ValidarScopes verifica que todos los scopes existan y los devuelve sin duplicados
+func ValidarScopes(scopes []string) ([]string, error) {
+	vistos := map[string]bool{}
+	var validos []string
+	for _, scope := range scopes {
+		scope = strings.TrimSpace(scope)
+		if _, ok := ScopesApiKey[scope]; !ok {
+			return nil, errors.New("scope desconocido: " + scope)
+		}
+		if !vistos[scope] {
+			vistos[scope] = true
+			validos = append(validos, scope)
+		}
+	}
+	if len(validos) == 0 {
+		return nil, errors.New("debe indicar al menos un scope")
+	}
+	return validos, nil
+}
+
+// CrearApiKey genera una llave nueva para la empresa y devuelve la llave en texto plano, que solo se muestra una vez
+func CrearApiKey(db *gorm.DB, apiKey *models.Api_key, scopes []string) (string, error) {
+	token, err := GenerarTokenAleatorio()
+	if err != nil {
+		return "", err
+	}
+	llave := PrefijoApiKey + token
+
+	apiKey.Prefijo = llave[:len(PrefijoApiKey)+8]
+	apiKey.KeyHash = HashToken(llave)
+	apiKey.Scopes = strings.Join(scopes, ",")
+	if err := db.Create(apiKey).Error; err != nil {
+		return "", err
+	}
+
+	return llave, nil
+}
+
+// AutenticarApiKey busca una llave vigente, registra su uso y devuelve los permisos que otorgan sus scopes
+func AutenticarApiKey(llave string) (models.Api_key, map[string]bool, error) {
+	var apiKey models.Api_key
+	// Las llaves de una empresa en la papelera dejan de funcionar hasta que se restaure
+	err := configs.DB.Where("key_hash = ?", HashToken(llave)).
+		Where("empresa_id IN (?)", configs.DB.Table("empresa").Select("id").Where("deleted_at IS NULL")).
+		First(&apiKey).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return apiKey, nil, ErrApiKeyInvalida
+	}
+	if err != nil {
+		return apiKey, nil, err
+	}
+
+	now := time.Now()
+	if apiKey.RevokedAt != nil || (apiKey.ExpiresAt != nil && now.After(*apiKey.ExpiresAt)) {
+		return apiKey, nil, ErrApiKeyInvalida
+	}
+
+	if apiKey.LastUsedAt == nil || now.Sub(*apiKey.LastUsedAt) > intervaloUltimoUso {
+		if err := configs.DB.Model(&apiKey).UpdateColumn("last_used_at", now).Error; err != nil {
+			return apiKey, nil, err
+		}
+	}
+
+	return apiKey, PermisosScopes(apiKey.Scopes), nil
+}
+
+// PermisosScopes convierte los scopes guardados en la llave en el mapa de permisos usado por RequirePermission
+func PermisosScopes(scopes string) map[string]bool {
+	permisos := map[string]bool{}
+	for _, scope := range strings.Split(scopes, ",") {
+		for _, permiso := range ScopesApiKey[scope] {
+			permisos[permiso] = true
+		}
+	}
+	return permisos
+}
@@ -0,0 +1,321 @@
+package services
+
+import (
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+	"v1_prefabricadas/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Acciones registradas en la auditoría
+const (
+	AccionAuditoriaCrear     = "create"
+	AccionAuditoriaEditar    = "update"
+	AccionAuditoriaEliminar  = "delete"
+	AccionAuditoriaRestaurar = "restore"
+)
+
+// Solo se auditan las escrituras hechas desde el panel de administración
+const prefijoRutaAuditada = "/administracion"
+
+const claveAuditoriaAntes = "auditoria:antes"
+
+// Tablas internas que no se auditan
+var tablasSinAuditoria = map[string]bool{
+	"auditorias":           true,
+	"sesiones":             true,
+	"bloqueos_acceso":      true,
+	"codigos_recuperacion": true,
+	"historial_passwords":  true,
+	"invitaciones":         true,
+	"papelera":             true,
+	"importaciones":        true,
+}
+
+// Columnas cuyo valor nunca se guarda, solo se indica que cambió
+var columnasSensibles = map[string]bool{
+	"password":     true,
+	"totp_secreto": true,
+	"key_hash":     true,
+}
+
+// RegistrarCallbacksAuditoria engancha la auditoría en las operaciones de escritura de GORM.
+// Las escrituras deben hacerse con db.WithContext(c) para conocer el usuario y la ruta.
+func RegistrarCallbacksAuditoria(db *gorm.DB) error {
+	callbacks := db.Callback()
+
+	if err := callbacks.Update().Before("gorm:update").Register("auditoria:antes_update", capturarAntes); err != nil {
+		return err
+	}
+	if err := callbacks.Delete().Before("gorm:delete").Register("auditoria:antes_delete", capturarAntes); err != nil {
+		return err
+	}
+	if err := callbacks.Create().After("gorm:create").Register("auditoria:create", registrarCreacion); err != nil {
+		return err
+	}
+	if err := callbacks.Update().After("gorm:update").Register("auditoria:update", registrarActualizacion); err != nil {
+		return err
+	}
+	return callbacks.Delete().After("gorm:delete").Register("auditoria:delete", registrarEliminacion)
+}
+
+// contextoAuditoria devuelve el contexto de gin si la escritura debe auditarse
+func contextoAuditoria(db *gorm.DB) (*gin.Context, bool) {
+	if db.Error != nil || db.Statement.Schema == nil || tablasSinAuditoria[db.Statement.Table] {
+		return nil, false
+	}
+	if db.Statement.Schema.PrioritizedPrimaryField == nil {
+		return nil, false // Tablas intermedias sin clave primaria propia
+	}
+
+	c, ok := db.Statement.Context.(*gin.Context)
+	if !ok || !strings.HasPrefix(c.FullPath(), prefijoRutaAuditada) {
+		return nil, false
+	}
+	_, esUsuario := c.Get("usuarioID")
+	_, esApiKey := c.Get("apiKeyID")
+	if !esUsuario && !esApiKey {
+		return nil, false
+	}
+	return c, true
+}
+
+// instantanea extrae los valores de las columnas de un registro
+func instantanea(db *gorm.DB, valor reflect.Value) map[string]interface{} {
+	datos := map[string]interface{}{}
+	for _, field := range db.Statement.Schema.Fields {
+		if field.DBName == "" {
+			continue
+		}
+		v, _ := field.ValueOf(db.Statement.Context, valor)
+		datos[field.DBName] = v
+	}
+	return datos
+}
+
+// registros devuelve cada struct afectado por la sentencia (soporta slices)
+func registros(db *gorm.DB) []reflect.Value {
+	rv := db.Statement.ReflectValue
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		var lista []reflect.Value
+		for i := 0; i < rv.Len(); i++ {
+			lista = append(lista, reflect.Indirect(rv.Index(i)))
+		}
+		return lista
+	case reflect.Struct:
+		return []reflect.Value{rv}
+	}
+	return nil
+}
+
+// clavePrimaria devuelve el ID del registro, 0 si no tiene
+func clavePrimaria(db *gorm.DB, valor reflect.Value) uint {
+	v, zero := db.Statement.Schema.PrioritizedPrimaryField.ValueOf(db.Statement.Context, valor)
+	if zero {
+		return 0
+	}
+	id, _ := strconv.ParseUint(strings.TrimSpace(toString(v)), 10, 64)
+	return uint(id)
+}
+
+func toString(v interface{}) string {
+	b, _ := json.Marshal(v)
+	return strings.Trim(string(b), `"`)
+}
+
+// capturarAntes carga el registro tal como está en la base de datos antes de modificarlo
+func capturarAntes(db *gorm.DB) {
+	if _, ok := contextoAuditoria(db); !ok {
+		return
+	}
+
+	lista := registros(db)
+	if len(lista) != 1 {
+		return
+	}
+	id := clavePrimaria(db, lista[0])
+	if id == 0 {
+		return // Actualizaciones masivas sin clave primaria no se auditan
+	}
+
+	anterior := reflect.New(db.Statement.Schema.ModelType)
+	if err := db.Session(&gorm.Session{NewDB: true}).
+		Table(db.Statement.Table).
+		Where(db.Statement.Schema.PrioritizedPrimaryField.DBName+" = ?", id).
+		Take(anterior.Interface()).Error; err != nil {
+		return
+	}
+
+	db.InstanceSet(claveAuditoriaAntes, instantanea(db, anterior.Elem()))
+}
+
+func registrarCreacion(db *gorm.DB) {
+	c, ok := contextoAuditoria(db)
+	if !ok {
+		return
+	}
+
+	// Las asociaciones que GORM vuelve a guardar al hacer Save usan ON CONFLICT y no son altas nuevas
+	if _, upsert := db.Statement.Clauses["ON CONFLICT"]; upsert {
+		return
+	}
+
+	for _, valor := range registros(db) {
+		guardarAuditoria(db, c, AccionAuditoriaCrear, clavePrimaria(db, valor), nil, instantanea(db, valor))
+	}
+}
+
+func registrarActualizacion(db *gorm.DB) {
+	c, ok := contextoAuditoria(db)
+	if !ok {
+		return
+	}
+
+	anteriorRaw, ok := db.InstanceGet(claveAuditoriaAntes)
+	if !ok {
+		return
+	}
+	antes := anteriorRaw.(map[string]interface{})
+
+	lista := registros(db)
+	if len(lista) != 1 {
+		return
+	}
+	despues := instantanea(db, lista[0])
+
+	antesCambios, despuesCambios := diferencias(antes, despues)
+	if len(despuesCambios) == 0 {
+		return
+	}
+
+	// Las eliminaciones y restauraciones lógicas se hacen modificando deleted_at
+	accion := AccionAuditoriaEditar
+	if _, cambioDeletedAt := despuesCambios["deleted_at"]; cambioDeletedAt {
+		if esNulo(despues["deleted_at"]) {
+			accion = AccionAuditoriaRestaurar
+		} else {
+			accion = AccionAuditoriaEliminar
+		}
+	}
+
+	guardarAuditoria(db, c, accion, clavePrimaria(db, lista[0]), antesCambios, despuesCambios)
+}
+
+func registrarEliminacion(db *gorm.DB) {
+	c, ok := contextoAuditoria(db)
+	if !ok {
+		return
+	}
+
+	anteriorRaw, ok := db.InstanceGet(claveAuditoriaAntes)
+	if !ok {
+		return
+	}
+	antes := anteriorRaw.(map[string]interface{})
+
+	lista := registros(db)
+	if len(lista) != 1 {
+		return
+	}
+	guardarAuditoria(db, c, AccionAuditoriaEliminar, clavePrimaria(db, lista[0]), antes, nil)
+}
+
+// diferencias devuelve solo los campos que cambiaron, ignorando updated_at
+func diferencias(antes, despues map[string]interface{}) (map[string]interface{}, map[string]interface{}) {
+	antesCambios := map[string]interface{}{}
+	despuesCambios := map[string]interface{}{}
+	for campo, nuevo := range despues {
+		if campo == "updated_at" {
+			continue
+		}
+		viejo := antes[campo]
+		if toString(viejo) != toString(nuevo) {
+			antesCambios[campo] = viejo
+			despuesCambios[campo] = nuevo
+		}
+	}
+	return antesCambios, despuesCambios
+}
+
+func esNulo(v interface{}) bool {
+	if v == nil {
+		return true
+	}
+	rv := reflect.ValueOf(v)
+	return rv.Kind() == reflect.Ptr && rv.IsNil()
+}
+
+// serializar convierte los valores a JSON ocultando las columnas sensibles
+func serializar(datos map[string]interface{}) *string {
+	if datos == nil {
+		return nil
+	}
+	for campo := range datos {
+		if columnasSensibles[campo] {
+			datos[campo] = "********"
+		}
+	}
+	b, err := json.Marshal(datos)
+	if err != nil {
+		return nil
+	}
+	s := string(b)
+	return &s
+}
+
+func guardarAuditoria(db *gorm.DB, c *gin.Context, accion string, entidadID uint, antes, despues map[string]interface{}) {
+	// Se guarda en la misma conexión/transacción que la escritura auditada
+	tx := db.Session(&gorm.Session{NewDB: true})
+	if err := RegistrarAuditoria(tx, c, db.Statement.Table, entidadID, accion, antes, despues); err != nil {
+		db.AddError(err)
+	}
+}
+
+// RegistrarAuditoria guarda manualmente una entrada de auditoría, para cambios que no pasan
+// por los callbacks (por ejemplo, tablas intermedias de relaciones muchos a muchos)
+func RegistrarAuditoria(db *gorm.DB, c *gin.Context, entidad string, entidadID uint, accion string, antes, despues map[string]interface{}) error {
+	auditoria := models.Auditoria{
+		UsuarioID: c.GetUint("usuarioID"),
+		ApiKeyID:  apiKeyAuditoria(c),
+		Entidad:   entidad,
+		EntidadID: entidadID,
+		Accion:    accion,
+		Antes:     serializar(antes),
+		Despues:   serializar(despues),
+		Ruta:      c.Request.Method + " " + c.FullPath(),
+	}
+
+	// Empresa del registro o, en su defecto, la de la ruta o la del usuario
+	if empresaID := empresaAuditoria(c, antes, despues); empresaID != 0 {
+		auditoria.EmpresaID = &empresaID
+	}
+
+	return db.Create(&auditoria).Error
+}
+
+// apiKeyAuditoria devuelve la API key que hizo el cambio, si no lo hizo un usuario
+func apiKeyAuditoria(c *gin.Context) *uint {
+	if id := c.GetUint("apiKeyID"); id != 0 {
+		return &id
+	}
+	return nil
+}
+
+func empresaAuditoria(c *gin.Context, antes, despues map[string]interface{}) uint {
+	for _, datos := range []map[string]interface{}{despues, antes} {
+		if v, ok := datos["empresa_id"]; ok {
+			if id, err := strconv.ParseUint(toString(v), 10, 64); err == nil && id != 0 {
+				return uint(id)
+			}
+		}
+	}
+	if id, err := strconv.ParseUint(c.Param("empresaID"), 10, 64); err == nil {
+		return uint(id)
+	}
+	return c.GetUint("empresaID")
+}
@@ -0,0 +1,126 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"time"
+	"v1_prefabricadas/configs"
+	"v1_prefabricadas/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Acciones y tipos de clave que se controlan contra fuerza bruta
+const (
+	AccionLogin        = "login"
+	AccionRecuperacion = "recuperacion"
+	AccionDosFactores  = "2fa"
+	TipoBloqueoEmail   = "email"
+	TipoBloqueoIP      = "ip"
+)
+
+// PoliticaBloqueo define cuántos intentos se permiten antes de aplicar demoras y bloqueos
+type PoliticaBloqueo struct {
+	IntentosSinDemora int           // Intentos permitidos antes de aplicar demoras progresivas
+	MaxIntentos       int           // Intentos que provocan un bloqueo temporal
+	DuracionBloqueo   time.Duration // Tiempo que dura el bloqueo
+	Ventana           time.Duration // Tiempo sin intentos tras el cual se reinicia el contador
+}
+
+var politicas = map[string]PoliticaBloqueo{
+	AccionLogin + ":" + TipoBloqueoEmail:        {IntentosSinDemora: 3, MaxIntentos: 5, DuracionBloqueo: 15 * time.Minute, Ventana: 15 * time.Minute},
+	AccionLogin + ":" + TipoBloqueoIP:           {IntentosSinDemora: 10, MaxIntentos: 20, DuracionBloqueo: 15 * time.Minute, Ventana: 15 * time.Minute},
+	AccionDosFactores + ":" + TipoBloqueoEmail:  {IntentosSinDemora: 3, MaxIntentos: 5, DuracionBloqueo: 15 * time.Minute, Ventana: 15 * time.Minute},
+	AccionDosFactores + ":" + TipoBloqueoIP:     {IntentosSinDemora: 10, MaxIntentos: 20, DuracionBloqueo: 15 * time.Minute, Ventana: 15 * time.Minute},
+	AccionRecuperacion + ":" + TipoBloqueoEmail: {IntentosSinDemora: 3, MaxIntentos: 3, DuracionBloqueo: time.Hour, Ventana: time.Hour},
+	AccionRecuperacion + ":" + TipoBloqueoIP:    {IntentosSinDemora: 10, MaxIntentos: 10, DuracionBloqueo: time.Hour, Ventana: time.Hour},
+}
+
+func normalizarValorBloqueo(tipo, valor string) string {
+	if tipo == TipoBloqueoEmail {
+		return strings.ToLower(strings.TrimSpace(valor))
+	}
+	return valor
+}
+
+// TiempoEsperaAcceso devuelve cuánto debe esperar el cliente antes de un nuevo intento (0 si puede continuar)
+func TiempoEsperaAcceso(accion, tipo, valor string) (time.Duration, error) {
+	politica, ok := politicas[accion+":"+tipo]
+	if !ok || valor == "" {
+		return 0, nil
+	}
+
+	var bloqueo models.BloqueoAcceso
+	err := configs.DB.Where("accion = ? AND tipo = ? AND valor = ?", accion, tipo, normalizarValorBloqueo(tipo, valor)).First(&bloqueo).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	now := time.Now()
+
+	// Bloqueo temporal vigente
+	if bloqueo.BloqueadoHasta != nil && bloqueo.BloqueadoHasta.After(now) {
+		return bloqueo.BloqueadoHasta.Sub(now), nil
+	}
+
+	// Contador vencido, no se aplica demora
+	if now.Sub(bloqueo.UltimoIntentoAt) > politica.Ventana {
+		return 0, nil
+	}
+
+	// Demora progresiva: 1s, 2s, 4s... a partir de los intentos permitidos sin demora
+	if exceso := bloqueo.IntentosFallidos - politica.IntentosSinDemora; exceso >= 0 {
+		demora := time.Second << uint(exceso)
+		if siguiente := bloqueo.UltimoIntentoAt.Add(demora); siguiente.After(now) {
+			return siguiente.Sub(now), nil
+		}
+	}
+
+	return 0, nil
+}
+
+// RegistrarIntentoFallido incrementa el contador y bloquea temporalmente la clave al superar el máximo
+func RegistrarIntentoFallido(accion, tipo, valor string) error {
+	politica, ok := politicas[accion+":"+tipo]
+	if !ok || valor == "" {
+		return nil
+	}
+	valor = normalizarValorBloqueo(tipo, valor)
+
+	return configs.DB.Transaction(func(tx *gorm.DB) error {
+		var bloqueo models.BloqueoAcceso
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where(models.BloqueoAcceso{Accion: accion, Tipo: tipo, Valor: valor}).
+			FirstOrCreate(&bloqueo).Error; err != nil {
+			return err
+		}
+
+		now := time.Now()
+
+		// Reiniciar el contador si el último intento quedó fuera de la ventana o ya terminó un bloqueo
+		bloqueoTerminado := bloqueo.BloqueadoHasta != nil && !bloqueo.BloqueadoHasta.After(now)
+		if now.Sub(bloqueo.UltimoIntentoAt) > politica.Ventana || bloqueoTerminado {
+			bloqueo.IntentosFallidos = 0
+			bloqueo.BloqueadoHasta = nil
+		}
+
+		bloqueo.IntentosFallidos++
+		bloqueo.UltimoIntentoAt = now
+		if bloqueo.IntentosFallidos >= politica.MaxIntentos {
+			hasta := now.Add(politica.DuracionBloqueo)
+			bloqueo.BloqueadoHasta = &hasta
+		}
+
+		return tx.Save(&bloqueo).Error
+	})
+}
+
+// LimpiarIntentos reinicia el contador de una clave tras un acceso exitoso
+func LimpiarIntentos(accion, tipo, valor string) error {
+	return configs.DB.Where("accion = ? AND tipo = ? AND valor = ?", accion, tipo, normalizarValorBloqueo(tipo, valor)).
+		Delete(&models.BloqueoAcceso{}).Error
+}
@@ -0,0 +1,122 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"unicode/utf8"
+	"v1_prefabricadas/configs"
+	"v1_prefabricadas/utils"
+
+	"gorm.io/gorm"
+)
+
+const (
+	largoMinimoTermino  = 3  // Igual a innodb_ft_min_token_size (3 por defecto), las palabras más cortas no están en el índice
+	maximoTerminos      = 10 // Cantidad máxima de palabras consideradas por búsqueda
+	largoMaximoConsulta = 200
+)
+
+var ErrBusquedaVacia = errors.New("la búsqueda debe contener al menos una palabra de 3 o más letras")
+
+// Resultado de la búsqueda: ID de la prefabricada y su relevancia
+type ResultadoBusqueda struct {
+	ID         uint    `gorm:"column:id"`
+	Relevancia float64 `gorm:"column:relevancia"`
+}
+
+// Función para obtener los términos normalizados (minúsculas y sin tildes) de una búsqueda
+func TerminosBusqueda(consulta string) []string {
+	if utf8.RuneCountInString(consulta) > largoMaximoConsulta {
+		consulta = string([]rune(consulta)[:largoMaximoConsulta])
+	}
+
+	var terminos []string
+	vistos := map[string]bool{}
+	for _, palabra := range utils.PalabrasNormalizadas(consulta) {
+		if utf8.RuneCountInString(palabra) < largoMinimoTermino || vistos[palabra] {
+			continue
+		}
+		vistos[palabra] = true
+		terminos = append(terminos, palabra)
+		if len(terminos) == maximoTerminos {
+			break
+		}
+	}
+	return terminos
+}
+
+// Expresión en modo booleano de MySQL: cada término es opcional y se busca como prefijo
+// ("mediterr" encuentra "mediterránea"). Las tildes las ignora la intercalación de las columnas.
+func expresionFullText(terminos []string) string {
+	partes := make([]string, len(terminos))
+	for i, termino := range terminos {
+		partes[i] = termino + "*"
+	}
+	return strings.Join(partes, " ")
+}
+
+// Función para buscar prefabricadas de una empresa usando los índices FULLTEXT de nombre, eslogan,
+// descripción, características, estilo y tipo. Devuelve los resultados de la página ordenados por
+// relevancia y el total de coincidencias. soloPublicadas limita la búsqueda a las visibles públicamente.
+func BuscarPrefabricadas(empresaID uint, terminos []string, soloPublicadas bool, limit, offset int) ([]ResultadoBusqueda, int64, error) {
+	if len(terminos) == 0 {
+		return nil, 0, ErrBusquedaVacia
+	}
+	expresion := expresionFullText(terminos)
+
+	// El nombre pesa más que el resto de los campos
+	relevancia := `
+		MATCH(prefabricadas.nombre_prefabricada) AGAINST(? IN BOOLEAN MODE) * 3 +
+		MATCH(prefabricadas.nombre_prefabricada, prefabricadas.eslogan, prefabricadas.descripcion) AGAINST(? IN BOOLEAN MODE) +
+		COALESCE(MATCH(estilos.nombre_estilo) AGAINST(? IN BOOLEAN MODE), 0) * 2 +
+		COALESCE(MATCH(tipos.material_estructura) AGAINST(? IN BOOLEAN MODE), 0) * 2 +
+		COALESCE((
+			SELECT SUM(MATCH(caracteristicas.clave, caracteristicas.valor) AGAINST(? IN BOOLEAN MODE))
+			FROM caracteristicas
+			WHERE caracteristicas.prefabricada_id = prefabricadas.id AND caracteristicas.deleted_at IS NULL
+		), 0)`
+
+	// Candidatas: cada índice FULLTEXT se consulta en el WHERE de su propia subconsulta para que MySQL lo use,
+	// en lugar de calcular la relevancia de todo el catálogo
+	candidatas := configs.DB.Raw(`
+		SELECT id FROM prefabricadas
+		WHERE MATCH(nombre_prefabricada, eslogan, descripcion) AGAINST(? IN BOOLEAN MODE)
+		UNION
+		SELECT prefabricadas.id FROM prefabricadas
+		JOIN estilos ON estilos.id = prefabricadas.estilo_id AND estilos.deleted_at IS NULL
+		WHERE MATCH(estilos.nombre_estilo) AGAINST(? IN BOOLEAN MODE)
+		UNION
+		SELECT prefabricadas.id FROM prefabricadas
+		JOIN tipos ON tipos.id = prefabricadas.tipo_id AND tipos.deleted_at IS NULL
+		WHERE MATCH(tipos.material_estructura) AGAINST(? IN BOOLEAN MODE)
+		UNION
+		SELECT prefabricada_id FROM caracteristicas
+		WHERE caracteristicas.deleted_at IS NULL AND MATCH(clave, valor) AGAINST(? IN BOOLEAN MODE)`,
+		expresion, expresion, expresion, expresion)
+
+	coincidencias := configs.DB.Table("prefabricadas").
+		Joins("LEFT JOIN estilos ON estilos.id = prefabricadas.estilo_id AND estilos.deleted_at IS NULL").
+		Joins("LEFT JOIN tipos ON tipos.id = prefabricadas.tipo_id AND tipos.deleted_at IS NULL").
+		Where("prefabricadas.id IN (?)", candidatas).
+		Where("prefabricadas.empresa_id = ?", empresaID).
+		Where("prefabricadas.deleted_at IS NULL")
+	if soloPublicadas {
+		coincidencias = FiltrarPublicados(coincidencias, "prefabricadas")
+	}
+
+	var total int64
+	if err := coincidencias.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var resultados []ResultadoBusqueda
+	if err := coincidencias.
+		Select("prefabricadas.id, ("+relevancia+") AS relevancia", expresion, expresion, expresion, expresion, expresion).
+		Order("relevancia DESC, prefabricadas.id DESC").
+		Limit(limit).Offset(offset).
+		Find(&resultados).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return resultados, total, nil
+}
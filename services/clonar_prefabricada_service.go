@@ -0,0 +1,82 @@
+package services
+
+import (
+	"v1_prefabricadas/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Datos propios de la copia de una Prefabricada; el resto se copia de la original
+type ClonPrefabricada struct {
+	NombrePrefabricada string
+	M2                 int
+	Slug               string
+	Imagenes           map[uint]string // URL de la copia en S3 por ID de la imagen original (si falta se reutiliza la original)
+}
+
+// Función para copiar una Prefabricada con sus imágenes, características, precios e incluyes no eliminados.
+// La original debe venir con sus relaciones precargadas. La copia se crea como borrador para revisarla
+// antes de publicarla. Se recomienda llamarla dentro de una transacción.
+func ClonarPrefabricada(db *gorm.DB, original models.Prefabricada, datos ClonPrefabricada) (models.Prefabricada, error) {
+	clon := models.Prefabricada{
+		Slug:               &datos.Slug,
+		NombrePrefabricada: datos.NombrePrefabricada,
+		M2:                 datos.M2,
+		Garantia:           original.Garantia,
+		Eslogan:            original.Eslogan,
+		Descripcion:        original.Descripcion,
+		Destacada:          original.Destacada,
+		Oferta:             original.Oferta,
+		Estado:             EstadoBorrador,
+		CategoriaID:        original.CategoriaID,
+		EmpresaID:          original.EmpresaID,
+		EstiloID:           original.EstiloID,
+		TipoID:             original.TipoID,
+	}
+	if err := db.Omit(clause.Associations).Create(&clon).Error; err != nil {
+		return clon, err
+	}
+
+	for _, imagen := range original.Imagen_prefabricada {
+		copia := models.Imagen_prefabricada{Image: imagen.Image, PrefabricadaID: clon.ID}
+		if url, ok := datos.Imagenes[imagen.ID]; ok {
+			copia.Image = url
+		}
+		if err := db.Omit(clause.Associations).Create(&copia).Error; err != nil {
+			return clon, err
+		}
+		clon.Imagen_prefabricada = append(clon.Imagen_prefabricada, copia)
+	}
+
+	for _, caracteristica := range original.Caracteristica {
+		copia := models.Caracteristica{Clave: caracteristica.Clave, Valor: caracteristica.Valor, PrefabricadaID: clon.ID}
+		if err := db.Omit(clause.Associations).Create(&copia).Error; err != nil {
+			return clon, err
+		}
+		clon.Caracteristica = append(clon.Caracteristica, copia)
+	}
+
+	for _, precio := range original.Precio {
+		copia := models.Precio{
+			NombrePrecio:      precio.NombrePrecio,
+			DescripcionPrecio: precio.DescripcionPrecio,
+			ValorPrefabricada: precio.ValorPrefabricada,
+			PrefabricadaID:    clon.ID,
+		}
+		if err := db.Omit(clause.Associations).Create(&copia).Error; err != nil {
+			return clon, err
+		}
+
+		for _, incluye := range precio.Incluye {
+			copiaIncluye := models.Incluye{NombreIncluye: incluye.NombreIncluye, PrecioID: copia.ID}
+			if err := db.Omit(clause.Associations).Create(&copiaIncluye).Error; err != nil {
+				return clon, err
+			}
+			copia.Incluye = append(copia.Incluye, copiaIncluye)
+		}
+		clon.Precio = append(clon.Precio, copia)
+	}
+
+	return clon, nil
+}
@@ -0,0 +1,51 @@
+package services
+
+import (
+	"errors"
+	"strconv"
+	"v1_prefabricadas/configs"
+	"v1_prefabricadas/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Claves de configuración del sistema
+const (
+	ConfigDosFactoresSuperAdmin = "seguridad.2fa_obligatorio_super_administrador"
+	ConfigDiasRetencionPapelera = "papelera.dias_retencion"
+)
+
+// ObtenerConfiguracion devuelve el valor de una clave o el valor por defecto si no existe
+func ObtenerConfiguracion(clave, porDefecto string) (string, error) {
+	var configuracion models.Configuracion
+	err := configs.DB.Where("clave = ?", clave).First(&configuracion).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return porDefecto, nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return configuracion.Valor, nil
+}
+
+// ObtenerConfiguracionBool interpreta el valor de una clave como booleano
+func ObtenerConfiguracionBool(clave string, porDefecto bool) (bool, error) {
+	valor, err := ObtenerConfiguracion(clave, strconv.FormatBool(porDefecto))
+	if err != nil {
+		return false, err
+	}
+	b, err := strconv.ParseBool(valor)
+	if err != nil {
+		return porDefecto, nil
+	}
+	return b, nil
+}
+
+// GuardarConfiguracion crea o actualiza el valor de una clave
+func GuardarConfiguracion(clave, valor string) error {
+	return configs.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "clave"}},
+		DoUpdates: clause.AssignmentColumns([]string{"valor", "updated_at"}),
+	}).Create(&models.Configuracion{Clave: clave, Valor: valor}).Error
+}
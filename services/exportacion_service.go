@@ -0,0 +1,54 @@
+package services
+
+import (
+	"strings"
+	"v1_prefabricadas/models"
+)
+
+// Función para obtener las filas de la hoja del catálogo de una prefabricada (con sus características,
+// precios e incluyes precargados), con los valores en el orden de ColumnasCatalogo. Se genera una fila
+// por precio, o una fila sin precio si la prefabricada no tiene precios, de modo que la hoja exportada
+// se pueda volver a importar.
+func FilasCatalogo(prefabricada models.Prefabricada) [][]interface{} {
+	slug := ""
+	if prefabricada.Slug != nil {
+		slug = *prefabricada.Slug
+	}
+
+	caracteristicas := make([]string, 0, len(prefabricada.Caracteristica))
+	for _, caracteristica := range prefabricada.Caracteristica {
+		caracteristicas = append(caracteristicas, caracteristica.Clave+SeparadorCaracteristicaCatalogo+" "+caracteristica.Valor)
+	}
+
+	datos := []interface{}{
+		slug, prefabricada.NombrePrefabricada, prefabricada.M2, prefabricada.Garantia, prefabricada.Eslogan,
+		prefabricada.Descripcion, siNoCatalogo(prefabricada.Destacada), siNoCatalogo(prefabricada.Oferta),
+		prefabricada.CategoriaID, prefabricada.EstiloID, prefabricada.TipoID, prefabricada.Estado,
+		strings.Join(caracteristicas, SeparadorListaCatalogo+" "),
+	}
+
+	if len(prefabricada.Precio) == 0 {
+		return [][]interface{}{append(datos, "", "", nil, "")}
+	}
+
+	filas := make([][]interface{}, 0, len(prefabricada.Precio))
+	for _, precio := range prefabricada.Precio {
+		incluyes := make([]string, 0, len(precio.Incluye))
+		for _, incluye := range precio.Incluye {
+			incluyes = append(incluyes, incluye.NombreIncluye)
+		}
+
+		fila := append(append([]interface{}{}, datos...),
+			precio.NombrePrecio, precio.DescripcionPrecio, precio.ValorPrefabricada,
+			strings.Join(incluyes, SeparadorListaCatalogo+" "))
+		filas = append(filas, fila)
+	}
+	return filas
+}
+
+func siNoCatalogo(valor bool) string {
+	if valor {
+		return "sí"
+	}
+	return "no"
+}
@@ -0,0 +1,211 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"v1_prefabricadas/configs"
+
+	"gorm.io/gorm"
+)
+
+// Rangos por defecto de las facetas de superficie (m2) y precio
+const (
+	RangosM2PorDefecto     = "0-50,50-100,100-150,150-"
+	RangosPrecioPorDefecto = "0-20000000,20000000-40000000,40000000-60000000,60000000-"
+)
+
+// Rango de una faceta: desde (inclusive) hasta (exclusivo). Hasta nil indica un rango abierto
+type RangoFaceta struct {
+	Desde float64
+	Hasta *float64
+}
+
+// Opción de una faceta por taxonomía (categoría, estilo o tipo) con su cantidad de prefabricadas
+type OpcionFaceta struct {
+	ID       uint   `gorm:"column:id"`
+	Nombre   string `gorm:"column:nombre"`
+	Cantidad int64  `gorm:"column:cantidad"`
+}
+
+// Cantidad de prefabricadas dentro de un rango
+type CantidadRango struct {
+	Rango    RangoFaceta
+	Cantidad int64
+}
+
+// Cantidades de prefabricadas para cada opción de filtro del catálogo
+type FacetasPrefabricadas struct {
+	Total      int64
+	Categorias []OpcionFaceta
+	Estilos    []OpcionFaceta
+	Tipos      []OpcionFaceta
+	Oferta     map[bool]int64
+	Destacada  map[bool]int64
+	M2         []CantidadRango
+	Precio     []CantidadRango
+}
+
+// Función para interpretar rangos con el formato "0-50,50-100,100-" (el último sin límite superior)
+func ParsearRangosFaceta(texto, parametro string) ([]RangoFaceta, error) {
+	var rangos []RangoFaceta
+	for _, parte := range strings.Split(texto, ",") {
+		parte = strings.TrimSpace(parte)
+		if parte == "" {
+			continue
+		}
+		limites := strings.SplitN(parte, "-", 2)
+		if len(limites) != 2 {
+			return nil, &ErrFiltroInvalido{parametro, "use el formato desde-hasta separado por coma, ej. 0-50,50-100,100-"}
+		}
+
+		desde, ok := numeroFinito(strings.TrimSpace(limites[0]))
+		if !ok || desde < 0 {
+			return nil, &ErrFiltroInvalido{parametro, "el rango " + parte + " no es válido"}
+		}
+		rango := RangoFaceta{Desde: desde}
+		if h := strings.TrimSpace(limites[1]); h != "" {
+			hasta, ok := numeroFinito(h)
+			if !ok || hasta <= desde {
+				return nil, &ErrFiltroInvalido{parametro, "el rango " + parte + " no es válido"}
+			}
+			rango.Hasta = &hasta
+		}
+		rangos = append(rangos, rango)
+	}
+
+	if len(rangos) == 0 || len(rangos) > 20 {
+		return nil, &ErrFiltroInvalido{parametro, "debe indicar entre 1 y 20 rangos"}
+	}
+	return rangos, nil
+}
+
+// Función para calcular las facetas del catálogo de una empresa. La cantidad de cada opción se calcula
+// aplicando todos los filtros activos excepto el de la propia faceta, para que el usuario vea cuántas
+// prefabricadas obtendría al cambiar o agregar esa opción.
+func ObtenerFacetasPrefabricadas(empresaID uint, filtros FiltrosPrefabricadas, rangosM2, rangosPrecio []RangoFaceta) (FacetasPrefabricadas, error) {
+	var facetas FacetasPrefabricadas
+	var err error
+
+	base := func(filtros FiltrosPrefabricadas) *gorm.DB {
+		query := configs.DB.Table("prefabricadas").
+			Where("prefabricadas.empresa_id = ?", empresaID).
+			Where("prefabricadas.deleted_at IS NULL")
+		return AplicarFiltrosPrefabricadas(query, filtros)
+	}
+
+	if err := base(filtros).Count(&facetas.Total).Error; err != nil {
+		return facetas, err
+	}
+
+	sinCategoria := filtros
+	sinCategoria.CategoriaIDs = nil
+	if facetas.Categorias, err = facetaTaxonomia(base(sinCategoria), "categorias", "nombre_categoria", "categoria_id"); err != nil {
+		return facetas, err
+	}
+
+	sinEstilo := filtros
+	sinEstilo.EstiloIDs = nil
+	if facetas.Estilos, err = facetaTaxonomia(base(sinEstilo), "estilos", "nombre_estilo", "estilo_id"); err != nil {
+		return facetas, err
+	}
+
+	sinTipo := filtros
+	sinTipo.TipoIDs = nil
+	if facetas.Tipos, err = facetaTaxonomia(base(sinTipo), "tipos", "material_estructura", "tipo_id"); err != nil {
+		return facetas, err
+	}
+
+	sinOferta := filtros
+	sinOferta.Oferta = nil
+	if facetas.Oferta, err = facetaBooleana(base(sinOferta), "oferta"); err != nil {
+		return facetas, err
+	}
+
+	sinDestacada := filtros
+	sinDestacada.Destacada = nil
+	if facetas.Destacada, err = facetaBooleana(base(sinDestacada), "destacada"); err != nil {
+		return facetas, err
+	}
+
+	sinM2 := filtros
+	sinM2.M2Min, sinM2.M2Max = nil, nil
+	if facetas.M2, err = facetaRangos(base(sinM2).Select("prefabricadas.m2 AS valor"), rangosM2); err != nil {
+		return facetas, err
+	}
+
+	sinPrecio := filtros
+	sinPrecio.PrecioMin, sinPrecio.PrecioMax = nil, nil
+	if facetas.Precio, err = facetaRangos(base(sinPrecio).Select(precioMinimoPrefabricada+" AS valor"), rangosPrecio); err != nil {
+		return facetas, err
+	}
+
+	return facetas, nil
+}
+
+// Cantidad por cada opción no eliminada de la taxonomía (incluye las opciones sin prefabricadas)
+func facetaTaxonomia(query *gorm.DB, tabla, columnaNombre, columnaFK string) ([]OpcionFaceta, error) {
+	cantidades := query.
+		Select("prefabricadas." + columnaFK + " AS id, COUNT(*) AS cantidad").
+		Group("prefabricadas." + columnaFK)
+
+	var opciones []OpcionFaceta
+	err := configs.DB.Table(tabla).
+		Select(tabla+".id, "+tabla+"."+columnaNombre+" AS nombre, COALESCE(cantidades.cantidad, 0) AS cantidad").
+		Joins("LEFT JOIN (?) AS cantidades ON cantidades.id = "+tabla+".id", cantidades).
+		Where(tabla + ".deleted_at IS NULL").
+		Order(tabla + "." + columnaNombre + " ASC").
+		Scan(&opciones).Error
+	return opciones, err
+}
+
+func facetaBooleana(query *gorm.DB, columna string) (map[bool]int64, error) {
+	var filas []struct {
+		Valor    bool  `gorm:"column:valor"`
+		Cantidad int64 `gorm:"column:cantidad"`
+	}
+	if err := query.
+		Select("prefabricadas." + columna + " AS valor, COUNT(*) AS cantidad").
+		Group("prefabricadas." + columna).
+		Scan(&filas).Error; err != nil {
+		return nil, err
+	}
+
+	cantidades := map[bool]int64{true: 0, false: 0}
+	for _, fila := range filas {
+		cantidades[fila.Valor] += fila.Cantidad
+	}
+	return cantidades, nil
+}
+
+// Contar en una sola consulta los valores (columna "valor" de la subconsulta) que caen en cada rango
+func facetaRangos(valores *gorm.DB, rangos []RangoFaceta) ([]CantidadRango, error) {
+	// Los límites de cada rango se envían como parámetros de la consulta
+	columnas := make([]string, len(rangos))
+	var limites []interface{}
+	for i, rango := range rangos {
+		condicion := "valor >= ?"
+		limites = append(limites, rango.Desde)
+		if rango.Hasta != nil {
+			condicion += " AND valor < ?"
+			limites = append(limites, *rango.Hasta)
+		}
+		columnas[i] = fmt.Sprintf("COALESCE(SUM(CASE WHEN %s THEN 1 ELSE 0 END), 0) AS r%d", condicion, i)
+	}
+
+	cantidades := make([]int64, len(rangos))
+	destinos := make([]interface{}, len(rangos))
+	for i := range cantidades {
+		destinos[i] = &cantidades[i]
+	}
+	if err := configs.DB.Table("(?) AS valores", valores).
+		Select(strings.Join(columnas, ", "), limites...).
+		Row().Scan(destinos...); err != nil {
+		return nil, err
+	}
+
+	resultado := make([]CantidadRango, len(rangos))
+	for i, rango := range rangos {
+		resultado[i] = CantidadRango{Rango: rango, Cantidad: cantidades[i]}
+	}
+	return resultado, nil
+}
@@ -0,0 +1,347 @@
+package services
+
+import (
+	"fmt"
+	"image/color"
+	"log"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"v1_prefabricadas/models"
+	"v1_prefabricadas/utils"
+)
+
+const (
+	imagenesFicha           = 3       // Imagen principal y dos secundarias
+	tamanoMaximoImagenFicha = 8 << 20 // 8 MB
+	margenFicha             = 45.0
+	limiteInferiorFicha     = utils.AltoPaginaPDF - 60 // Espacio reservado para el pie de página
+	anchoContenidoFicha     = utils.AnchoPaginaPDF - 2*margenFicha
+)
+
+var (
+	colorPrincipalFicha  = color.RGBA{0x1f, 0x4e, 0x5f, 0xff}
+	colorTextoFicha      = color.RGBA{0x33, 0x33, 0x33, 0xff}
+	colorSecundarioFicha = color.RGBA{0x77, 0x77, 0x77, 0xff}
+	colorFondoFicha      = color.RGBA{0xf1, 0xf3, 0xf4, 0xff}
+)
+
+// Fichas generadas recientemente. Generar una ficha descarga y convierte sus imágenes, así que se
+// reutiliza por unos minutos. Un cambio en la prefabricada o la empresa genera una ficha nueva de
+// inmediato; los cambios en sus imágenes, características o precios se ven al vencer la caché.
+const (
+	duracionCacheFicha = 10 * time.Minute
+	fichasMaximasCache = 100
+)
+
+type fichaEnCache struct {
+	pdf      []byte
+	generada time.Time
+}
+
+var (
+	muFichas    sync.Mutex
+	cacheFichas = map[string]fichaEnCache{}
+)
+
+// Página en construcción de la ficha y la altura hasta la que ya se escribió
+type fichaPDF struct {
+	doc *utils.DocumentoPDF
+	y   float64
+}
+
+// Función para generar la ficha en PDF de una prefabricada (con sus imágenes, características, precios
+// e incluyes precargados) con los datos de contacto y redes de la empresa (con sus redes precargadas).
+// Las imágenes que no se puedan descargar o leer se omiten para no impedir la generación de la ficha.
+func GenerarFichaPrefabricada(prefabricada models.Prefabricada, empresa models.Empresa) ([]byte, error) {
+	f := &fichaPDF{doc: utils.NuevoDocumentoPDF(prefabricada.NombrePrefabricada + " - " + empresa.NombreEmpresa)}
+
+	// Encabezado con los datos de la empresa
+	f.doc.Rectangulo(0, 0, utils.AnchoPaginaPDF, 80, colorPrincipalFicha)
+	f.doc.Texto(margenFicha, 40, 20, true, color.White, empresa.NombreEmpresa)
+	f.doc.Texto(margenFicha, 60, 9, false, color.White, unirNoVacios(" · ", empresa.UbicacionEmpresa, empresa.CelularEmpresa, empresa.EmailEmpresa))
+	f.y = 105
+
+	// Nombre y eslogan
+	f.parrafo(prefabricada.NombrePrefabricada, margenFicha, anchoContenidoFicha, 22, true, colorTextoFicha)
+	if prefabricada.Eslogan != "" {
+		f.parrafo(prefabricada.Eslogan, margenFicha, anchoContenidoFicha, 13, false, colorSecundarioFicha)
+	}
+	f.y += 10
+
+	f.imagenes(imagenesPrincipales(prefabricada.Imagen_prefabricada))
+
+	// Superficie y garantía
+	f.reservar(44)
+	anchoDato := (anchoContenidoFicha - 12) / 2
+	for i, dato := range [][2]string{{"Superficie", strconv.Itoa(prefabricada.M2) + " m²"}, {"Garantía", prefabricada.Garantia}} {
+		x := margenFicha + float64(i)*(anchoDato+12)
+		f.doc.Rectangulo(x, f.y, anchoDato, 40, colorFondoFicha)
+		f.doc.Texto(x+10, f.y+14, 8, false, colorSecundarioFicha, strings.ToUpper(dato[0]))
+		f.doc.Texto(x+10, f.y+31, 13, true, colorTextoFicha, dato[1])
+	}
+	f.y += 52
+
+	if prefabricada.Descripcion != "" {
+		f.seccion("Descripción")
+		f.parrafo(prefabricada.Descripcion, margenFicha, anchoContenidoFicha, 10, false, colorTextoFicha)
+	}
+
+	if len(prefabricada.Caracteristica) > 0 {
+		f.seccion("Características")
+		f.tablaCaracteristicas(prefabricada.Caracteristica)
+	}
+
+	if len(prefabricada.Precio) > 0 {
+		f.seccion("Precios")
+		for _, precio := range prefabricada.Precio {
+			f.precio(precio)
+		}
+	}
+
+	f.contacto(empresa)
+	f.pies(empresa)
+
+	return f.doc.Bytes()
+}
+
+// Función para obtener la ficha de una prefabricada desde la caché, o generarla y guardarla si no está
+// o ya venció. Los mismos datos que GenerarFichaPrefabricada deben venir precargados.
+func FichaPrefabricadaEnCache(prefabricada models.Prefabricada, empresa models.Empresa) ([]byte, error) {
+	clave := fmt.Sprintf("%d-%d-%d", prefabricada.ID, prefabricada.UpdatedAt.UnixNano(), empresa.UpdatedAt.UnixNano())
+	ahora := time.Now()
+
+	muFichas.Lock()
+	ficha, ok := cacheFichas[clave]
+	muFichas.Unlock()
+	if ok && ahora.Sub(ficha.generada) < duracionCacheFicha {
+		return ficha.pdf, nil
+	}
+
+	pdf, err := GenerarFichaPrefabricada(prefabricada, empresa)
+	if err != nil {
+		return nil, err
+	}
+
+	muFichas.Lock()
+	defer muFichas.Unlock()
+	// Liberar espacio: primero las vencidas y, si no alcanza, cualquier otra
+	if len(cacheFichas) >= fichasMaximasCache {
+		for otra, guardada := range cacheFichas {
+			if ahora.Sub(guardada.generada) >= duracionCacheFicha {
+				delete(cacheFichas, otra)
+			}
+		}
+		for otra := range cacheFichas {
+			if len(cacheFichas) < fichasMaximasCache {
+				break
+			}
+			delete(cacheFichas, otra)
+		}
+	}
+	cacheFichas[clave] = fichaEnCache{pdf: pdf, generada: ahora}
+	return pdf, nil
+}
+
+// Pasar a una nueva página si no queda espacio para un bloque del alto indicado
+func (f *fichaPDF) reservar(alto float64) {
+	if f.y+alto > limiteInferiorFicha {
+		f.doc.NuevaPagina()
+		f.y = margenFicha
+	}
+}
+
+// Escribir un texto ajustado al ancho indicado, pasando de página si es necesario
+func (f *fichaPDF) parrafo(texto string, x, ancho, tamano float64, negrita bool, c color.Color) {
+	interlineado := tamano * 1.35
+	for _, linea := range utils.DividirLineasPDF(texto, ancho, tamano, negrita) {
+		f.reservar(interlineado)
+		f.doc.Texto(x, f.y+tamano, tamano, negrita, c, linea)
+		f.y += interlineado
+	}
+}
+
+// Título de sección subrayado. Reserva espacio para que no quede solo al final de una página.
+func (f *fichaPDF) seccion(titulo string) {
+	f.y += 8
+	f.reservar(60)
+	f.doc.Texto(margenFicha, f.y+13, 13, true, colorPrincipalFicha, titulo)
+	f.doc.Linea(margenFicha, f.y+19, margenFicha+anchoContenidoFicha, f.y+19, 1, colorPrincipalFicha)
+	f.y += 27
+}
+
+// La primera imagen ocupa todo el ancho y las siguientes se muestran lado a lado debajo
+func (f *fichaPDF) imagenes(imagenes []utils.ImagenPDF) {
+	if len(imagenes) == 0 {
+		return
+	}
+
+	alto := f.imagenEnCaja(imagenes[0], margenFicha, anchoContenidoFicha, 260)
+	f.y += alto + 12
+
+	if len(imagenes) > 1 {
+		secundarias := imagenes[1:]
+		anchoCaja := (anchoContenidoFicha - 12*float64(len(secundarias)-1)) / float64(len(secundarias))
+		maximo := 0.0
+		for i, imagen := range secundarias {
+			if alto := f.imagenEnCaja(imagen, margenFicha+float64(i)*(anchoCaja+12), anchoCaja, 150); alto > maximo {
+				maximo = alto
+			}
+		}
+		f.y += maximo + 12
+	}
+}
+
+// Dibujar una imagen escalada para caber en la caja, centrada horizontalmente. Devuelve el alto dibujado.
+func (f *fichaPDF) imagenEnCaja(imagen utils.ImagenPDF, x, anchoMaximo, altoMaximo float64) float64 {
+	if imagen.Ancho <= 0 || imagen.Alto <= 0 {
+		return 0
+	}
+	escala := math.Min(anchoMaximo/float64(imagen.Ancho), altoMaximo/float64(imagen.Alto))
+	ancho, alto := float64(imagen.Ancho)*escala, float64(imagen.Alto)*escala
+	f.reservar(alto)
+	f.doc.Imagen(imagen, x+(anchoMaximo-ancho)/2, f.y, ancho, alto)
+	return alto
+}
+
+// Tabla de dos columnas (clave y valor) con filas de fondo alternado
+func (f *fichaPDF) tablaCaracteristicas(caracteristicas []models.Caracteristica) {
+	anchoClave := anchoContenidoFicha * 0.4
+	anchoValor := anchoContenidoFicha - anchoClave
+	for i, caracteristica := range caracteristicas {
+		claves := utils.DividirLineasPDF(caracteristica.Clave, anchoClave-16, 10, true)
+		valores := utils.DividirLineasPDF(caracteristica.Valor, anchoValor-16, 10, false)
+		alto := float64(len(claves))*13.5 + 10
+		if len(valores) > len(claves) {
+			alto = float64(len(valores))*13.5 + 10
+		}
+
+		f.reservar(alto)
+		if i%2 == 0 {
+			f.doc.Rectangulo(margenFicha, f.y, anchoContenidoFicha, alto, colorFondoFicha)
+		}
+		for j, linea := range claves {
+			f.doc.Texto(margenFicha+8, f.y+15+float64(j)*13.5, 10, true, colorTextoFicha, linea)
+		}
+		for j, linea := range valores {
+			f.doc.Texto(margenFicha+anchoClave+8, f.y+15+float64(j)*13.5, 10, false, colorTextoFicha, linea)
+		}
+		f.y += alto
+	}
+}
+
+// Nombre y valor del precio, su descripción y la lista de lo que incluye
+func (f *fichaPDF) precio(precio models.Precio) {
+	f.reservar(50)
+	valor := formatearPrecioFicha(precio.ValorPrefabricada)
+	anchoValor := utils.AnchoTextoPDF(valor, 13, true)
+	f.doc.Rectangulo(margenFicha, f.y, anchoContenidoFicha, 26, colorFondoFicha)
+	f.doc.Texto(margenFicha+8, f.y+17, 12, true, colorTextoFicha, precio.NombrePrecio)
+	f.doc.Texto(margenFicha+anchoContenidoFicha-8-anchoValor, f.y+17, 13, true, colorPrincipalFicha, valor)
+	f.y += 32
+
+	if precio.DescripcionPrecio != "" {
+		f.parrafo(precio.DescripcionPrecio, margenFicha+8, anchoContenidoFicha-16, 10, false, colorSecundarioFicha)
+	}
+	if len(precio.Incluye) > 0 {
+		f.y += 2
+		f.parrafo("Incluye:", margenFicha+8, anchoContenidoFicha-16, 10, true, colorTextoFicha)
+		for _, incluye := range precio.Incluye {
+			f.reservar(13.5)
+			f.doc.Texto(margenFicha+14, f.y+10, 10, false, colorPrincipalFicha, "•")
+			f.parrafo(incluye.NombreIncluye, margenFicha+26, anchoContenidoFicha-34, 10, false, colorTextoFicha)
+		}
+	}
+	f.y += 12
+}
+
+// Datos de contacto y redes sociales de la empresa
+func (f *fichaPDF) contacto(empresa models.Empresa) {
+	var datos [][2]string
+	for _, dato := range [][2]string{{"Dirección", empresa.UbicacionEmpresa}, {"Celular", empresa.CelularEmpresa}, {"Email", empresa.EmailEmpresa}} {
+		if dato[1] != "" {
+			datos = append(datos, dato)
+		}
+	}
+	for _, red := range empresa.Red {
+		datos = append(datos, [2]string{red.RedSocial, red.Link})
+	}
+	if len(datos) == 0 {
+		return
+	}
+
+	f.seccion("Contacto")
+	for _, dato := range datos {
+		f.reservar(14)
+		f.doc.Texto(margenFicha, f.y+10, 10, true, colorTextoFicha, dato[0]+":")
+		f.parrafo(dato[1], margenFicha+90, anchoContenidoFicha-90, 10, false, colorTextoFicha)
+	}
+}
+
+// Pie de cada página con el nombre y contacto de la empresa y el número de página
+func (f *fichaPDF) pies(empresa models.Empresa) {
+	total := f.doc.CantidadPaginas()
+	y := utils.AltoPaginaPDF - 40
+	for i := 0; i < total; i++ {
+		f.doc.IrAPagina(i)
+		f.doc.Linea(margenFicha, y-12, margenFicha+anchoContenidoFicha, y-12, 0.5, colorSecundarioFicha)
+		f.doc.Texto(margenFicha, y, 8, false, colorSecundarioFicha, unirNoVacios(" · ", empresa.NombreEmpresa, empresa.EmailEmpresa, empresa.CelularEmpresa))
+		pagina := fmt.Sprintf("Página %d de %d", i+1, total)
+		f.doc.Texto(margenFicha+anchoContenidoFicha-utils.AnchoTextoPDF(pagina, 8, false), y, 8, false, colorSecundarioFicha, pagina)
+	}
+}
+
+// Descargar y preparar las primeras imágenes de la prefabricada (en el orden en que se subieron)
+func imagenesPrincipales(imagenes []models.Imagen_prefabricada) []utils.ImagenPDF {
+	ordenadas := append([]models.Imagen_prefabricada{}, imagenes...)
+	sort.Slice(ordenadas, func(i, j int) bool { return ordenadas[i].ID < ordenadas[j].ID })
+
+	var resultado []utils.ImagenPDF
+	for _, imagen := range ordenadas {
+		if len(resultado) == imagenesFicha {
+			break
+		}
+		contenido, err := DescargarDeS3(imagen.Image, tamanoMaximoImagenFicha)
+		if err != nil {
+			log.Printf("Ficha: imagen %d omitida: %v", imagen.ID, err)
+			continue
+		}
+		imagenPDF, err := utils.PrepararImagenPDF(contenido)
+		if err != nil {
+			log.Printf("Ficha: imagen %d omitida: %v", imagen.ID, err)
+			continue
+		}
+		resultado = append(resultado, imagenPDF)
+	}
+	return resultado
+}
+
+// Formato de precio en pesos con separador de miles ("$ 12.500.000" o "$ 1.250,50")
+func formatearPrecioFicha(valor float64) string {
+	centavos := int64(math.Round(valor * 100))
+	signo := ""
+	if centavos < 0 {
+		signo, centavos = "-", -centavos
+	}
+	entero := strconv.FormatInt(centavos/100, 10)
+	for i := len(entero) - 3; i > 0; i -= 3 {
+		entero = entero[:i] + "." + entero[i:]
+	}
+	if centavos%100 != 0 {
+		return fmt.Sprintf("$ %s%s,%02d", signo, entero, centavos%100)
+	}
+	return "$ " + signo + entero
+}
+
+func unirNoVacios(separador string, textos ...string) string {
+	var noVacios []string
+	for _, texto := range textos {
+		if texto = strings.TrimSpace(texto); texto != "" {
+			noVacios = append(noVacios, texto)
+		}
+	}
+	return strings.Join(noVacios, separador)
+}
@@ -0,0 +1,292 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// Precio más bajo no eliminado de la prefabricada
+const precioMinimoPrefabricada = `(SELECT MIN(precios.valor_prefabricada) FROM precios
+	WHERE precios.prefabricada_id = prefabricadas.id AND precios.deleted_at IS NULL)`
+
+// Opciones de orden permitidas para el listado de prefabricadas ("-" indica orden descendente)
+var OrdenesPrefabricadas = map[string]string{
+	"price":  precioMinimoPrefabricada + " IS NULL, " + precioMinimoPrefabricada + " ASC",
+	"-price": precioMinimoPrefabricada + " IS NULL, " + precioMinimoPrefabricada + " DESC",
+	"m2":     "prefabricadas.m2 ASC",
+	"-m2":    "prefabricadas.m2 DESC",
+	"newest": "prefabricadas.created_at DESC",
+	"name":   "prefabricadas.nombre_prefabricada ASC",
+	"-name":  "prefabricadas.nombre_prefabricada DESC",
+}
+
+// Ej. "dormitorios>=3" o "terminación=llave en mano"
+var expresionFiltroCaracteristica = regexp.MustCompile(`^\s*([^<>=!]+?)\s*(>=|<=|!=|>|<|=)\s*(.+?)\s*$`)
+
+// Filtro sobre el valor de una característica de la prefabricada
+type FiltroCaracteristica struct {
+	Clave    string
+	Operador string
+	Valor    string
+}
+
+// Filtros del listado de prefabricadas
+type FiltrosPrefabricadas struct {
+	CategoriaIDs    []uint
+	TipoIDs         []uint
+	EstiloIDs       []uint
+	Destacada       *bool
+	Oferta          *bool
+	M2Min           *int
+	M2Max           *int
+	PrecioMin       *float64
+	PrecioMax       *float64
+	Caracteristicas []FiltroCaracteristica
+	Estados         []string // Estados de publicación (draft, scheduled, published, archived)
+	SoloPublicadas  bool     // Rutas públicas: solo las prefabricadas visibles según su publicación
+	Orden           string
+}
+
+// Error de un parámetro de filtro inválido
+type ErrFiltroInvalido struct {
+	Parametro string
+	Motivo    string
+}
+
+func (e *ErrFiltroInvalido) Error() string {
+	return fmt.Sprintf("parámetro %s inválido: %s", e.Parametro, e.Motivo)
+}
+
+// Función para obtener los filtros del listado de prefabricadas desde los parámetros de la URL.
+// Los filtros de lista aceptan valores separados por coma o el parámetro repetido (estilo_id=1,2&estilo_id=3)
+// y los de característica se indican como caracteristica=dormitorios>=3 (repetible).
+func ParsearFiltrosPrefabricadas(valores url.Values) (FiltrosPrefabricadas, error) {
+	var filtros FiltrosPrefabricadas
+	var err error
+
+	if filtros.CategoriaIDs, err = listaIDs(valores, "categoria_id"); err != nil {
+		return filtros, err
+	}
+	if filtros.TipoIDs, err = listaIDs(valores, "tipo_id"); err != nil {
+		return filtros, err
+	}
+	if filtros.EstiloIDs, err = listaIDs(valores, "estilo_id"); err != nil {
+		return filtros, err
+	}
+
+	if filtros.Destacada, err = parametroBool(valores, "destacada"); err != nil {
+		return filtros, err
+	}
+	if filtros.Oferta, err = parametroBool(valores, "oferta"); err != nil {
+		return filtros, err
+	}
+
+	if filtros.M2Min, err = parametroEntero(valores, "m2_min"); err != nil {
+		return filtros, err
+	}
+	if filtros.M2Max, err = parametroEntero(valores, "m2_max"); err != nil {
+		return filtros, err
+	}
+	if filtros.M2Min != nil && filtros.M2Max != nil && *filtros.M2Min > *filtros.M2Max {
+		return filtros, &ErrFiltroInvalido{"m2_min", "no puede ser mayor que m2_max"}
+	}
+
+	if filtros.PrecioMin, err = parametroDecimal(valores, "precio_min"); err != nil {
+		return filtros, err
+	}
+	if filtros.PrecioMax, err = parametroDecimal(valores, "precio_max"); err != nil {
+		return filtros, err
+	}
+	if filtros.PrecioMin != nil && filtros.PrecioMax != nil && *filtros.PrecioMin > *filtros.PrecioMax {
+		return filtros, &ErrFiltroInvalido{"precio_min", "no puede ser mayor que precio_max"}
+	}
+
+	for _, expresion := range valores["caracteristica"] {
+		filtro, err := parsearFiltroCaracteristica(expresion)
+		if err != nil {
+			return filtros, err
+		}
+		filtros.Caracteristicas = append(filtros.Caracteristicas, filtro)
+	}
+
+	for _, valor := range valores["estado"] {
+		for _, estado := range strings.Split(valor, ",") {
+			estado = strings.TrimSpace(estado)
+			if estado == "" {
+				continue
+			}
+			if !estadoPublicacionValido(estado) {
+				return filtros, &ErrFiltroInvalido{"estado", "valores permitidos: " + strings.Join(EstadosPublicacion, ", ")}
+			}
+			filtros.Estados = append(filtros.Estados, estado)
+		}
+	}
+
+	filtros.Orden = strings.TrimSpace(valores.Get("sort"))
+	if _, ok := OrdenesPrefabricadas[filtros.Orden]; filtros.Orden != "" && !ok {
+		var permitidos []string
+		for orden := range OrdenesPrefabricadas {
+			permitidos = append(permitidos, orden)
+		}
+		sort.Strings(permitidos)
+		return filtros, &ErrFiltroInvalido{"sort", "valores permitidos: " + strings.Join(permitidos, ", ")}
+	}
+
+	return filtros, nil
+}
+
+// Función para aplicar los filtros a una consulta sobre la tabla prefabricadas (sin el orden)
+func AplicarFiltrosPrefabricadas(query *gorm.DB, filtros FiltrosPrefabricadas) *gorm.DB {
+	if len(filtros.CategoriaIDs) > 0 {
+		query = query.Where("prefabricadas.categoria_id IN ?", filtros.CategoriaIDs)
+	}
+	if len(filtros.TipoIDs) > 0 {
+		query = query.Where("prefabricadas.tipo_id IN ?", filtros.TipoIDs)
+	}
+	if len(filtros.EstiloIDs) > 0 {
+		query = query.Where("prefabricadas.estilo_id IN ?", filtros.EstiloIDs)
+	}
+	if filtros.Destacada != nil {
+		query = query.Where("prefabricadas.destacada = ?", *filtros.Destacada)
+	}
+	if filtros.Oferta != nil {
+		query = query.Where("prefabricadas.oferta = ?", *filtros.Oferta)
+	}
+	if filtros.M2Min != nil {
+		query = query.Where("prefabricadas.m2 >= ?", *filtros.M2Min)
+	}
+	if filtros.M2Max != nil {
+		query = query.Where("prefabricadas.m2 <= ?", *filtros.M2Max)
+	}
+	if filtros.PrecioMin != nil {
+		query = query.Where(precioMinimoPrefabricada+" >= ?", *filtros.PrecioMin)
+	}
+	if filtros.PrecioMax != nil {
+		query = query.Where(precioMinimoPrefabricada+" <= ?", *filtros.PrecioMax)
+	}
+
+	if len(filtros.Estados) > 0 {
+		query = query.Where("prefabricadas.estado IN ?", filtros.Estados)
+	}
+	if filtros.SoloPublicadas {
+		query = FiltrarPublicados(query, "prefabricadas")
+	}
+
+	for _, filtro := range filtros.Caracteristicas {
+		condicion := "caracteristicas.valor " + filtro.Operador + " ?"
+		var valor interface{} = filtro.Valor
+		if numero, ok := numeroFinito(strings.ReplaceAll(filtro.Valor, ",", ".")); ok {
+			// Comparación numérica solo contra valores que comienzan con un número (ej. "3" o "3 baños")
+			condicion = "caracteristicas.valor REGEXP '^[[:space:]]*[0-9]' AND CAST(caracteristicas.valor AS DECIMAL(14,2)) " + filtro.Operador + " ?"
+			valor = numero
+		}
+		query = query.Where(`EXISTS (SELECT 1 FROM caracteristicas
+			WHERE caracteristicas.prefabricada_id = prefabricadas.id AND caracteristicas.deleted_at IS NULL
+			AND caracteristicas.clave = ? AND `+condicion+`)`, filtro.Clave, valor)
+	}
+
+	return query
+}
+
+// Función para ordenar la consulta según una de las opciones de OrdenesPrefabricadas (por defecto, por ID).
+// El ID desempata para que la paginación sea estable.
+func OrdenarPrefabricadas(query *gorm.DB, orden string) *gorm.DB {
+	if expresion, ok := OrdenesPrefabricadas[orden]; ok {
+		query = query.Order(expresion)
+	}
+	return query.Order("prefabricadas.id ASC")
+}
+
+func parsearFiltroCaracteristica(expresion string) (FiltroCaracteristica, error) {
+	partes := expresionFiltroCaracteristica.FindStringSubmatch(expresion)
+	if partes == nil {
+		return FiltroCaracteristica{}, &ErrFiltroInvalido{"caracteristica", "use el formato clave<operador>valor, ej. dormitorios>=3"}
+	}
+
+	filtro := FiltroCaracteristica{Clave: partes[1], Operador: partes[2], Valor: partes[3]}
+	if filtro.Operador != "=" && filtro.Operador != "!=" {
+		if _, ok := numeroFinito(strings.ReplaceAll(filtro.Valor, ",", ".")); !ok {
+			return filtro, &ErrFiltroInvalido{"caracteristica", "el operador " + filtro.Operador + " requiere un valor numérico"}
+		}
+	}
+	return filtro, nil
+}
+
+func listaIDs(valores url.Values, parametro string) ([]uint, error) {
+	var ids []uint
+	for _, valor := range valores[parametro] {
+		for _, parte := range strings.Split(valor, ",") {
+			parte = strings.TrimSpace(parte)
+			if parte == "" {
+				continue
+			}
+			id, err := strconv.ParseUint(parte, 10, 64)
+			if err != nil {
+				return nil, &ErrFiltroInvalido{parametro, "debe ser una lista de IDs separados por coma"}
+			}
+			ids = append(ids, uint(id))
+		}
+	}
+	return ids, nil
+}
+
+func parametroBool(valores url.Values, parametro string) (*bool, error) {
+	valor := valores.Get(parametro)
+	if valor == "" {
+		return nil, nil
+	}
+	b, err := strconv.ParseBool(valor)
+	if err != nil {
+		return nil, &ErrFiltroInvalido{parametro, "debe ser true o false"}
+	}
+	return &b, nil
+}
+
+func parametroEntero(valores url.Values, parametro string) (*int, error) {
+	valor := valores.Get(parametro)
+	if valor == "" {
+		return nil, nil
+	}
+	n, err := strconv.Atoi(valor)
+	if err != nil || n < 0 {
+		return nil, &ErrFiltroInvalido{parametro, "debe ser un número entero positivo"}
+	}
+	return &n, nil
+}
+
+func parametroDecimal(valores url.Values, parametro string) (*float64, error) {
+	valor := valores.Get(parametro)
+	if valor == "" {
+		return nil, nil
+	}
+	n, ok := numeroFinito(valor)
+	if !ok || n < 0 {
+		return nil, &ErrFiltroInvalido{parametro, "debe ser un número positivo"}
+	}
+	return &n, nil
+}
+
+// Interpretar un número decimal descartando NaN e infinito, que ParseFloat acepta pero MySQL no
+func numeroFinito(texto string) (float64, bool) {
+	n, err := strconv.ParseFloat(texto, 64)
+	if err != nil || math.IsNaN(n) || math.IsInf(n, 0) {
+		return 0, false
+	}
+	return n, true
+}
+
+func estadoPublicacionValido(estado string) bool {
+	for _, permitido := range EstadosPublicacion {
+		if estado == permitido {
+			return true
+		}
+	}
+	return false
+}
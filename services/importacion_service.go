@@ -0,0 +1,523 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"v1_prefabricadas/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Acción que la importación realiza sobre cada prefabricada
+const (
+	AccionImportacionCrear      = "create"
+	AccionImportacionActualizar = "update"
+	AccionImportacionOmitir     = "skip" // Ya existe y no hay cambios
+)
+
+// Estados del historial de importaciones
+const (
+	EstadoImportacionSimulada  = "simulada"  // Dry run: solo se informó lo que se haría
+	EstadoImportacionAplicada  = "aplicada"  // Los cambios se guardaron
+	EstadoImportacionRechazada = "rechazada" // Había errores y no se guardó nada
+)
+
+// Separadores de las celdas con listas: "Dormitorios: 3; Baños: 2" e "Ventanas; Puertas"
+const (
+	SeparadorListaCatalogo          = ";"
+	SeparadorCaracteristicaCatalogo = ":"
+)
+
+// Columnas de la hoja de cálculo del catálogo: una fila por prefabricada y precio.
+// Los datos de la prefabricada se repiten en cada fila de sus precios. Al importar se ignora
+// el estado: las prefabricadas nuevas se crean como borrador y las existentes lo conservan.
+var ColumnasCatalogo = []string{
+	"slug", "nombre_prefabricada", "m2", "garantia", "eslogan", "descripcion", "destacada", "oferta",
+	"categoria_id", "estilo_id", "tipo_id", "estado", "caracteristicas",
+	"nombre_precio", "descripcion_precio", "valor_prefabricada", "incluyes",
+}
+
+type CaracteristicaImportada struct {
+	Clave string
+	Valor string
+}
+
+type PrecioImportado struct {
+	NombrePrecio      string
+	DescripcionPrecio string
+	ValorPrefabricada float64
+	Incluyes          []string
+}
+
+// Prefabricada leída de la hoja de cálculo, con las filas de las que se obtuvo
+type PrefabricadaImportada struct {
+	Filas              []int
+	Slug               string
+	NombrePrefabricada string
+	M2                 int
+	Garantia           string
+	Eslogan            string
+	Descripcion        string
+	Destacada          bool
+	Oferta             bool
+	CategoriaID        uint
+	EstiloID           uint
+	TipoID             uint
+	Caracteristicas    []CaracteristicaImportada
+	Precios            []PrecioImportado
+	// Si la hoja trae la columna, la celda es la lista completa y lo que no aparece se envía a la papelera.
+	// Los precios que no aparecen en la hoja no se eliminan.
+	SincronizarCaracteristicas bool
+	SincronizarIncluyes        bool
+}
+
+// Error de una fila (y columna, si corresponde) de la hoja de cálculo
+type ErrorImportacion struct {
+	Fila    int
+	Columna string
+	Mensaje string
+}
+
+func (e ErrorImportacion) Error() string {
+	return e.Mensaje
+}
+
+// Lo que la importación hace (o haría) con una prefabricada de la hoja
+type CambioImportacion struct {
+	Filas              []int
+	Accion             string
+	PrefabricadaID     uint // 0 si se crea y aún no se aplica
+	NombrePrefabricada string
+	Slug               string
+	Detalles           []string // Diferencias con los datos actuales
+	datos              PrefabricadaImportada
+	existente          *models.Prefabricada
+}
+
+type PlanImportacion struct {
+	Cambios []CambioImportacion
+	Errores []ErrorImportacion
+}
+
+// Cantidad de prefabricadas con la acción indicada
+func (p PlanImportacion) Contar(accion string) int {
+	cantidad := 0
+	for _, cambio := range p.Cambios {
+		if cambio.Accion == accion {
+			cantidad++
+		}
+	}
+	return cantidad
+}
+
+// Función para comparar las prefabricadas de la hoja con las de la empresa y decidir si cada una se crea,
+// se actualiza o se omite. Una prefabricada se busca por su slug (actual o antiguo) si la hoja lo trae y si no
+// por su nombre. Los errores de datos quedan en el plan; el error devuelto es solo de la base de datos.
+func PlanificarImportacion(db *gorm.DB, empresaID uint, prefabricadas []PrefabricadaImportada) (PlanImportacion, error) {
+	var plan PlanImportacion
+
+	taxonomias := map[string]map[uint]bool{}
+	for _, tabla := range []string{"categorias", "estilos", "tipos"} {
+		var ids []uint
+		if err := db.Table(tabla).Where("deleted_at IS NULL").Pluck("id", &ids).Error; err != nil {
+			return plan, err
+		}
+		taxonomias[tabla] = map[uint]bool{}
+		for _, id := range ids {
+			taxonomias[tabla][id] = true
+		}
+	}
+
+	filaDeExistente := map[uint]int{}    // Para no importar dos veces la misma prefabricada
+	slugsReservados := map[string]bool{} // Slugs de las prefabricadas nuevas de esta importación
+
+	for _, datos := range prefabricadas {
+		fila := datos.Filas[0]
+		errores := len(plan.Errores)
+
+		for _, taxonomia := range []struct {
+			tabla, columna, nombre string
+			id                     uint
+		}{
+			{"categorias", "categoria_id", "categoría", datos.CategoriaID},
+			{"estilos", "estilo_id", "estilo", datos.EstiloID},
+			{"tipos", "tipo_id", "tipo", datos.TipoID},
+		} {
+			if !taxonomias[taxonomia.tabla][taxonomia.id] {
+				plan.Errores = append(plan.Errores, ErrorImportacion{fila, taxonomia.columna, fmt.Sprintf("el %s %d no existe", taxonomia.nombre, taxonomia.id)})
+			}
+		}
+
+		existente, err := buscarPrefabricadaImportada(db, empresaID, datos)
+		if err != nil {
+			var errDatos ErrorImportacion
+			if errors.As(err, &errDatos) {
+				errDatos.Fila = fila
+				plan.Errores = append(plan.Errores, errDatos)
+				continue
+			}
+			return plan, err
+		}
+
+		cambio := CambioImportacion{
+			Filas:              datos.Filas,
+			NombrePrefabricada: datos.NombrePrefabricada,
+			datos:              datos,
+			existente:          existente,
+		}
+
+		if existente != nil {
+			if anterior, ok := filaDeExistente[existente.ID]; ok {
+				plan.Errores = append(plan.Errores, ErrorImportacion{fila, "", fmt.Sprintf("la prefabricada ya se importa en la fila %d", anterior)})
+				continue
+			}
+			filaDeExistente[existente.ID] = fila
+
+			cambio.PrefabricadaID = existente.ID
+			cambio.Slug = valorSlugImportacion(existente.Slug)
+			cambio.Detalles = diferenciasImportacion(*existente, datos)
+			cambio.Accion = AccionImportacionActualizar
+			if len(cambio.Detalles) == 0 {
+				cambio.Accion = AccionImportacionOmitir
+			}
+		} else {
+			cambio.Accion = AccionImportacionCrear
+			cambio.Slug, err = slugImportacion(db, empresaID, datos, slugsReservados)
+			if err != nil {
+				if errors.Is(err, ErrSlugEnUso) || errors.Is(err, ErrSlugInvalido) {
+					plan.Errores = append(plan.Errores, ErrorImportacion{fila, "slug", err.Error()})
+					continue
+				}
+				return plan, err
+			}
+			slugsReservados[cambio.Slug] = true
+		}
+
+		if len(plan.Errores) == errores {
+			plan.Cambios = append(plan.Cambios, cambio)
+		}
+	}
+
+	return plan, nil
+}
+
+// Función para guardar los cambios del plan. Debe llamarse dentro de una transacción y solo si el plan no
+// tiene errores; al terminar, los cambios de prefabricadas nuevas quedan con su ID.
+func AplicarImportacion(db *gorm.DB, empresaID, usuarioID uint, plan *PlanImportacion) error {
+	for i := range plan.Cambios {
+		cambio := &plan.Cambios[i]
+		switch cambio.Accion {
+		case AccionImportacionCrear:
+			id, err := crearPrefabricadaImportada(db, empresaID, cambio.Slug, cambio.datos)
+			if err != nil {
+				return err
+			}
+			cambio.PrefabricadaID = id
+		case AccionImportacionActualizar:
+			if err := actualizarPrefabricadaImportada(db, empresaID, usuarioID, cambio.existente, cambio.datos); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Buscar la prefabricada (no eliminada, con sus relaciones no eliminadas) que corresponde a la de la hoja.
+// Devuelve nil si no existe y un ErrorImportacion si el nombre es ambiguo.
+func buscarPrefabricadaImportada(db *gorm.DB, empresaID uint, datos PrefabricadaImportada) (*models.Prefabricada, error) {
+	var id uint
+	if datos.Slug != "" {
+		resuelto, err := ResolverSlug(EntidadSlugPrefabricada, empresaID, datos.Slug)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+		id = resuelto.ID
+	}
+
+	if id == 0 {
+		var ids []uint
+		if err := db.Model(&models.Prefabricada{}).
+			Where("empresa_id = ? AND deleted_at IS NULL AND nombre_prefabricada = ?", empresaID, datos.NombrePrefabricada).
+			Limit(2).Pluck("id", &ids).Error; err != nil {
+			return nil, err
+		}
+		if len(ids) > 1 {
+			return nil, ErrorImportacion{Columna: "nombre_prefabricada", Mensaje: "hay más de una prefabricada con este nombre, indique el slug"}
+		}
+		if len(ids) == 0 {
+			return nil, nil
+		}
+		id = ids[0]
+	}
+
+	var prefabricada models.Prefabricada
+	if err := db.
+		Preload("Caracteristica", "deleted_at IS NULL").
+		Preload("Precio", "deleted_at IS NULL").
+		Preload("Precio.Incluye", "deleted_at IS NULL").
+		Where("empresa_id = ? AND deleted_at IS NULL", empresaID).
+		First(&prefabricada, id).Error; err != nil {
+		return nil, err
+	}
+	return &prefabricada, nil
+}
+
+// Slug de una prefabricada nueva: el indicado en la hoja o uno generado desde el nombre,
+// distinto de los de otras prefabricadas nuevas de la misma importación
+func slugImportacion(db *gorm.DB, empresaID uint, datos PrefabricadaImportada, reservados map[string]bool) (string, error) {
+	if datos.Slug != "" {
+		slug, err := ValidarSlug(db, EntidadSlugPrefabricada, empresaID, datos.Slug, 0)
+		if err == nil && reservados[slug] {
+			err = ErrSlugEnUso
+		}
+		return slug, err
+	}
+
+	for i := 1; ; i++ {
+		texto := datos.NombrePrefabricada
+		if i > 1 {
+			texto = fmt.Sprintf("%s %d", texto, i)
+		}
+		slug, err := GenerarSlugUnico(db, EntidadSlugPrefabricada, empresaID, texto, 0)
+		if err != nil || !reservados[slug] {
+			return slug, err
+		}
+	}
+}
+
+// Diferencias entre la prefabricada actual y la de la hoja, en el formato "campo: antes → después"
+func diferenciasImportacion(actual models.Prefabricada, datos PrefabricadaImportada) []string {
+	var detalles []string
+	campo := func(nombre, antes, despues string) {
+		if antes != despues {
+			detalles = append(detalles, fmt.Sprintf("%s: %s → %s", nombre, antes, despues))
+		}
+	}
+
+	campo("nombre_prefabricada", actual.NombrePrefabricada, datos.NombrePrefabricada)
+	campo("m2", strconv.Itoa(actual.M2), strconv.Itoa(datos.M2))
+	campo("garantia", actual.Garantia, datos.Garantia)
+	campo("eslogan", actual.Eslogan, datos.Eslogan)
+	campo("descripcion", actual.Descripcion, datos.Descripcion)
+	campo("destacada", strconv.FormatBool(actual.Destacada), strconv.FormatBool(datos.Destacada))
+	campo("oferta", strconv.FormatBool(actual.Oferta), strconv.FormatBool(datos.Oferta))
+	campo("categoria_id", strconv.FormatUint(uint64(actual.CategoriaID), 10), strconv.FormatUint(uint64(datos.CategoriaID), 10))
+	campo("estilo_id", strconv.FormatUint(uint64(actual.EstiloID), 10), strconv.FormatUint(uint64(datos.EstiloID), 10))
+	campo("tipo_id", strconv.FormatUint(uint64(actual.TipoID), 10), strconv.FormatUint(uint64(datos.TipoID), 10))
+
+	caracteristicas := map[string]models.Caracteristica{}
+	for _, caracteristica := range actual.Caracteristica {
+		caracteristicas[strings.ToLower(caracteristica.Clave)] = caracteristica
+	}
+	importadas := map[string]bool{}
+	for _, caracteristica := range datos.Caracteristicas {
+		clave := strings.ToLower(caracteristica.Clave)
+		importadas[clave] = true
+		if existente, ok := caracteristicas[clave]; !ok {
+			detalles = append(detalles, fmt.Sprintf("+ característica %s: %s", caracteristica.Clave, caracteristica.Valor))
+		} else {
+			campo("característica "+existente.Clave, existente.Valor, caracteristica.Valor)
+		}
+	}
+	if datos.SincronizarCaracteristicas {
+		for _, caracteristica := range actual.Caracteristica {
+			if !importadas[strings.ToLower(caracteristica.Clave)] {
+				detalles = append(detalles, "- característica "+caracteristica.Clave)
+			}
+		}
+	}
+
+	precios := map[string]models.Precio{}
+	for _, precio := range actual.Precio {
+		precios[strings.ToLower(precio.NombrePrecio)] = precio
+	}
+	for _, precio := range datos.Precios {
+		existente, ok := precios[strings.ToLower(precio.NombrePrecio)]
+		if !ok {
+			detalles = append(detalles, fmt.Sprintf("+ precio %s: %s", precio.NombrePrecio, formatearValor(precio.ValorPrefabricada)))
+			continue
+		}
+		campo("precio "+existente.NombrePrecio+" descripcion_precio", existente.DescripcionPrecio, precio.DescripcionPrecio)
+		campo("precio "+existente.NombrePrecio+" valor_prefabricada", formatearValor(existente.ValorPrefabricada), formatearValor(precio.ValorPrefabricada))
+		if datos.SincronizarIncluyes {
+			agregados, quitados := diferenciasIncluyes(existente.Incluye, precio.Incluyes)
+			for _, incluye := range agregados {
+				detalles = append(detalles, fmt.Sprintf("+ incluye %s (precio %s)", incluye, existente.NombrePrecio))
+			}
+			for _, incluye := range quitados {
+				detalles = append(detalles, fmt.Sprintf("- incluye %s (precio %s)", incluye.NombreIncluye, existente.NombrePrecio))
+			}
+		}
+	}
+
+	return detalles
+}
+
+// Incluyes de la hoja que el precio no tiene e incluyes del precio que no están en la hoja
+func diferenciasIncluyes(actuales []models.Incluye, importados []string) ([]string, []models.Incluye) {
+	existentes := map[string]bool{}
+	for _, incluye := range actuales {
+		existentes[strings.ToLower(incluye.NombreIncluye)] = true
+	}
+	enHoja := map[string]bool{}
+	var agregados []string
+	for _, incluye := range importados {
+		enHoja[strings.ToLower(incluye)] = true
+		if !existentes[strings.ToLower(incluye)] {
+			agregados = append(agregados, incluye)
+		}
+	}
+	var quitados []models.Incluye
+	for _, incluye := range actuales {
+		if !enHoja[strings.ToLower(incluye.NombreIncluye)] {
+			quitados = append(quitados, incluye)
+		}
+	}
+	return agregados, quitados
+}
+
+// Crear la prefabricada (como borrador) con sus características, precios e incluyes
+func crearPrefabricadaImportada(db *gorm.DB, empresaID uint, slug string, datos PrefabricadaImportada) (uint, error) {
+	prefabricada := models.Prefabricada{Slug: &slug, EmpresaID: empresaID, Estado: EstadoBorrador}
+	asignarDatosImportados(&prefabricada, datos)
+	if err := db.Omit(clause.Associations).Create(&prefabricada).Error; err != nil {
+		return 0, err
+	}
+
+	for _, caracteristica := range datos.Caracteristicas {
+		if err := crearCaracteristicaImportada(db, prefabricada.ID, caracteristica); err != nil {
+			return 0, err
+		}
+	}
+	for _, precio := range datos.Precios {
+		if err := crearPrecioImportado(db, prefabricada.ID, precio); err != nil {
+			return 0, err
+		}
+	}
+	return prefabricada.ID, nil
+}
+
+// Actualizar la prefabricada con los datos de la hoja. Las características e incluyes que ya no están
+// se envían a la papelera (si la hoja trae su columna); los precios que no están no se modifican.
+func actualizarPrefabricadaImportada(db *gorm.DB, empresaID, usuarioID uint, prefabricada *models.Prefabricada, datos PrefabricadaImportada) error {
+	asignarDatosImportados(prefabricada, datos)
+	if err := db.Omit(clause.Associations).Save(prefabricada).Error; err != nil {
+		return err
+	}
+
+	caracteristicas := map[string]*models.Caracteristica{}
+	for i := range prefabricada.Caracteristica {
+		caracteristicas[strings.ToLower(prefabricada.Caracteristica[i].Clave)] = &prefabricada.Caracteristica[i]
+	}
+	for _, caracteristica := range datos.Caracteristicas {
+		clave := strings.ToLower(caracteristica.Clave)
+		existente, ok := caracteristicas[clave]
+		delete(caracteristicas, clave)
+		if !ok {
+			if err := crearCaracteristicaImportada(db, prefabricada.ID, caracteristica); err != nil {
+				return err
+			}
+			continue
+		}
+		if existente.Valor != caracteristica.Valor {
+			existente.Valor = caracteristica.Valor
+			if err := db.Omit(clause.Associations).Save(existente).Error; err != nil {
+				return err
+			}
+		}
+	}
+	if datos.SincronizarCaracteristicas {
+		for _, caracteristica := range caracteristicas {
+			if err := EnviarAPapelera(db, "caracteristicas", caracteristica.ID, empresaID, usuarioID); err != nil {
+				return err
+			}
+		}
+	}
+
+	precios := map[string]*models.Precio{}
+	for i := range prefabricada.Precio {
+		precios[strings.ToLower(prefabricada.Precio[i].NombrePrecio)] = &prefabricada.Precio[i]
+	}
+	for _, precio := range datos.Precios {
+		existente, ok := precios[strings.ToLower(precio.NombrePrecio)]
+		if !ok {
+			if err := crearPrecioImportado(db, prefabricada.ID, precio); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if existente.DescripcionPrecio != precio.DescripcionPrecio || existente.ValorPrefabricada != precio.ValorPrefabricada {
+			existente.DescripcionPrecio = precio.DescripcionPrecio
+			existente.ValorPrefabricada = precio.ValorPrefabricada
+			if err := db.Omit(clause.Associations).Save(existente).Error; err != nil {
+				return err
+			}
+		}
+
+		if datos.SincronizarIncluyes {
+			agregados, quitados := diferenciasIncluyes(existente.Incluye, precio.Incluyes)
+			for _, nombre := range agregados {
+				if err := db.Omit(clause.Associations).Create(&models.Incluye{NombreIncluye: nombre, PrecioID: existente.ID}).Error; err != nil {
+					return err
+				}
+			}
+			for _, incluye := range quitados {
+				if err := EnviarAPapelera(db, "incluyes", incluye.ID, empresaID, usuarioID); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func asignarDatosImportados(prefabricada *models.Prefabricada, datos PrefabricadaImportada) {
+	prefabricada.NombrePrefabricada = datos.NombrePrefabricada
+	prefabricada.M2 = datos.M2
+	prefabricada.Garantia = datos.Garantia
+	prefabricada.Eslogan = datos.Eslogan
+	prefabricada.Descripcion = datos.Descripcion
+	prefabricada.Destacada = datos.Destacada
+	prefabricada.Oferta = datos.Oferta
+	prefabricada.CategoriaID = datos.CategoriaID
+	prefabricada.EstiloID = datos.EstiloID
+	prefabricada.TipoID = datos.TipoID
+}
+
+func crearCaracteristicaImportada(db *gorm.DB, prefabricadaID uint, datos CaracteristicaImportada) error {
+	caracteristica := models.Caracteristica{Clave: datos.Clave, Valor: datos.Valor, PrefabricadaID: prefabricadaID}
+	return db.Omit(clause.Associations).Create(&caracteristica).Error
+}
+
+func crearPrecioImportado(db *gorm.DB, prefabricadaID uint, datos PrecioImportado) error {
+	precio := models.Precio{
+		NombrePrecio:      datos.NombrePrecio,
+		DescripcionPrecio: datos.DescripcionPrecio,
+		ValorPrefabricada: datos.ValorPrefabricada,
+		PrefabricadaID:    prefabricadaID,
+	}
+	if err := db.Omit(clause.Associations).Create(&precio).Error; err != nil {
+		return err
+	}
+	for _, nombre := range datos.Incluyes {
+		if err := db.Omit(clause.Associations).Create(&models.Incluye{NombreIncluye: nombre, PrecioID: precio.ID}).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func formatearValor(valor float64) string {
+	return strconv.FormatFloat(valor, 'f', -1, 64)
+}
+
+func valorSlugImportacion(slug *string) string {
+	if slug == nil {
+		return ""
+	}
+	return *slug
+}
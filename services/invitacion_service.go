@@ -0,0 +1,111 @@
+package services
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"time"
+	"v1_prefabricadas/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Tiempo de validez del enlace de invitación
+const DuracionInvitacion = 72 * time.Hour
+
+// URLs del frontend usadas cuando no se definen INVITACION_URL y VERIFICAR_EMAIL_URL
+const (
+	urlInvitacionPorDefecto     = "https://vifrontendcasasemilia-production.up.railway.app/invitacion/"
+	urlVerificarEmailPorDefecto = "https://vifrontendcasasemilia-production.up.railway.app/verificar-email/"
+)
+
+var ErrInvitacionInvalida = errors.New("invitación inválida o expirada")
+
+// CrearInvitacion invalida las invitaciones pendientes de la credencial y emite una nueva.
+// Devuelve el token en texto plano, que solo viaja en el email.
+func CrearInvitacion(db *gorm.DB, credencialID uint) (string, error) {
+	return crearTokenVerificacion(db, credencialID, "")
+}
+
+// CrearVerificacionEmail emite el token para confirmar el cambio de email de una credencial.
+// El email actual sigue vigente hasta que se confirme el nuevo.
+func CrearVerificacionEmail(db *gorm.DB, credencialID uint, emailNuevo string) (string, error) {
+	return crearTokenVerificacion(db, credencialID, emailNuevo)
+}
+
+func crearTokenVerificacion(db *gorm.DB, credencialID uint, emailNuevo string) (string, error) {
+	token, err := GenerarTokenAleatorio()
+	if err != nil {
+		return "", err
+	}
+
+	if err := db.Model(&models.Invitacion{}).
+		Where("credencial_id = ? AND invalidado_at IS NULL", credencialID).
+		Update("invalidado_at", time.Now()).Error; err != nil {
+		return "", err
+	}
+
+	if err := db.Create(&models.Invitacion{
+		TokenHash:    HashToken(token),
+		ExpiresAt:    time.Now().Add(DuracionInvitacion),
+		EmailNuevo:   emailNuevo,
+		CredencialID: credencialID,
+	}).Error; err != nil {
+		return "", err
+	}
+
+	return token, nil
+}
+
+// ConsumirInvitacion valida el token y marca la invitación como usada dentro de la transacción recibida.
+// cambioEmail indica si se espera un token de cambio de email o uno de invitación, que no son intercambiables.
+func ConsumirInvitacion(tx *gorm.DB, token string, cambioEmail bool) (models.Invitacion, error) {
+	var invitacion models.Invitacion
+	query := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("token_hash = ?", HashToken(token))
+	if cambioEmail {
+		query = query.Where("email_nuevo <> ''")
+	} else {
+		query = query.Where("email_nuevo = '' OR email_nuevo IS NULL")
+	}
+	err := query.First(&invitacion).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return invitacion, ErrInvitacionInvalida
+	}
+	if err != nil {
+		return invitacion, err
+	}
+
+	if invitacion.InvalidadoAt != nil || time.Now().After(invitacion.ExpiresAt) {
+		return invitacion, ErrInvitacionInvalida
+	}
+
+	now := time.Now()
+	invitacion.InvalidadoAt = &now
+	if err := tx.Save(&invitacion).Error; err != nil {
+		return invitacion, err
+	}
+
+	return invitacion, nil
+}
+
+// URLVerificarEmail arma el enlace de confirmación de un cambio de email a partir de VERIFICAR_EMAIL_URL
+func URLVerificarEmail(token string) string {
+	return construirURL("VERIFICAR_EMAIL_URL", urlVerificarEmailPorDefecto, token)
+}
+
+// URLInvitacion arma el enlace que se envía por email a partir de INVITACION_URL
+func URLInvitacion(token string) string {
+	return construirURL("INVITACION_URL", urlInvitacionPorDefecto, token)
+}
+
+func construirURL(variable, porDefecto, token string) string {
+	base := os.Getenv(variable)
+	if base == "" {
+		base = porDefecto
+	}
+	if !strings.HasSuffix(base, "/") {
+		base += "/"
+	}
+	return base + token
+}
@@ -0,0 +1,345 @@
+package services
+
+import (
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+	"v1_prefabricadas/configs"
+	"v1_prefabricadas/models"
+
+	"github.com/golang-jwt/jwt/v4"
+	"gorm.io/gorm"
+)
+
+// Propósitos del flujo OpenID Connect
+const (
+	PropositoOIDCLogin    = "oidc_login"
+	PropositoOIDCVincular = "oidc_vincular"
+)
+
+// Emisor usado cuando no se define OIDC_ISSUER (Google Workspace)
+const emisorOIDCPorDefecto = "https://accounts.google.com"
+
+// Tiempo que tiene el usuario para completar el login en el proveedor
+const duracionEstadoOIDC = 10 * time.Minute
+
+var (
+	ErrOIDCNoConfigurado     = errors.New("el inicio de sesión con OpenID Connect no está configurado")
+	ErrOIDCEstadoInvalido    = errors.New("estado de OpenID Connect inválido o expirado")
+	ErrOIDCTokenInvalido     = errors.New("token de identidad inválido")
+	ErrOIDCEmailNoVerificado = errors.New("el proveedor no verificó el email de la cuenta")
+)
+
+var clienteHTTPOIDC = &http.Client{Timeout: 10 * time.Second}
+
+// IdentidadOIDC es la identidad externa verificada a partir del id_token
+type IdentidadOIDC struct {
+	Issuer  string
+	Subject string
+	Email   string
+	Dominio string // Claim "hd" de Google Workspace, vacío en cuentas personales
+}
+
+// EstadoOIDCClaims viaja firmado al frontend entre el inicio y el final del flujo,
+// así la API no necesita guardar el estado de cada login pendiente
+type EstadoOIDCClaims struct {
+	Estado       string `json:"state"`
+	Nonce        string `json:"nonce"`
+	CodeVerifier string `json:"code_verifier"` // PKCE
+	Proposito    string `json:"proposito"`
+	UsuarioID    uint   `json:"usuario_id,omitempty"` // Usuario autenticado que vincula su cuenta
+	jwt.RegisteredClaims
+}
+
+type configuracionOIDC struct {
+	issuer       string
+	clientID     string
+	clientSecret string
+	redirectURL  string
+}
+
+type descubrimientoOIDC struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JwksURI               string `json:"jwks_uri"`
+}
+
+var (
+	muOIDC                  sync.Mutex
+	descubrimientoOIDCCache *descubrimientoOIDC
+	llavesOIDC              map[string]*rsa.PublicKey
+)
+
+// leerConfiguracionOIDC lee OIDC_ISSUER, OIDC_CLIENT_ID, OIDC_CLIENT_SECRET y OIDC_REDIRECT_URL.
+// El emisor se puede apuntar a un proveedor local de pruebas.
+func leerConfiguracionOIDC() (configuracionOIDC, error) {
+	config := configuracionOIDC{
+		issuer:       strings.TrimSuffix(os.Getenv("OIDC_ISSUER"), "/"),
+		clientID:     os.Getenv("OIDC_CLIENT_ID"),
+		clientSecret: os.Getenv("OIDC_CLIENT_SECRET"),
+		redirectURL:  os.Getenv("OIDC_REDIRECT_URL"),
+	}
+	if config.issuer == "" {
+		config.issuer = emisorOIDCPorDefecto
+	}
+	if config.clientID == "" || config.redirectURL == "" {
+		return config, ErrOIDCNoConfigurado
+	}
+	return config, nil
+}
+
+// obtenerDescubrimientoOIDC lee (y guarda en memoria) el documento /.well-known/openid-configuration del emisor
+func obtenerDescubrimientoOIDC(config configuracionOIDC) (*descubrimientoOIDC, error) {
+	muOIDC.Lock()
+	defer muOIDC.Unlock()
+
+	if descubrimientoOIDCCache != nil && descubrimientoOIDCCache.Issuer == config.issuer {
+		return descubrimientoOIDCCache, nil
+	}
+
+	var doc descubrimientoOIDC
+	if err := obtenerJSON(config.issuer+"/.well-known/openid-configuration", &doc); err != nil {
+		return nil, err
+	}
+	if strings.TrimSuffix(doc.Issuer, "/") != config.issuer {
+		return nil, fmt.Errorf("el emisor del descubrimiento (%s) no coincide con OIDC_ISSUER", doc.Issuer)
+	}
+
+	descubrimientoOIDCCache = &doc
+	llavesOIDC = nil
+	return descubrimientoOIDCCache, nil
+}
+
+func obtenerJSON(url string, destino interface{}) error {
+	resp, err := clienteHTTPOIDC.Get(url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s respondió %d", url, resp.StatusCode)
+	}
+	return json.NewDecoder(resp.Body).Decode(destino)
+}
+
+// IniciarOIDC arma la URL de autorización del proveedor y el estado firmado que el frontend debe devolver
+func IniciarOIDC(proposito string, usuarioID uint) (string, string, error) {
+	config, err := leerConfiguracionOIDC()
+	if err != nil {
+		return "", "", err
+	}
+	doc, err := obtenerDescubrimientoOIDC(config)
+	if err != nil {
+		return "", "", err
+	}
+
+	valores := make([]string, 3)
+	for i := range valores {
+		if valores[i], err = GenerarTokenAleatorio(); err != nil {
+			return "", "", err
+		}
+	}
+	estado, nonce, verifier := valores[0], valores[1], valores[2]
+
+	estadoToken, err := FirmarToken(&EstadoOIDCClaims{
+		Estado:       estado,
+		Nonce:        nonce,
+		CodeVerifier: verifier,
+		Proposito:    proposito,
+		UsuarioID:    usuarioID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(duracionEstadoOIDC)),
+			Issuer:    EmisorToken,
+		},
+	})
+	if err != nil {
+		return "", "", err
+	}
+
+	challenge := sha256.Sum256([]byte(verifier))
+	parametros := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {config.clientID},
+		"redirect_uri":          {config.redirectURL},
+		"scope":                 {"openid email profile"},
+		"state":                 {estado},
+		"nonce":                 {nonce},
+		"code_challenge":        {base64.RawURLEncoding.EncodeToString(challenge[:])},
+		"code_challenge_method": {"S256"},
+		"prompt":                {"select_account"},
+	}
+
+	return doc.AuthorizationEndpoint + "?" + parametros.Encode(), estadoToken, nil
+}
+
+// CompletarOIDC valida el estado, canjea el código por tokens y verifica el id_token.
+// Devuelve la identidad externa y el estado original (propósito y usuario que vincula).
+func CompletarOIDC(code, estado, estadoToken, proposito string) (IdentidadOIDC, EstadoOIDCClaims, error) {
+	var identidad IdentidadOIDC
+
+	claimsEstado := EstadoOIDCClaims{}
+	if err := VerificarToken(estadoToken, &claimsEstado); err != nil ||
+		claimsEstado.Proposito != proposito || claimsEstado.Estado != estado {
+		return identidad, claimsEstado, ErrOIDCEstadoInvalido
+	}
+
+	config, err := leerConfiguracionOIDC()
+	if err != nil {
+		return identidad, claimsEstado, err
+	}
+	doc, err := obtenerDescubrimientoOIDC(config)
+	if err != nil {
+		return identidad, claimsEstado, err
+	}
+
+	// Canjear el código de autorización
+	formulario := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {config.redirectURL},
+		"client_id":     {config.clientID},
+		"code_verifier": {claimsEstado.CodeVerifier},
+	}
+	if config.clientSecret != "" {
+		formulario.Set("client_secret", config.clientSecret)
+	}
+	resp, err := clienteHTTPOIDC.PostForm(doc.TokenEndpoint, formulario)
+	if err != nil {
+		return identidad, claimsEstado, err
+	}
+	defer resp.Body.Close()
+
+	var tokens struct {
+		IDToken string `json:"id_token"`
+		Error   string `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&tokens); err != nil {
+		return identidad, claimsEstado, err
+	}
+	if resp.StatusCode != http.StatusOK || tokens.IDToken == "" {
+		return identidad, claimsEstado, fmt.Errorf("%w: el proveedor rechazó el código (%s)", ErrOIDCTokenInvalido, tokens.Error)
+	}
+
+	identidad, err = verificarIDToken(config, doc, tokens.IDToken, claimsEstado.Nonce)
+	return identidad, claimsEstado, err
+}
+
+type idTokenClaims struct {
+	Email         string      `json:"email"`
+	EmailVerified interface{} `json:"email_verified"` // Algunos proveedores lo envían como texto
+	Nonce         string      `json:"nonce"`
+	Dominio       string      `json:"hd"`
+	jwt.RegisteredClaims
+}
+
+// verificarIDToken valida firma, emisor, audiencia, expiración y nonce del id_token
+func verificarIDToken(config configuracionOIDC, doc *descubrimientoOIDC, idToken, nonce string) (IdentidadOIDC, error) {
+	claims := &idTokenClaims{}
+	token, err := jwt.ParseWithClaims(idToken, claims, func(token *jwt.Token) (interface{}, error) {
+		if token.Method.Alg() != jwt.SigningMethodRS256.Alg() {
+			return nil, ErrOIDCTokenInvalido
+		}
+		kid, _ := token.Header["kid"].(string)
+		return llaveOIDC(doc, kid)
+	})
+	if err != nil || !token.Valid {
+		return IdentidadOIDC{}, ErrOIDCTokenInvalido
+	}
+
+	if strings.TrimSuffix(claims.Issuer, "/") != config.issuer && !(config.issuer == emisorOIDCPorDefecto && claims.Issuer == "accounts.google.com") {
+		return IdentidadOIDC{}, ErrOIDCTokenInvalido
+	}
+	if !claims.VerifyAudience(config.clientID, true) || claims.Nonce != nonce || claims.Subject == "" {
+		return IdentidadOIDC{}, ErrOIDCTokenInvalido
+	}
+	if claims.Email == "" || !(claims.EmailVerified == true || claims.EmailVerified == "true") {
+		return IdentidadOIDC{}, ErrOIDCEmailNoVerificado
+	}
+
+	return IdentidadOIDC{
+		Issuer:  config.issuer,
+		Subject: claims.Subject,
+		Email:   strings.ToLower(claims.Email),
+		Dominio: strings.ToLower(claims.Dominio),
+	}, nil
+}
+
+// llaveOIDC busca la llave pública del proveedor, volviendo a leer el JWKS si el kid es desconocido (rotación)
+func llaveOIDC(doc *descubrimientoOIDC, kid string) (*rsa.PublicKey, error) {
+	muOIDC.Lock()
+	defer muOIDC.Unlock()
+
+	if llave, ok := llavesOIDC[kid]; ok {
+		return llave, nil
+	}
+
+	var jwks struct {
+		Keys []struct {
+			Kid string `json:"kid"`
+			Kty string `json:"kty"`
+			N   string `json:"n"`
+			E   string `json:"e"`
+		} `json:"keys"`
+	}
+	if err := obtenerJSON(doc.JwksURI, &jwks); err != nil {
+		return nil, err
+	}
+
+	llavesOIDC = map[string]*rsa.PublicKey{}
+	for _, jwk := range jwks.Keys {
+		if jwk.Kty != "RSA" {
+			continue
+		}
+		n, errN := base64.RawURLEncoding.DecodeString(jwk.N)
+		e, errE := base64.RawURLEncoding.DecodeString(jwk.E)
+		if errN != nil || errE != nil {
+			continue
+		}
+		llavesOIDC[jwk.Kid] = &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}
+	}
+
+	llave, ok := llavesOIDC[kid]
+	if !ok {
+		return nil, ErrOIDCTokenInvalido
+	}
+	return llave, nil
+}
+
+// ObtenerConfiguracionOIDCEmpresa devuelve la configuración de la empresa o una deshabilitada si no existe
+func ObtenerConfiguracionOIDCEmpresa(empresaID uint) (models.Configuracion_oidc, error) {
+	var configuracion models.Configuracion_oidc
+	err := configs.DB.Where("empresa_id = ?", empresaID).First(&configuracion).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return models.Configuracion_oidc{EmpresaID: empresaID}, nil
+	}
+	return configuracion, err
+}
+
+// DominioPermitido verifica que el email y el dominio de Workspace (claim "hd") estén en la lista de la empresa.
+// Una cuenta sin "hd" (personal) se rechaza aunque el email sea del dominio, ya que el dominio no la administra.
+func DominioPermitido(configuracion models.Configuracion_oidc, identidad IdentidadOIDC) bool {
+	partes := strings.Split(identidad.Email, "@")
+	dominioEmail := partes[len(partes)-1]
+
+	for _, dominio := range strings.Split(configuracion.DominiosPermitidos, ",") {
+		dominio = strings.ToLower(strings.TrimSpace(dominio))
+		if dominio != "" && dominio == dominioEmail && identidad.Dominio == dominio {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,260 @@
+package services
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+	"v1_prefabricadas/models"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+const clienteOIDCPrueba = "cliente-prueba"
+
+// proveedorOIDCPrueba es un emisor OpenID Connect mínimo (descubrimiento, token y JWKS) para probar el callback
+type proveedorOIDCPrueba struct {
+	servidor *httptest.Server
+	llave    *rsa.PrivateKey
+	// claims arma las claims del id_token a partir del nonce de la autorización
+	claims func(nonce string) jwt.MapClaims
+	// códigos emitidos: code -> nonce y code_challenge de la autorización
+	nonces     map[string]string
+	challenges map[string]string
+}
+
+func nuevoProveedorOIDCPrueba(t *testing.T) *proveedorOIDCPrueba {
+	t.Helper()
+
+	llave, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := &proveedorOIDCPrueba{llave: llave, nonces: map[string]string{}, challenges: map[string]string{}}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 p.servidor.URL,
+			"authorization_endpoint": p.servidor.URL + "/authorize",
+			"token_endpoint":         p.servidor.URL + "/token",
+			"jwks_uri":               p.servidor.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"keys": []map[string]string{{
+				"kid": "llave-prueba",
+				"kty": "RSA",
+				"n":   base64.RawURLEncoding.EncodeToString(llave.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(llave.E)).Bytes()),
+			}},
+		})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		code := r.PostForm.Get("code")
+		nonce, ok := p.nonces[code]
+		verifier := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+		if !ok || base64.RawURLEncoding.EncodeToString(verifier[:]) != p.challenges[code] {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+			return
+		}
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, p.claims(nonce))
+		token.Header["kid"] = "llave-prueba"
+		idToken, err := token.SignedString(llave)
+		if err != nil {
+			t.Error(err)
+		}
+		json.NewEncoder(w).Encode(map[string]string{"id_token": idToken})
+	})
+	p.servidor = httptest.NewServer(mux)
+	t.Cleanup(p.servidor.Close)
+
+	p.claims = func(nonce string) jwt.MapClaims {
+		return jwt.MapClaims{
+			"iss":            p.servidor.URL,
+			"aud":            clienteOIDCPrueba,
+			"sub":            "12345",
+			"exp":            time.Now().Add(time.Minute).Unix(),
+			"email":          "Ana@Empresa.cl",
+			"email_verified": true,
+			"hd":             "empresa.cl",
+			"nonce":          nonce,
+		}
+	}
+	return p
+}
+
+// autorizar simula que el usuario inicia sesión en el proveedor y devuelve el code y el state del redirect
+func (p *proveedorOIDCPrueba) autorizar(t *testing.T, authorizationURL string) (string, string) {
+	t.Helper()
+	u, err := url.Parse(authorizationURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parametros := u.Query()
+	if parametros.Get("client_id") != clienteOIDCPrueba || parametros.Get("code_challenge_method") != "S256" {
+		t.Fatalf("URL de autorización inesperada: %s", authorizationURL)
+	}
+	code := "code-" + parametros.Get("state")
+	p.nonces[code] = parametros.Get("nonce")
+	p.challenges[code] = parametros.Get("code_challenge")
+	return code, parametros.Get("state")
+}
+
+func configurarOIDCPrueba(t *testing.T) *proveedorOIDCPrueba {
+	t.Helper()
+
+	_, privada, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(privada)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("JWT_PRIVATE_KEY", string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})))
+	if err := InicializarTokens(); err != nil {
+		t.Fatal(err)
+	}
+
+	p := nuevoProveedorOIDCPrueba(t)
+	t.Setenv("OIDC_ISSUER", p.servidor.URL)
+	t.Setenv("OIDC_CLIENT_ID", clienteOIDCPrueba)
+	t.Setenv("OIDC_REDIRECT_URL", "https://app.example/oidc/callback")
+
+	muOIDC.Lock()
+	descubrimientoOIDCCache, llavesOIDC = nil, nil
+	muOIDC.Unlock()
+	return p
+}
+
+func TestCompletarOIDC(t *testing.T) {
+	p := configurarOIDCPrueba(t)
+	claimsValidas := p.claims
+
+	casos := []struct {
+		nombre    string
+		proposito string
+		estado    func(estado string) string
+		claims    func(nonce string) jwt.MapClaims
+		err       error
+	}{
+		{nombre: "valido"},
+		{
+			nombre: "estado distinto",
+			estado: func(string) string { return "otro-estado" },
+			err:    ErrOIDCEstadoInvalido,
+		},
+		{
+			nombre:    "proposito distinto",
+			proposito: PropositoOIDCVincular,
+			err:       ErrOIDCEstadoInvalido,
+		},
+		{
+			nombre: "nonce distinto",
+			claims: func(nonce string) jwt.MapClaims {
+				claims := claimsValidas(nonce)
+				claims["nonce"] = "otro-nonce"
+				return claims
+			},
+			err: ErrOIDCTokenInvalido,
+		},
+		{
+			nombre: "audiencia distinta",
+			claims: func(nonce string) jwt.MapClaims {
+				claims := claimsValidas(nonce)
+				claims["aud"] = "otro-cliente"
+				return claims
+			},
+			err: ErrOIDCTokenInvalido,
+		},
+		{
+			nombre: "email sin verificar",
+			claims: func(nonce string) jwt.MapClaims {
+				claims := claimsValidas(nonce)
+				claims["email_verified"] = false
+				return claims
+			},
+			err: ErrOIDCEmailNoVerificado,
+		},
+	}
+
+	for _, caso := range casos {
+		t.Run(caso.nombre, func(t *testing.T) {
+			p.claims = claimsValidas
+			if caso.claims != nil {
+				p.claims = caso.claims
+			}
+
+			authorizationURL, estadoToken, err := IniciarOIDC(PropositoOIDCLogin, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			code, estado := p.autorizar(t, authorizationURL)
+			if caso.estado != nil {
+				estado = caso.estado(estado)
+			}
+			proposito := PropositoOIDCLogin
+			if caso.proposito != "" {
+				proposito = caso.proposito
+			}
+
+			identidad, _, err := CompletarOIDC(code, estado, estadoToken, proposito)
+			if caso.err != nil {
+				if !errors.Is(err, caso.err) {
+					t.Fatalf("se esperaba %v, se obtuvo %v", caso.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			esperada := IdentidadOIDC{Issuer: p.servidor.URL, Subject: "12345", Email: "ana@empresa.cl", Dominio: "empresa.cl"}
+			if identidad != esperada {
+				t.Fatalf("identidad %+v, se esperaba %+v", identidad, esperada)
+			}
+		})
+	}
+}
+
+func TestDominioPermitido(t *testing.T) {
+	configuracion := models.Configuracion_oidc{Habilitado: true, DominiosPermitidos: "empresa.cl,filial.cl"}
+
+	casos := []struct {
+		nombre    string
+		identidad IdentidadOIDC
+		permitido bool
+	}{
+		{"dominio permitido", IdentidadOIDC{Email: "ana@empresa.cl", Dominio: "empresa.cl"}, true},
+		{"segundo dominio", IdentidadOIDC{Email: "ana@filial.cl", Dominio: "filial.cl"}, true},
+		{"sin hd", IdentidadOIDC{Email: "ana@empresa.cl"}, false},
+		{"hd distinto", IdentidadOIDC{Email: "ana@empresa.cl", Dominio: "otra.cl"}, false},
+		{"email de otro dominio", IdentidadOIDC{Email: "ana@gmail.com", Dominio: "empresa.cl"}, false},
+	}
+
+	for _, caso := range casos {
+		t.Run(caso.nombre, func(t *testing.T) {
+			if permitido := DominioPermitido(configuracion, caso.identidad); permitido != caso.permitido {
+				t.Fatalf("DominioPermitido = %v, se esperaba %v", permitido, caso.permitido)
+			}
+		})
+	}
+
+	if DominioPermitido(models.Configuracion_oidc{}, IdentidadOIDC{Email: "ana@empresa.cl", Dominio: "empresa.cl"}) {
+		t.Fatal("sin dominios configurados no se debe permitir ninguna cuenta")
+	}
+}
@@ -0,0 +1,358 @@
+package services
+
+import (
+	"errors"
+	"log"
+	"strconv"
+	"time"
+	"v1_prefabricadas/configs"
+	"v1_prefabricadas/models"
+
+	"gorm.io/gorm"
+)
+
+const (
+	DiasRetencionPapeleraPorDefecto = 30
+	intervaloPurgaPapelera          = time.Hour
+)
+
+var (
+	ErrEntidadSinPapelera = errors.New("la entidad no admite papelera")
+	ErrPadreEnPapelera    = errors.New("el registro pertenece a otro que está eliminado, restaure primero ese registro")
+)
+
+// ErrEntidadEnUso indica que un catálogo global no se puede eliminar porque hay registros vigentes que lo usan
+type ErrEntidadEnUso struct {
+	Tabla string // Tabla de los registros que lo usan
+}
+
+func (e *ErrEntidadEnUso) Error() string {
+	return "el registro está en uso en " + e.Tabla
+}
+
+// Columna de otra tabla que apunta a un registro
+type referenciaPapelera struct {
+	tabla   string
+	columna string
+}
+
+// Entidad que puede enviarse a la papelera y su posición en el grafo de modelos
+type entidadPapelera struct {
+	modelo        func() interface{} // Puntero a un modelo vacío, para que las escrituras pasen por la auditoría
+	columnaNombre string             // Columna que identifica el registro en el listado de la papelera
+	padre         string             // Tabla del registro padre ("" si es la raíz, la empresa)
+	columnaPadre  string
+}
+
+// Árbol de la empresa. Los catálogos globales (categorias, estilos, tipos y roles) no están aquí: no
+// pertenecen a una empresa y su eliminación física arrastraría registros de todas; ver catalogosGlobales.
+var entidadesPapelera = map[string]entidadPapelera{
+	"empresa":                {func() interface{} { return &models.Empresa{} }, "nombre_empresa", "", ""},
+	"usuarios":               {func() interface{} { return &models.Usuario{} }, "CONCAT_WS(' ', primer_nombre, primer_apellido)", "empresa", "empresa_id"},
+	"contactos":              {func() interface{} { return &models.Contacto{} }, "email_laboral", "usuarios", "usuario_id"},
+	"roles_usuarios":         {func() interface{} { return &models.Rol_usuario{} }, "(SELECT roles.nombre_rol FROM roles WHERE roles.id = roles_usuarios.rol_id)", "usuarios", "usuario_id"},
+	"prefabricadas":          {func() interface{} { return &models.Prefabricada{} }, "nombre_prefabricada", "empresa", "empresa_id"},
+	"imagenes_prefabricadas": {func() interface{} { return &models.Imagen_prefabricada{} }, "image", "prefabricadas", "prefabricada_id"},
+	"caracteristicas":        {func() interface{} { return &models.Caracteristica{} }, "clave", "prefabricadas", "prefabricada_id"},
+	"precios":                {func() interface{} { return &models.Precio{} }, "nombre_precio", "prefabricadas", "prefabricada_id"},
+	"incluyes":               {func() interface{} { return &models.Incluye{} }, "nombre_incluye", "precios", "precio_id"},
+	"noticias":               {func() interface{} { return &models.Noticia{} }, "titulo_noticia", "empresa", "empresa_id"},
+	"imagenes_noticias":      {func() interface{} { return &models.Imagen_noticia{} }, "image", "noticias", "noticia_id"},
+	"portadas":               {func() interface{} { return &models.Portada{} }, "nombre_portada", "empresa", "empresa_id"},
+	"servicios":              {func() interface{} { return &models.Servicio{} }, "nombre_servicio", "empresa", "empresa_id"},
+	"redes":                  {func() interface{} { return &models.Red{} }, "red_social", "empresa", "empresa_id"},
+}
+
+// Orden fijo para recorrer los hijos de cada entidad
+var ordenEntidadesPapelera = []string{
+	"empresa", "usuarios", "contactos", "roles_usuarios",
+	"prefabricadas", "imagenes_prefabricadas", "caracteristicas", "precios", "incluyes",
+	"noticias", "imagenes_noticias", "portadas", "servicios", "redes",
+}
+
+// Registros que apuntan a una entidad de la papelera pero no se restauran con ella (sesiones,
+// API keys, configuración): se eliminan físicamente al vaciar la papelera, antes que el registro
+var dependientesPurga = map[string][]referenciaPapelera{
+	"empresa":  {{"api_keys", "empresa_id"}, {"configuraciones_oidc", "empresa_id"}, {"leads", "empresa_id"}},
+	"usuarios": {{"sesiones", "usuario_id"}},
+}
+
+// Catálogo compartido por todas las empresas, que se elimina lógicamente sin pasar por la papelera
+type catalogoGlobal struct {
+	modelo   func() interface{}
+	usos     []referenciaPapelera // Registros que lo usan: mientras haya alguno vigente no se puede eliminar
+	vinculos []referenciaPapelera // Tablas de relación que se eliminan lógicamente junto con el registro
+}
+
+var catalogosGlobales = map[string]catalogoGlobal{
+	"categorias": {
+		modelo:   func() interface{} { return &models.Categoria{} },
+		usos:     []referenciaPapelera{{"prefabricadas", "categoria_id"}},
+		vinculos: []referenciaPapelera{{"tipos_categorias", "categoria_id"}},
+	},
+	"tipos": {
+		modelo:   func() interface{} { return &models.Tipo{} },
+		usos:     []referenciaPapelera{{"prefabricadas", "tipo_id"}},
+		vinculos: []referenciaPapelera{{"tipos_categorias", "tipo_id"}},
+	},
+	"estilos": {
+		modelo: func() interface{} { return &models.Estilo{} },
+		usos:   []referenciaPapelera{{"prefabricadas", "estilo_id"}},
+	},
+	"roles": {
+		modelo: func() interface{} { return &models.Rol{} },
+		usos:   []referenciaPapelera{{"roles_usuarios", "rol_id"}},
+	},
+}
+
+// Tablas con slugs históricos que se eliminan junto con el registro
+var tablasConSlug = map[string]bool{EntidadSlugPrefabricada: true, EntidadSlugNoticia: true}
+
+// Función para eliminar lógicamente un registro junto con todos sus descendientes no eliminados
+// y registrarlo en la papelera de la empresa. La escritura debe hacerse con db.WithContext(c) para auditarla.
+func EnviarAPapelera(db *gorm.DB, entidad string, id, empresaID, usuarioID uint) error {
+	definicion, ok := entidadesPapelera[entidad]
+	if !ok {
+		return ErrEntidadSinPapelera
+	}
+
+	// Sin fracciones menores al milisegundo, para poder comparar con deleted_at al restaurar
+	ahora := time.Now().Truncate(time.Millisecond)
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := actualizarDeletedAt(tx, entidad, []uint{id}, ahora); err != nil {
+			return err
+		}
+
+		noEliminados := func(query *gorm.DB) *gorm.DB { return query.Where("deleted_at IS NULL") }
+		if err := recorrerDescendientes(tx, entidad, []uint{id}, noEliminados, func(tabla string, ids []uint) error {
+			return actualizarDeletedAt(tx, tabla, ids, ahora)
+		}); err != nil {
+			return err
+		}
+
+		var nombres []string
+		if err := tx.Table(entidad).Where("id = ?", id).Pluck(definicion.columnaNombre, &nombres).Error; err != nil {
+			return err
+		}
+
+		entrada := models.Papelera{
+			EmpresaID:   empresaID,
+			Entidad:     entidad,
+			EntidadID:   id,
+			EliminadoAt: ahora,
+		}
+		if len(nombres) > 0 {
+			entrada.Nombre = nombres[0]
+		}
+		if usuarioID != 0 {
+			entrada.UsuarioID = &usuarioID
+		}
+		return tx.Create(&entrada).Error
+	})
+}
+
+// Función para restaurar un elemento de la papelera junto con los descendientes que se eliminaron con él.
+// Los descendientes eliminados antes por separado siguen en la papelera.
+func RestaurarDePapelera(db *gorm.DB, entrada models.Papelera) error {
+	definicion, ok := entidadesPapelera[entrada.Entidad]
+	if !ok {
+		return ErrEntidadSinPapelera
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		// El padre debe existir y no estar eliminado
+		if definicion.padre != "" {
+			var padres int64
+			if err := tx.Table(definicion.padre).
+				Where("id = (?)", tx.Table(entrada.Entidad).Select(definicion.columnaPadre).Where("id = ?", entrada.EntidadID)).
+				Where("deleted_at IS NULL").
+				Count(&padres).Error; err != nil {
+				return err
+			}
+			if padres == 0 {
+				return ErrPadreEnPapelera
+			}
+		}
+
+		if err := actualizarDeletedAt(tx, entrada.Entidad, []uint{entrada.EntidadID}, nil); err != nil {
+			return err
+		}
+
+		eliminadosConElRegistro := func(query *gorm.DB) *gorm.DB { return query.Where("deleted_at = ?", entrada.EliminadoAt) }
+		if err := recorrerDescendientes(tx, entrada.Entidad, []uint{entrada.EntidadID}, eliminadosConElRegistro, func(tabla string, ids []uint) error {
+			return actualizarDeletedAt(tx, tabla, ids, nil)
+		}); err != nil {
+			return err
+		}
+
+		return tx.Delete(&entrada).Error
+	})
+}
+
+// Función para eliminar lógicamente un catálogo global (categoría, tipo, estilo o rol) junto con sus
+// vínculos. Devuelve *ErrEntidadEnUso si algún registro vigente lo usa, para no dejarlo huérfano.
+// La escritura debe hacerse con db.WithContext(c) para auditarla.
+func EliminarCatalogoGlobal(db *gorm.DB, tabla string, id uint) error {
+	catalogo, ok := catalogosGlobales[tabla]
+	if !ok {
+		return ErrEntidadSinPapelera
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		for _, uso := range catalogo.usos {
+			var count int64
+			if err := tx.Table(uso.tabla).Where(uso.columna+" = ? AND deleted_at IS NULL", id).Count(&count).Error; err != nil {
+				return err
+			}
+			if count > 0 {
+				return &ErrEntidadEnUso{Tabla: uso.tabla}
+			}
+		}
+
+		ahora := time.Now()
+		modelo := catalogo.modelo()
+		if err := tx.First(modelo, id).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(modelo).Update("deleted_at", ahora).Error; err != nil {
+			return err
+		}
+
+		for _, vinculo := range catalogo.vinculos {
+			if err := tx.Table(vinculo.tabla).Where(vinculo.columna+" = ? AND deleted_at IS NULL", id).Update("deleted_at", ahora).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Función para eliminar físicamente los elementos que llevan en la papelera más días que la retención
+// configurada, con todos sus descendientes. Devuelve la cantidad de elementos eliminados.
+func PurgarPapelera(ahora time.Time) (int, error) {
+	dias, err := DiasRetencionPapelera()
+	if err != nil {
+		return 0, err
+	}
+
+	var entradas []models.Papelera
+	if err := configs.DB.Where("eliminado_at < ?", ahora.AddDate(0, 0, -dias)).Order("id ASC").Find(&entradas).Error; err != nil {
+		return 0, err
+	}
+
+	for _, entrada := range entradas {
+		if err := configs.DB.Transaction(func(tx *gorm.DB) error {
+			return purgarEntrada(tx, entrada)
+		}); err != nil {
+			return 0, err
+		}
+	}
+	return len(entradas), nil
+}
+
+// Función para obtener los días que los elementos permanecen en la papelera antes de eliminarse
+func DiasRetencionPapelera() (int, error) {
+	valor, err := ObtenerConfiguracion(ConfigDiasRetencionPapelera, strconv.Itoa(DiasRetencionPapeleraPorDefecto))
+	if err != nil {
+		return 0, err
+	}
+	dias, err := strconv.Atoi(valor)
+	if err != nil || dias < 1 {
+		return DiasRetencionPapeleraPorDefecto, nil
+	}
+	return dias, nil
+}
+
+// Función para iniciar en segundo plano la eliminación periódica de los elementos vencidos de la papelera
+func IniciarPurgaPapelera() {
+	go func() {
+		ticker := time.NewTicker(intervaloPurgaPapelera)
+		defer ticker.Stop()
+		for {
+			if purgados, err := PurgarPapelera(time.Now()); err != nil {
+				log.Printf("Error al vaciar la papelera: %v", err)
+			} else if purgados > 0 {
+				log.Printf("Papelera: %d elementos eliminados definitivamente", purgados)
+			}
+			<-ticker.C
+		}
+	}()
+}
+
+// Eliminar físicamente el registro de la entrada y todos sus descendientes (desde las hojas),
+// junto con las entradas de la papelera y los slugs históricos que dependían de ellos
+func purgarEntrada(tx *gorm.DB, entrada models.Papelera) error {
+	eliminar := func(tabla string, ids []uint) error {
+		if err := tx.Where("entidad = ? AND entidad_id IN ?", tabla, ids).Delete(&models.Papelera{}).Error; err != nil {
+			return err
+		}
+		for _, dependiente := range dependientesPurga[tabla] {
+			if err := tx.Exec("DELETE FROM "+dependiente.tabla+" WHERE "+dependiente.columna+" IN ?", ids).Error; err != nil {
+				return err
+			}
+		}
+		if tablasConSlug[tabla] {
+			if err := tx.Where("entidad = ? AND entidad_id IN ?", tabla, ids).Delete(&models.Slug_historico{}).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Exec("DELETE FROM "+tabla+" WHERE id IN ?", ids).Error
+	}
+
+	// El registro pudo haberse restaurado y eliminado de nuevo con otra fecha
+	var vigente int64
+	if err := tx.Table(entrada.Entidad).Where("id = ? AND deleted_at = ?", entrada.EntidadID, entrada.EliminadoAt).Count(&vigente).Error; err != nil {
+		return err
+	}
+	if vigente == 0 {
+		return tx.Delete(&entrada).Error
+	}
+
+	todos := func(query *gorm.DB) *gorm.DB { return query }
+	if err := recorrerDescendientes(tx, entrada.Entidad, []uint{entrada.EntidadID}, todos, eliminar); err != nil {
+		return err
+	}
+	return eliminar(entrada.Entidad, []uint{entrada.EntidadID})
+}
+
+// Recorrer los descendientes de los registros indicados que cumplan el filtro, visitando
+// primero los más profundos
+func recorrerDescendientes(tx *gorm.DB, tabla string, ids []uint, filtro func(*gorm.DB) *gorm.DB, visitar func(tabla string, ids []uint) error) error {
+	for _, hija := range ordenEntidadesPapelera {
+		definicion := entidadesPapelera[hija]
+		if definicion.padre != tabla {
+			continue
+		}
+
+		var hijos []uint
+		if err := filtro(tx.Table(hija).Where(definicion.columnaPadre+" IN ?", ids)).Pluck("id", &hijos).Error; err != nil {
+			return err
+		}
+		if len(hijos) == 0 {
+			continue
+		}
+
+		if err := recorrerDescendientes(tx, hija, hijos, filtro, visitar); err != nil {
+			return err
+		}
+		if err := visitar(hija, hijos); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Cambiar deleted_at registro por registro a través de su modelo, para que cada cambio quede auditado
+func actualizarDeletedAt(tx *gorm.DB, tabla string, ids []uint, valor interface{}) error {
+	for _, id := range ids {
+		modelo := entidadesPapelera[tabla].modelo()
+		if err := tx.First(modelo, id).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(modelo).Update("deleted_at", valor).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package services
+
+import (
+	"v1_prefabricadas/configs"
+)
+
+// RolSuperAdministrador tiene todos los permisos de forma implícita
+const RolSuperAdministrador = "super_administrador"
+
+// SufijoPermisoPropio restringe un permiso a los recursos del propio usuario (ej. contactos:write:own)
+const SufijoPermisoPropio = ":own"
+
+// ObtenerPermisosUsuario devuelve el conjunto de permisos otorgados a los roles asignados al usuario.
+// Se resuelven por las asignaciones vigentes del usuario y no por los nombres de roles del token,
+// ya que un nombre de rol no identifica a un único rol.
+func ObtenerPermisosUsuario(usuarioID uint) (map[string]bool, error) {
+	permisos := map[string]bool{}
+	if usuarioID == 0 {
+		return permisos, nil
+	}
+
+	var nombres []string
+	if err := configs.DB.Table("permisos").
+		Distinct("permisos.nombre_permiso").
+		Joins("JOIN roles_permisos ON roles_permisos.permiso_id = permisos.id").
+		Joins("JOIN roles ON roles.id = roles_permisos.rol_id").
+		Joins("JOIN roles_usuarios ON roles_usuarios.rol_id = roles.id").
+		Where("roles_usuarios.usuario_id = ? AND roles_usuarios.deleted_at IS NULL", usuarioID).
+		Where("roles.deleted_at IS NULL AND permisos.deleted_at IS NULL").
+		Pluck("permisos.nombre_permiso", &nombres).Error; err != nil {
+		return nil, err
+	}
+
+	for _, nombre := range nombres {
+		permisos[nombre] = true
+	}
+	return permisos, nil
+}
+
+// ObtenerPermisosRolesID devuelve los permisos otorgados a los roles indicados por su ID
+// e indica si alguno de ellos es el super_administrador (que tiene todos los permisos de forma implícita)
+func ObtenerPermisosRolesID(rolIDs []uint) ([]string, bool, error) {
+	var nombres []string
+	if len(rolIDs) == 0 {
+		return nombres, false, nil
+	}
+
+	var superAdministrador int64
+	if err := configs.DB.Table("roles").
+		Where("id IN ? AND nombre_rol = ? AND deleted_at IS NULL", rolIDs, RolSuperAdministrador).
+		Count(&superAdministrador).Error; err != nil {
+		return nil, false, err
+	}
+
+	if err := configs.DB.Table("permisos").
+		Distinct("permisos.nombre_permiso").
+		Joins("JOIN roles_permisos ON roles_permisos.permiso_id = permisos.id").
+		Where("roles_permisos.rol_id IN ?", rolIDs).
+		Where("permisos.deleted_at IS NULL").
+		Pluck("permisos.nombre_permiso", &nombres).Error; err != nil {
+		return nil, false, err
+	}
+	return nombres, superAdministrador > 0, nil
+}
+
+// ObtenerRolesUsuario devuelve los IDs de los roles activos asignados al usuario
+func ObtenerRolesUsuario(usuarioID uint) ([]uint, error) {
+	var rolIDs []uint
+	err := configs.DB.Table("roles_usuarios").
+		Joins("JOIN roles ON roles.id = roles_usuarios.rol_id AND roles.deleted_at IS NULL").
+		Where("roles_usuarios.usuario_id = ? AND roles_usuarios.deleted_at IS NULL", usuarioID).
+		Pluck("roles_usuarios.rol_id", &rolIDs).Error
+	return rolIDs, err
+}
@@ -0,0 +1,255 @@
+package services
+
+import (
+	"bufio"
+	_ "embed"
+	"strconv"
+	"strings"
+	"unicode"
+	"v1_prefabricadas/models"
+
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// Claves de configuración de la política de contraseñas
+const (
+	ConfigPasswordLongitudMinima    = "seguridad.password_longitud_minima"
+	ConfigPasswordRequiereMayuscula = "seguridad.password_requiere_mayuscula"
+	ConfigPasswordRequiereMinuscula = "seguridad.password_requiere_minuscula"
+	ConfigPasswordRequiereNumero    = "seguridad.password_requiere_numero"
+	ConfigPasswordRequiereSimbolo   = "seguridad.password_requiere_simbolo"
+	ConfigPasswordHistorial         = "seguridad.password_historial"
+)
+
+// bcrypt solo considera los primeros 72 bytes de la contraseña
+const longitudMaximaPassword = 72
+
+// Lista offline de contraseñas comunes o filtradas en brechas conocidas
+//
+//go:embed data/passwords_comunes.txt
+var passwordsComunesTxt string
+
+var passwordsComunes = cargarPasswordsComunes(passwordsComunesTxt)
+
+func cargarPasswordsComunes(contenido string) map[string]bool {
+	lista := map[string]bool{}
+	scanner := bufio.NewScanner(strings.NewReader(contenido))
+	for scanner.Scan() {
+		linea := strings.TrimSpace(scanner.Text())
+		if linea == "" || strings.HasPrefix(linea, "#") {
+			continue
+		}
+		lista[strings.ToLower(linea)] = true
+	}
+	return lista
+}
+
+// PoliticaPassword reúne las reglas que debe cumplir toda contraseña nueva
+type PoliticaPassword struct {
+	LongitudMinima    int
+	RequiereMayuscula bool
+	RequiereMinuscula bool
+	RequiereNumero    bool
+	RequiereSimbolo   bool
+	Historial         int // Cantidad de contraseñas anteriores que no se pueden reutilizar
+}
+
+// Política usada mientras no se configure otra
+var politicaPasswordPorDefecto = PoliticaPassword{
+	LongitudMinima:    8,
+	RequiereMayuscula: true,
+	RequiereMinuscula: true,
+	RequiereNumero:    true,
+	RequiereSimbolo:   false,
+	Historial:         5,
+}
+
+// ErrPoliticaPassword indica los motivos por los que una contraseña fue rechazada
+type ErrPoliticaPassword struct {
+	Motivos []string
+}
+
+func (e *ErrPoliticaPassword) Error() string {
+	return "la contraseña no cumple la política de seguridad: " + strings.Join(e.Motivos, "; ")
+}
+
+// ObtenerPoliticaPassword lee la política configurada, usando los valores por defecto para las claves ausentes
+func ObtenerPoliticaPassword() (PoliticaPassword, error) {
+	politica := politicaPasswordPorDefecto
+	var err error
+
+	enteros := map[string]*int{
+		ConfigPasswordLongitudMinima: &politica.LongitudMinima,
+		ConfigPasswordHistorial:      &politica.Historial,
+	}
+	for clave, destino := range enteros {
+		valor, err := ObtenerConfiguracion(clave, strconv.Itoa(*destino))
+		if err != nil {
+			return politica, err
+		}
+		if n, err := strconv.Atoi(valor); err == nil && n >= 0 {
+			*destino = n
+		}
+	}
+
+	booleanos := map[string]*bool{
+		ConfigPasswordRequiereMayuscula: &politica.RequiereMayuscula,
+		ConfigPasswordRequiereMinuscula: &politica.RequiereMinuscula,
+		ConfigPasswordRequiereNumero:    &politica.RequiereNumero,
+		ConfigPasswordRequiereSimbolo:   &politica.RequiereSimbolo,
+	}
+	for clave, destino := range booleanos {
+		if *destino, err = ObtenerConfiguracionBool(clave, *destino); err != nil {
+			return politica, err
+		}
+	}
+
+	return politica, nil
+}
+
+// GuardarPoliticaPassword persiste la política de contraseñas en la configuración del sistema
+func GuardarPoliticaPassword(politica PoliticaPassword) error {
+	valores := map[string]string{
+		ConfigPasswordLongitudMinima:    strconv.Itoa(politica.LongitudMinima),
+		ConfigPasswordHistorial:         strconv.Itoa(politica.Historial),
+		ConfigPasswordRequiereMayuscula: strconv.FormatBool(politica.RequiereMayuscula),
+		ConfigPasswordRequiereMinuscula: strconv.FormatBool(politica.RequiereMinuscula),
+		ConfigPasswordRequiereNumero:    strconv.FormatBool(politica.RequiereNumero),
+		ConfigPasswordRequiereSimbolo:   strconv.FormatBool(politica.RequiereSimbolo),
+	}
+	for clave, valor := range valores {
+		if err := GuardarConfiguracion(clave, valor); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ValidarPassword verifica una contraseña nueva contra la política vigente.
+// credencialID es 0 cuando la credencial aún no existe y no hay historial que revisar.
+// Devuelve *ErrPoliticaPassword si la contraseña es rechazada.
+func ValidarPassword(db *gorm.DB, credencialID uint, password string) error {
+	politica, err := ObtenerPoliticaPassword()
+	if err != nil {
+		return err
+	}
+
+	var motivos []string
+	if len([]rune(password)) < politica.LongitudMinima {
+		motivos = append(motivos, "debe tener al menos "+strconv.Itoa(politica.LongitudMinima)+" caracteres")
+	}
+	if len(password) > longitudMaximaPassword {
+		motivos = append(motivos, "no puede superar los "+strconv.Itoa(longitudMaximaPassword)+" bytes")
+	}
+
+	var mayuscula, minuscula, numero, simbolo bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			mayuscula = true
+		case unicode.IsLower(r):
+			minuscula = true
+		case unicode.IsDigit(r):
+			numero = true
+		default:
+			simbolo = true
+		}
+	}
+	if politica.RequiereMayuscula && !mayuscula {
+		motivos = append(motivos, "debe contener al menos una letra mayúscula")
+	}
+	if politica.RequiereMinuscula && !minuscula {
+		motivos = append(motivos, "debe contener al menos una letra minúscula")
+	}
+	if politica.RequiereNumero && !numero {
+		motivos = append(motivos, "debe contener al menos un número")
+	}
+	if politica.RequiereSimbolo && !simbolo {
+		motivos = append(motivos, "debe contener al menos un símbolo")
+	}
+
+	if passwordsComunes[strings.ToLower(password)] {
+		motivos = append(motivos, "es una contraseña demasiado común o aparece en filtraciones conocidas")
+	}
+
+	if credencialID != 0 && politica.Historial > 0 {
+		reutilizada, err := passwordEnHistorial(db, credencialID, password, politica.Historial)
+		if err != nil {
+			return err
+		}
+		if reutilizada {
+			motivos = append(motivos, "no puede ser igual a ninguna de las últimas "+strconv.Itoa(politica.Historial)+" contraseñas")
+		}
+	}
+
+	if len(motivos) > 0 {
+		return &ErrPoliticaPassword{Motivos: motivos}
+	}
+	return nil
+}
+
+// HashearPasswordValidada valida la contraseña contra la política vigente y devuelve su hash.
+// Toda contraseña que se asigna a una credencial debe pasar por aquí para no saltear la política.
+func HashearPasswordValidada(db *gorm.DB, credencialID uint, password string) (string, error) {
+	if err := ValidarPassword(db, credencialID, password); err != nil {
+		return "", err
+	}
+	return HashPassword(password)
+}
+
+// passwordEnHistorial compara la contraseña con la actual y con las últimas usadas por la credencial
+func passwordEnHistorial(db *gorm.DB, credencialID uint, password string, cantidad int) (bool, error) {
+	var hashes []string
+	if err := db.Model(&models.Credencial{}).Where("id = ?", credencialID).Pluck("password", &hashes).Error; err != nil {
+		return false, err
+	}
+
+	var historial []string
+	if err := db.Model(&models.Historial_password{}).
+		Where("credencial_id = ?", credencialID).
+		Order("created_at DESC, id DESC").
+		Limit(cantidad).
+		Pluck("password_hash", &historial).Error; err != nil {
+		return false, err
+	}
+	hashes = append(hashes, historial...)
+
+	for _, hash := range hashes {
+		if bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// RegistrarHistorialPassword guarda el hash de la contraseña asignada y descarta las entradas
+// que ya no se necesitan según la política vigente
+func RegistrarHistorialPassword(db *gorm.DB, credencialID uint, passwordHash string) error {
+	if err := db.Create(&models.Historial_password{
+		CredencialID: credencialID,
+		PasswordHash: passwordHash,
+	}).Error; err != nil {
+		return err
+	}
+
+	politica, err := ObtenerPoliticaPassword()
+	if err != nil {
+		return err
+	}
+
+	var conservar []uint
+	if err := db.Model(&models.Historial_password{}).
+		Where("credencial_id = ?", credencialID).
+		Order("created_at DESC, id DESC").
+		Limit(politica.Historial).
+		Pluck("id", &conservar).Error; err != nil {
+		return err
+	}
+
+	limpieza := db.Where("credencial_id = ?", credencialID)
+	if len(conservar) > 0 {
+		limpieza = limpieza.Where("id NOT IN ?", conservar)
+	}
+	return limpieza.Delete(&models.Historial_password{}).Error
+}
@@ -0,0 +1,136 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+	"v1_prefabricadas/configs"
+
+	"gorm.io/gorm"
+)
+
+// Estados de publicación de prefabricadas, noticias y portadas
+const (
+	EstadoBorrador   = "draft"
+	EstadoProgramado = "scheduled"
+	EstadoPublicado  = "published"
+	EstadoArchivado  = "archived"
+)
+
+const intervaloPublicacionPorDefecto = time.Minute
+
+var EstadosPublicacion = []string{EstadoBorrador, EstadoProgramado, EstadoPublicado, EstadoArchivado}
+
+// Tablas con estado de publicación
+var tablasPublicacion = []string{"prefabricadas", "noticias", "portadas"}
+
+var ErrPublicacionInvalida = errors.New("publicación inválida")
+
+// Estado de publicación y fechas de publicación y retiro de un registro
+type Publicacion struct {
+	Estado      string
+	PublishAt   *time.Time
+	UnpublishAt *time.Time
+}
+
+// Función para validar el estado de publicación solicitado y completar los datos que faltan:
+//   - sin estado: programado si publish_at es futuro, publicado si ya pasó y borrador si no se indica
+//   - scheduled requiere un publish_at futuro
+//   - published sin publish_at se publica ahora
+func ResolverPublicacion(estado string, publishAt, unpublishAt *time.Time, ahora time.Time) (Publicacion, error) {
+	publicacion := Publicacion{Estado: estado, PublishAt: publishAt, UnpublishAt: unpublishAt}
+
+	if publishAt != nil && unpublishAt != nil && !unpublishAt.After(*publishAt) {
+		return publicacion, fmt.Errorf("%w: unpublish_at debe ser posterior a publish_at", ErrPublicacionInvalida)
+	}
+
+	switch estado {
+	case "":
+		publicacion.Estado = EstadoBorrador
+		if publishAt != nil {
+			publicacion.Estado = EstadoPublicado
+			if publishAt.After(ahora) {
+				publicacion.Estado = EstadoProgramado
+			}
+		}
+	case EstadoProgramado:
+		if publishAt == nil || !publishAt.After(ahora) {
+			return publicacion, fmt.Errorf("%w: para programar la publicación indique un publish_at futuro", ErrPublicacionInvalida)
+		}
+	case EstadoPublicado:
+		if publishAt == nil {
+			publicacion.PublishAt = &ahora
+		} else if publishAt.After(ahora) {
+			return publicacion, fmt.Errorf("%w: publish_at es futuro, use el estado %s", ErrPublicacionInvalida, EstadoProgramado)
+		}
+	case EstadoBorrador, EstadoArchivado:
+	default:
+		return publicacion, fmt.Errorf("%w: estado debe ser uno de %s", ErrPublicacionInvalida, strings.Join(EstadosPublicacion, ", "))
+	}
+
+	if (publicacion.Estado == EstadoPublicado || publicacion.Estado == EstadoProgramado) && unpublishAt != nil && !unpublishAt.After(ahora) {
+		return publicacion, fmt.Errorf("%w: unpublish_at debe ser futuro", ErrPublicacionInvalida)
+	}
+
+	return publicacion, nil
+}
+
+// Función para limitar una consulta a los registros visibles públicamente: publicados, o programados cuya
+// fecha ya llegó, y que no han sido retirados. No depende de que el programador ya haya cambiado el estado.
+func FiltrarPublicados(query *gorm.DB, tabla string) *gorm.DB {
+	ahora := time.Now()
+	return query.Where("("+tabla+".estado = ? OR ("+tabla+".estado = ? AND "+tabla+".publish_at <= ?)) AND ("+tabla+".unpublish_at IS NULL OR "+tabla+".unpublish_at > ?)",
+		EstadoPublicado, EstadoProgramado, ahora, ahora)
+}
+
+// Función para publicar los registros programados cuya fecha llegó y archivar los que llegaron a su fecha de retiro
+func ActualizarEstadosPublicacion(ahora time.Time) error {
+	for _, tabla := range tablasPublicacion {
+		publicados := configs.DB.Table(tabla).
+			Where("estado = ? AND publish_at <= ? AND deleted_at IS NULL", EstadoProgramado, ahora).
+			Updates(map[string]interface{}{"estado": EstadoPublicado, "updated_at": ahora})
+		if publicados.Error != nil {
+			return publicados.Error
+		}
+
+		archivados := configs.DB.Table(tabla).
+			Where("estado IN ? AND unpublish_at <= ? AND deleted_at IS NULL", []string{EstadoPublicado, EstadoProgramado}, ahora).
+			Updates(map[string]interface{}{"estado": EstadoArchivado, "updated_at": ahora})
+		if archivados.Error != nil {
+			return archivados.Error
+		}
+
+		if publicados.RowsAffected > 0 || archivados.RowsAffected > 0 {
+			log.Printf("Publicación programada en %s: %d publicados, %d archivados", tabla, publicados.RowsAffected, archivados.RowsAffected)
+		}
+	}
+	return nil
+}
+
+// Función para iniciar en segundo plano el programador de publicaciones. El intervalo se configura con
+// PUBLICACION_INTERVALO (ej. "30s" o "5m"); por defecto se revisa cada minuto.
+func IniciarProgramadorPublicacion() {
+	intervalo := intervaloPublicacionPorDefecto
+	if valor := os.Getenv("PUBLICACION_INTERVALO"); valor != "" {
+		d, err := time.ParseDuration(valor)
+		if err != nil || d <= 0 {
+			log.Printf("PUBLICACION_INTERVALO inválido (%q), se usa %s", valor, intervaloPublicacionPorDefecto)
+		} else {
+			intervalo = d
+		}
+	}
+
+	go func() {
+		ticker := time.NewTicker(intervalo)
+		defer ticker.Stop()
+		for {
+			if err := ActualizarEstadosPublicacion(time.Now()); err != nil {
+				log.Printf("Error al actualizar los estados de publicación: %v", err)
+			}
+			<-ticker.C
+		}
+	}()
+}
@@ -0,0 +1,92 @@
+package services
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"time"
+	"v1_prefabricadas/configs"
+	"v1_prefabricadas/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Tiempo de validez del enlace de recuperación de contraseña
+const DuracionTokenRecuperacion = 15 * time.Minute
+
+// URL del frontend usada cuando no se define RESET_PASSWORD_URL
+const urlRecuperacionPorDefecto = "https://vifrontendcasasemilia-production.up.railway.app/reset-password/"
+
+var ErrTokenRecuperacionInvalido = errors.New("token inválido o expirado")
+
+// CrearTokenRecuperacion invalida los tokens anteriores del usuario y emite uno nuevo.
+// Devuelve el token en texto plano, que solo viaja en el email.
+func CrearTokenRecuperacion(usuarioID uint) (string, error) {
+	token, err := GenerarTokenAleatorio()
+	if err != nil {
+		return "", err
+	}
+
+	err = configs.DB.Transaction(func(tx *gorm.DB) error {
+		if err := InvalidarRecuperacionesUsuario(tx, usuarioID); err != nil {
+			return err
+		}
+		return tx.Create(&models.Recuperacion{
+			TokenHash: HashToken(token),
+			UsuarioID: usuarioID,
+			ExpiresAt: time.Now().Add(DuracionTokenRecuperacion),
+		}).Error
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return token, nil
+}
+
+// ConsumirTokenRecuperacion valida el token y lo marca como usado dentro de la transacción recibida,
+// de modo que no pueda usarse dos veces aunque lleguen solicitudes simultáneas
+func ConsumirTokenRecuperacion(tx *gorm.DB, token string) (models.Recuperacion, error) {
+	var recuperacion models.Recuperacion
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("token = ?", HashToken(token)).
+		First(&recuperacion).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return recuperacion, ErrTokenRecuperacionInvalido
+	}
+	if err != nil {
+		return recuperacion, err
+	}
+
+	if recuperacion.InvalidadoAt != nil || time.Now().After(recuperacion.ExpiresAt) {
+		return recuperacion, ErrTokenRecuperacionInvalido
+	}
+
+	now := time.Now()
+	recuperacion.InvalidadoAt = &now
+	if err := tx.Save(&recuperacion).Error; err != nil {
+		return recuperacion, err
+	}
+
+	return recuperacion, nil
+}
+
+// InvalidarRecuperacionesUsuario invalida todos los tokens de recuperación vigentes del usuario
+func InvalidarRecuperacionesUsuario(db *gorm.DB, usuarioID uint) error {
+	return db.Model(&models.Recuperacion{}).
+		Where("usuario_id = ? AND invalidado_at IS NULL", usuarioID).
+		Update("invalidado_at", time.Now()).Error
+}
+
+// URLRecuperacion arma el enlace que se envía por email a partir de RESET_PASSWORD_URL
+func URLRecuperacion(token string) string {
+	base := os.Getenv("RESET_PASSWORD_URL")
+	if base == "" {
+		base = urlRecuperacionPorDefecto
+	}
+	if !strings.HasSuffix(base, "/") {
+		base += "/"
+	}
+	return base + token
+}
@@ -7,9 +7,12 @@ import (
 	"io"
 	"log"
 	"net/http"
+	"net/url"
 	"os"
+	"path"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
@@ -80,3 +83,102 @@ func UploadToS3(file io.Reader, folder string, filename string) (string, error)
 	url := fmt.Sprintf("https://%s.s3.%s.amazonaws.com/%s", "bucket-casas-emilia", os.Getenv("AWS_REGION"), key)
 	return url, nil
 }
+
+// CopiarEnS3 copia un archivo ya subido a una nueva clave dentro de la carpeta indicada y devuelve la URL de la copia.
+// Si la URL no corresponde a un archivo del bucket la devuelve sin cambios, ya que no hay nada que copiar.
+func CopiarEnS3(urlArchivo string, folder string) (string, error) {
+	prefijo := fmt.Sprintf("https://%s.s3.%s.amazonaws.com/", "bucket-casas-emilia", os.Getenv("AWS_REGION"))
+	if !strings.HasPrefix(urlArchivo, prefijo) {
+		return urlArchivo, nil
+	}
+	origen := strings.TrimPrefix(urlArchivo, prefijo)
+
+	// Cargar la configuración de AWS
+	cfg, err := loadAWSConfig()
+	if err != nil {
+		log.Printf("Error al cargar la configuración de AWS: %v", err)
+		return "", err
+	}
+
+	// Crear el cliente de S3
+	s3Client := s3.NewFromConfig(cfg)
+
+	// Nueva clave con un prefijo único para no sobrescribir el original ni otras copias
+	key := folder + "/" + fmt.Sprintf("%d-%s", time.Now().UnixNano(), path.Base(origen))
+
+	_, err = s3Client.CopyObject(context.TODO(), &s3.CopyObjectInput{
+		Bucket:     aws.String("bucket-casas-emilia"),
+		Key:        aws.String(key),
+		CopySource: aws.String("bucket-casas-emilia/" + (&url.URL{Path: origen}).EscapedPath()),
+	})
+	if err != nil {
+		log.Printf("Error al copiar el archivo en S3: %v", err)
+		return "", fmt.Errorf("no se pudo copiar el archivo en S3: %v", err)
+	}
+
+	return prefijo + key, nil
+}
+
+// EliminarDeS3 borra un archivo del bucket a partir de su URL.
+// Si la URL no corresponde a un archivo del bucket no hace nada.
+func EliminarDeS3(urlArchivo string) error {
+	prefijo := fmt.Sprintf("https://%s.s3.%s.amazonaws.com/", "bucket-casas-emilia", os.Getenv("AWS_REGION"))
+	if !strings.HasPrefix(urlArchivo, prefijo) {
+		return nil
+	}
+
+	// Cargar la configuración de AWS
+	cfg, err := loadAWSConfig()
+	if err != nil {
+		log.Printf("Error al cargar la configuración de AWS: %v", err)
+		return err
+	}
+
+	// Crear el cliente de S3
+	s3Client := s3.NewFromConfig(cfg)
+
+	_, err = s3Client.DeleteObject(context.TODO(), &s3.DeleteObjectInput{
+		Bucket: aws.String("bucket-casas-emilia"),
+		Key:    aws.String(strings.TrimPrefix(urlArchivo, prefijo)),
+	})
+	if err != nil {
+		return fmt.Errorf("no se pudo eliminar el archivo de S3: %v", err)
+	}
+	return nil
+}
+
+// DescargarDeS3 obtiene el contenido de un archivo del bucket a partir de su URL, hasta tamanoMaximo bytes
+func DescargarDeS3(urlArchivo string, tamanoMaximo int64) ([]byte, error) {
+	prefijo := fmt.Sprintf("https://%s.s3.%s.amazonaws.com/", "bucket-casas-emilia", os.Getenv("AWS_REGION"))
+	if !strings.HasPrefix(urlArchivo, prefijo) {
+		return nil, fmt.Errorf("el archivo %s no pertenece al bucket", urlArchivo)
+	}
+
+	// Cargar la configuración de AWS
+	cfg, err := loadAWSConfig()
+	if err != nil {
+		log.Printf("Error al cargar la configuración de AWS: %v", err)
+		return nil, err
+	}
+
+	// Crear el cliente de S3
+	s3Client := s3.NewFromConfig(cfg)
+
+	objeto, err := s3Client.GetObject(context.TODO(), &s3.GetObjectInput{
+		Bucket: aws.String("bucket-casas-emilia"),
+		Key:    aws.String(strings.TrimPrefix(urlArchivo, prefijo)),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("no se pudo descargar el archivo de S3: %v", err)
+	}
+	defer objeto.Body.Close()
+
+	contenido, err := io.ReadAll(io.LimitReader(objeto.Body, tamanoMaximo+1))
+	if err != nil {
+		return nil, fmt.Errorf("no se pudo leer el archivo de S3: %v", err)
+	}
+	if int64(len(contenido)) > tamanoMaximo {
+		return nil, fmt.Errorf("el archivo %s supera el tamaño máximo", urlArchivo)
+	}
+	return contenido, nil
+}
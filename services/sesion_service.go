@@ -0,0 +1,180 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"time"
+	"v1_prefabricadas/configs"
+	"v1_prefabricadas/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Duración de los tokens emitidos al iniciar sesión
+const (
+	DuracionAccessToken  = 15 * time.Minute
+	DuracionRefreshToken = 30 * 24 * time.Hour
+)
+
+// Cada cuánto se actualiza last_seen_at, para no escribir en la base de datos en cada request
+const intervaloActividadSesion = time.Minute
+
+var (
+	ErrRefreshTokenInvalido    = errors.New("refresh token inválido o expirado")
+	ErrRefreshTokenReutilizado = errors.New("refresh token reutilizado, sesión revocada")
+)
+
+// GenerarTokenAleatorio genera un token opaco de 32 bytes codificado en base64 URL
+func GenerarTokenAleatorio() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// HashToken devuelve el SHA-256 en hexadecimal de un token opaco, que es lo que se guarda en la base de datos
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// CrearSesion registra una nueva sesión para el dispositivo y devuelve el refresh token en texto plano
+func CrearSesion(usuarioID uint, userAgent, ip string) (models.Sesion, string, error) {
+	refreshToken, err := GenerarTokenAleatorio()
+	if err != nil {
+		return models.Sesion{}, "", err
+	}
+
+	now := time.Now()
+	sesion := models.Sesion{
+		RefreshTokenHash: HashToken(refreshToken),
+		UserAgent:        userAgent,
+		IP:               ip,
+		ExpiresAt:        now.Add(DuracionRefreshToken),
+		LastSeenAt:       &now,
+		UsuarioID:        usuarioID,
+	}
+	if err := configs.DB.Create(&sesion).Error; err != nil {
+		return models.Sesion{}, "", err
+	}
+
+	return sesion, refreshToken, nil
+}
+
+// RotarRefreshToken valida el refresh token recibido y lo reemplaza por uno nuevo dentro de la misma sesión.
+// Si se presenta un token que ya fue rotado se asume que fue robado y la sesión completa se revoca.
+func RotarRefreshToken(refreshToken, userAgent, ip string) (models.Sesion, string, error) {
+	var sesion models.Sesion
+	var nuevoToken string
+	hash := HashToken(refreshToken)
+
+	err := configs.DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("refresh_token_hash = ?", hash).
+			First(&sesion).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			// Verificar si corresponde al token anterior de alguna sesión
+			if err := tx.Where("refresh_token_anterior_hash = ?", hash).First(&sesion).Error; err == nil {
+				return ErrRefreshTokenReutilizado
+			}
+			return ErrRefreshTokenInvalido
+		}
+		if err != nil {
+			return err
+		}
+
+		if sesion.RevokedAt != nil || time.Now().After(sesion.ExpiresAt) {
+			return ErrRefreshTokenInvalido
+		}
+
+		nuevoToken, err = GenerarTokenAleatorio()
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		sesion.RefreshTokenAnteriorHash = sesion.RefreshTokenHash
+		sesion.RefreshTokenHash = HashToken(nuevoToken)
+		sesion.ExpiresAt = now.Add(DuracionRefreshToken)
+		sesion.UserAgent = userAgent
+		sesion.IP = ip
+		sesion.LastSeenAt = &now
+		return tx.Save(&sesion).Error
+	})
+	if err != nil {
+		// La revocación por reutilización se hace fuera de la transacción para que no se deshaga con el rollback
+		if errors.Is(err, ErrRefreshTokenReutilizado) {
+			RevocarSesion(sesion.ID, sesion.UsuarioID)
+		}
+		return models.Sesion{}, "", err
+	}
+
+	return sesion, nuevoToken, nil
+}
+
+// RevocarSesion revoca una sesión específica del usuario
+func RevocarSesion(sesionID uint, usuarioID uint) error {
+	return configs.DB.Model(&models.Sesion{}).
+		Where("id = ? AND usuario_id = ? AND revoked_at IS NULL", sesionID, usuarioID).
+		Update("revoked_at", time.Now()).Error
+}
+
+// RevocarSesionesUsuario revoca todas las sesiones activas de un usuario (despido, cambio de contraseña, etc.)
+func RevocarSesionesUsuario(db *gorm.DB, usuarioID uint) error {
+	return db.Model(&models.Sesion{}).
+		Where("usuario_id = ? AND revoked_at IS NULL", usuarioID).
+		Update("revoked_at", time.Now()).Error
+}
+
+// RevocarSesionesEmpresa revoca las sesiones activas de todos los usuarios de una empresa
+func RevocarSesionesEmpresa(db *gorm.DB, empresaID uint) error {
+	return db.Model(&models.Sesion{}).
+		Where("usuario_id IN (?) AND revoked_at IS NULL", db.Model(&models.Usuario{}).Select("id").Where("empresa_id = ?", empresaID)).
+		Update("revoked_at", time.Now()).Error
+}
+
+// SesionActiva verifica que la sesión asociada a un access token siga vigente y registra su actividad
+func SesionActiva(sesionID uint, usuarioID uint, ip string) (bool, error) {
+	var sesion models.Sesion
+	err := configs.DB.
+		Where("id = ? AND usuario_id = ? AND revoked_at IS NULL AND expires_at > ?", sesionID, usuarioID, time.Now()).
+		First(&sesion).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	now := time.Now()
+	if sesion.LastSeenAt == nil || now.Sub(*sesion.LastSeenAt) > intervaloActividadSesion || sesion.IP != ip {
+		if err := configs.DB.Model(&sesion).UpdateColumns(map[string]interface{}{
+			"last_seen_at": now,
+			"ip":           ip,
+		}).Error; err != nil {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// Orden de los listados de sesiones, la más reciente primero
+const OrdenSesiones = "last_seen_at DESC, id DESC"
+
+// ConsultaSesionesActivas devuelve la consulta de las sesiones vigentes de un usuario, para paginarla
+func ConsultaSesionesActivas(usuarioID uint) *gorm.DB {
+	return configs.DB.Model(&models.Sesion{}).
+		Where("usuario_id = ? AND revoked_at IS NULL AND expires_at > ?", usuarioID, time.Now())
+}
+
+// RevocarOtrasSesiones revoca todas las sesiones del usuario excepto la indicada
+func RevocarOtrasSesiones(usuarioID uint, sesionID uint) error {
+	return configs.DB.Model(&models.Sesion{}).
+		Where("usuario_id = ? AND id <> ? AND revoked_at IS NULL", usuarioID, sesionID).
+		Update("revoked_at", time.Now()).Error
+}
@@ -0,0 +1,208 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"v1_prefabricadas/configs"
+	"v1_prefabricadas/models"
+	"v1_prefabricadas/utils"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Entidades con slug (nombre de su tabla)
+const (
+	EntidadSlugPrefabricada = "prefabricadas"
+	EntidadSlugNoticia      = "noticias"
+	EntidadSlugCategoria    = "categorias"
+)
+
+const largoMaximoSlug = 150 // Deja espacio para el sufijo numérico dentro de las 191 letras de la columna
+
+// Columna desde la que se genera el slug de cada entidad
+var columnasSlug = map[string]string{
+	EntidadSlugPrefabricada: "nombre_prefabricada",
+	EntidadSlugNoticia:      "titulo_noticia",
+	EntidadSlugCategoria:    "nombre_categoria",
+}
+
+var (
+	ErrSlugEnUso    = errors.New("el slug ya está en uso")
+	ErrSlugInvalido = errors.New("el slug debe contener al menos una letra o número")
+)
+
+// ErrorSlugDuplicado convierte el error de clave duplicada del índice único del slug en ErrSlugEnUso,
+// como cuando dos solicitudes simultáneas generan el mismo slug. Los demás errores se devuelven sin cambios.
+func ErrorSlugDuplicado(db *gorm.DB, err error) error {
+	if traductor, ok := db.Dialector.(gorm.ErrorTranslator); ok && err != nil && errors.Is(traductor.Translate(err), gorm.ErrDuplicatedKey) {
+		return ErrSlugEnUso
+	}
+	return err
+}
+
+// Slug vigente de una entidad encontrada por un slug actual o antiguo
+type SlugResuelto struct {
+	ID   uint
+	Slug string
+}
+
+// Función para generar un slug único a partir de un texto. Si ya está en uso por otro registro
+// (o fue usado antes por otro registro) se agrega un sufijo: casa-lago, casa-lago-2, casa-lago-3...
+// empresaID es 0 para las categorías y excluirID es el ID del propio registro al actualizarlo.
+func GenerarSlugUnico(db *gorm.DB, entidad string, empresaID uint, texto string, excluirID uint) (string, error) {
+	base := slugBase(entidad, texto)
+	for i := 1; ; i++ {
+		candidato := base
+		if i > 1 {
+			candidato = fmt.Sprintf("%s-%d", base, i)
+		}
+		enUso, err := slugEnUso(db, entidad, empresaID, candidato, excluirID)
+		if err != nil {
+			return "", err
+		}
+		if !enUso {
+			return candidato, nil
+		}
+	}
+}
+
+// Función para validar un slug indicado manualmente. Devuelve el slug normalizado,
+// ErrSlugInvalido si queda vacío o ErrSlugEnUso si lo usa (o lo usó) otro registro.
+func ValidarSlug(db *gorm.DB, entidad string, empresaID uint, slug string, excluirID uint) (string, error) {
+	if utils.GenerarSlug(slug, largoMaximoSlug) == "" {
+		return "", ErrSlugInvalido
+	}
+	slug = slugBase(entidad, slug)
+
+	enUso, err := slugEnUso(db, entidad, empresaID, slug, excluirID)
+	if err != nil {
+		return "", err
+	}
+	if enUso {
+		return "", ErrSlugEnUso
+	}
+	return slug, nil
+}
+
+// Función para guardar el slug anterior de un registro cuando cambia, de modo que las URLs
+// antiguas sigan resolviendo. Si el nuevo slug ya había sido usado por el registro, deja de ser antiguo.
+func RegistrarCambioSlug(db *gorm.DB, entidad string, empresaID, id uint, anterior *string, nuevo string) error {
+	if anterior == nil || *anterior == "" || *anterior == nuevo {
+		return nil
+	}
+
+	historico := models.Slug_historico{
+		Entidad:   entidad,
+		EmpresaID: empresaID,
+		Slug:      *anterior,
+		EntidadID: id,
+	}
+	if err := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&historico).Error; err != nil {
+		return err
+	}
+
+	return db.Where("entidad = ? AND empresa_id = ? AND slug = ? AND entidad_id = ?", entidad, empresaID, nuevo, id).
+		Delete(&models.Slug_historico{}).Error
+}
+
+// Función para encontrar un registro no eliminado por su slug vigente o por uno antiguo.
+// Devuelve gorm.ErrRecordNotFound si ningún registro usa ni usó el slug.
+func ResolverSlug(entidad string, empresaID uint, slug string) (SlugResuelto, error) {
+	var resuelto SlugResuelto
+
+	if err := consultaSlug(configs.DB, entidad, empresaID).
+		Select("id, slug").
+		Where("slug = ? AND deleted_at IS NULL", slug).
+		Take(&resuelto).Error; err == nil || !errors.Is(err, gorm.ErrRecordNotFound) {
+		return resuelto, err
+	}
+
+	var historico models.Slug_historico
+	if err := configs.DB.Where("entidad = ? AND empresa_id = ? AND slug = ?", entidad, empresaID, slug).
+		First(&historico).Error; err != nil {
+		return resuelto, err
+	}
+
+	err := consultaSlug(configs.DB, entidad, empresaID).
+		Select("id, slug").
+		Where("id = ? AND slug IS NOT NULL AND deleted_at IS NULL", historico.EntidadID).
+		Take(&resuelto).Error
+	return resuelto, err
+}
+
+// Función para asignar slug a los registros creados antes de existir la columna. Devuelve la cantidad asignada.
+func AsignarSlugsFaltantes(entidad string) (int, error) {
+	var registros []struct {
+		ID        uint
+		EmpresaID uint
+		Texto     string
+	}
+	columnaEmpresa := "0"
+	if entidad != EntidadSlugCategoria {
+		columnaEmpresa = "empresa_id"
+	}
+	if err := configs.DB.Table(entidad).
+		Select("id, " + columnaEmpresa + " AS empresa_id, " + columnasSlug[entidad] + " AS texto").
+		Where("slug IS NULL").
+		Order("id ASC").
+		Scan(&registros).Error; err != nil {
+		return 0, err
+	}
+
+	for _, registro := range registros {
+		slug, err := GenerarSlugUnico(configs.DB, entidad, registro.EmpresaID, registro.Texto, registro.ID)
+		if err != nil {
+			return 0, err
+		}
+		if err := configs.DB.Table(entidad).Where("id = ?", registro.ID).Update("slug", slug).Error; err != nil {
+			return 0, err
+		}
+	}
+	return len(registros), nil
+}
+
+// Slug normalizado del texto. Un slug solo numérico se confundiría con un ID en las rutas,
+// por lo que se antepone el nombre de la entidad, igual que cuando el texto no tiene letras ni números.
+func slugBase(entidad, texto string) string {
+	slug := utils.GenerarSlug(texto, largoMaximoSlug)
+	singular := strings.TrimSuffix(entidad, "s")
+	if slug == "" {
+		return singular
+	}
+	if _, err := strconv.ParseUint(slug, 10, 64); err == nil {
+		return singular + "-" + slug
+	}
+	return slug
+}
+
+// Un slug está en uso si lo tiene otro registro (aunque esté eliminado) o si otro registro lo tuvo antes
+func slugEnUso(db *gorm.DB, entidad string, empresaID uint, slug string, excluirID uint) (bool, error) {
+	var count int64
+	if err := consultaSlug(db, entidad, empresaID).
+		Where("slug = ? AND id <> ?", slug, excluirID).
+		Count(&count).Error; err != nil {
+		return false, err
+	}
+	if count > 0 {
+		return true, nil
+	}
+
+	if err := db.Model(&models.Slug_historico{}).
+		Where("entidad = ? AND empresa_id = ? AND slug = ? AND entidad_id <> ?", entidad, empresaID, slug, excluirID).
+		Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// Consulta sobre la tabla de la entidad limitada a la empresa (las categorías son globales)
+func consultaSlug(db *gorm.DB, entidad string, empresaID uint) *gorm.DB {
+	query := db.Table(entidad)
+	if entidad != EntidadSlugCategoria {
+		query = query.Where("empresa_id = ?", empresaID)
+	}
+	return query
+}
@@ -0,0 +1,266 @@
+package services
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// Emisor de los tokens firmados por la API
+const EmisorToken = "miApp"
+
+var ErrTokenInvalido = errors.New("token inválido")
+
+// ClaimsAcceso son las claims del access token que emite el login y valida AuthMiddleware
+type ClaimsAcceso struct {
+	UsuarioID uint     `json:"usuario_id"`
+	EmpresaID uint     `json:"empresa_id"` // Empresa a la que pertenece el usuario
+	Roles     []string `json:"roles"`
+	SesionID  uint     `json:"sid"` // Sesión (dispositivo) a la que pertenece el token
+	jwt.RegisteredClaims
+}
+
+// llaveToken es una llave pública con la que se verifican tokens, identificada por su kid
+type llaveToken struct {
+	kid     string
+	metodo  jwt.SigningMethod
+	publica crypto.PublicKey
+}
+
+var (
+	muLlaves       sync.RWMutex
+	llaveFirma     crypto.Signer         // Llave privada con la que se firman los tokens nuevos
+	kidFirma       string                // kid de la llave de firma
+	llavesVerifica map[string]llaveToken // Llaves aceptadas para verificar (la de firma y las anteriores durante la rotación)
+)
+
+// InicializarTokens carga la llave de firma y las llaves de verificación adicionales.
+//
+//   - JWT_PRIVATE_KEY o JWT_PRIVATE_KEY_FILE: llave privada PEM (RSA o Ed25519) usada para firmar.
+//   - JWT_VERIFICATION_KEYS o JWT_VERIFICATION_KEYS_FILE: uno o más bloques PEM con llaves públicas
+//     anteriores que se siguen aceptando mientras dura la rotación.
+//
+// Devuelve un error si no hay llave de firma configurada, para que la API no arranque sin ella.
+func InicializarTokens() error {
+	pemFirma, err := leerPEMConfigurado("JWT_PRIVATE_KEY")
+	if err != nil {
+		return err
+	}
+	if pemFirma == "" {
+		return errors.New("no se configuró la llave de firma de tokens (JWT_PRIVATE_KEY o JWT_PRIVATE_KEY_FILE)")
+	}
+
+	privada, err := parsearLlavePrivada([]byte(pemFirma))
+	if err != nil {
+		return fmt.Errorf("llave de firma inválida: %w", err)
+	}
+	firma, err := nuevaLlaveToken(privada.Public())
+	if err != nil {
+		return err
+	}
+
+	verifica := map[string]llaveToken{firma.kid: firma}
+
+	pemVerifica, err := leerPEMConfigurado("JWT_VERIFICATION_KEYS")
+	if err != nil {
+		return err
+	}
+	resto := []byte(pemVerifica)
+	for {
+		var bloque *pem.Block
+		bloque, resto = pem.Decode(resto)
+		if bloque == nil {
+			break
+		}
+		publica, err := parsearLlavePublica(bloque)
+		if err != nil {
+			return fmt.Errorf("llave de verificación inválida: %w", err)
+		}
+		llave, err := nuevaLlaveToken(publica)
+		if err != nil {
+			return err
+		}
+		verifica[llave.kid] = llave
+	}
+
+	muLlaves.Lock()
+	defer muLlaves.Unlock()
+	llaveFirma = privada
+	kidFirma = firma.kid
+	llavesVerifica = verifica
+	return nil
+}
+
+// leerPEMConfigurado lee un PEM desde la variable de entorno o desde el archivo indicado en <variable>_FILE
+func leerPEMConfigurado(variable string) (string, error) {
+	if valor := os.Getenv(variable); valor != "" {
+		// Permite definir el PEM en una sola línea con saltos escapados
+		return strings.ReplaceAll(valor, `\n`, "\n"), nil
+	}
+	if ruta := os.Getenv(variable + "_FILE"); ruta != "" {
+		contenido, err := os.ReadFile(ruta)
+		if err != nil {
+			return "", fmt.Errorf("no se pudo leer %s: %w", ruta, err)
+		}
+		return string(contenido), nil
+	}
+	return "", nil
+}
+
+func parsearLlavePrivada(contenido []byte) (crypto.Signer, error) {
+	if llave, err := jwt.ParseRSAPrivateKeyFromPEM(contenido); err == nil {
+		return llave, nil
+	}
+	llave, err := jwt.ParseEdPrivateKeyFromPEM(contenido)
+	if err != nil {
+		return nil, errors.New("se esperaba una llave privada RSA o Ed25519 en formato PEM")
+	}
+	return llave.(crypto.Signer), nil
+}
+
+func parsearLlavePublica(bloque *pem.Block) (crypto.PublicKey, error) {
+	contenido := pem.EncodeToMemory(bloque)
+	if strings.Contains(bloque.Type, "PRIVATE") {
+		privada, err := parsearLlavePrivada(contenido)
+		if err != nil {
+			return nil, err
+		}
+		return privada.Public(), nil
+	}
+	if llave, err := jwt.ParseRSAPublicKeyFromPEM(contenido); err == nil {
+		return llave, nil
+	}
+	llave, err := jwt.ParseEdPublicKeyFromPEM(contenido)
+	if err != nil {
+		return nil, errors.New("se esperaba una llave pública RSA o Ed25519 en formato PEM")
+	}
+	return llave, nil
+}
+
+// nuevaLlaveToken determina el algoritmo de la llave y calcula su kid a partir de la llave pública
+func nuevaLlaveToken(publica crypto.PublicKey) (llaveToken, error) {
+	var metodo jwt.SigningMethod
+	switch publica.(type) {
+	case *rsa.PublicKey:
+		metodo = jwt.SigningMethodRS256
+	case ed25519.PublicKey:
+		metodo = jwt.SigningMethodEdDSA
+	default:
+		return llaveToken{}, errors.New("tipo de llave no soportado, use RSA o Ed25519")
+	}
+
+	der, err := x509.MarshalPKIXPublicKey(publica)
+	if err != nil {
+		return llaveToken{}, err
+	}
+	suma := sha256.Sum256(der)
+
+	return llaveToken{
+		kid:     base64.RawURLEncoding.EncodeToString(suma[:12]),
+		metodo:  metodo,
+		publica: publica,
+	}, nil
+}
+
+// FirmarToken firma las claims con la llave de firma vigente, indicando su kid en la cabecera
+func FirmarToken(claims jwt.Claims) (string, error) {
+	muLlaves.RLock()
+	defer muLlaves.RUnlock()
+
+	if llaveFirma == nil {
+		return "", errors.New("servicio de tokens no inicializado")
+	}
+
+	token := jwt.NewWithClaims(llavesVerifica[kidFirma].metodo, claims)
+	token.Header["kid"] = kidFirma
+	return token.SignedString(llaveFirma)
+}
+
+// VerificarToken valida la firma del token con la llave indicada por su kid y carga las claims
+func VerificarToken(tokenString string, claims jwt.Claims) error {
+	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+
+		muLlaves.RLock()
+		llave, ok := llavesVerifica[kid]
+		muLlaves.RUnlock()
+
+		// El algoritmo debe ser el de la llave, nunca el que elija quien envía el token
+		if !ok || token.Method.Alg() != llave.metodo.Alg() {
+			return nil, ErrTokenInvalido
+		}
+		return llave.publica, nil
+	})
+	if err != nil {
+		return err
+	}
+	if !token.Valid {
+		return ErrTokenInvalido
+	}
+	return nil
+}
+
+// GenerarAccessToken emite un access token de corta duración asociado a una sesión
+func GenerarAccessToken(usuarioID, empresaID uint, roles []string, sesionID uint) (string, error) {
+	jti, err := GenerarTokenAleatorio()
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+	return FirmarToken(&ClaimsAcceso{
+		UsuarioID: usuarioID,
+		EmpresaID: empresaID,
+		Roles:     roles,
+		SesionID:  sesionID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        jti,
+			Subject:   strconv.FormatUint(uint64(usuarioID), 10),
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(DuracionAccessToken)),
+			Issuer:    EmisorToken,
+		},
+	})
+}
+
+// JWKS devuelve las llaves públicas de verificación en formato JSON Web Key Set (RFC 7517)
+func JWKS() map[string]interface{} {
+	muLlaves.RLock()
+	defer muLlaves.RUnlock()
+
+	keys := []map[string]string{}
+	for _, llave := range llavesVerifica {
+		jwk := map[string]string{
+			"kid": llave.kid,
+			"alg": llave.metodo.Alg(),
+			"use": "sig",
+		}
+		switch publica := llave.publica.(type) {
+		case *rsa.PublicKey:
+			jwk["kty"] = "RSA"
+			jwk["n"] = base64.RawURLEncoding.EncodeToString(publica.N.Bytes())
+			jwk["e"] = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(publica.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk["kty"] = "OKP"
+			jwk["crv"] = "Ed25519"
+			jwk["x"] = base64.RawURLEncoding.EncodeToString(publica)
+		}
+		keys = append(keys, jwk)
+	}
+
+	return map[string]interface{}{"keys": keys}
+}
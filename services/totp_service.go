@@ -0,0 +1,144 @@
+package services
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+	"v1_prefabricadas/configs"
+	"v1_prefabricadas/models"
+
+	"gorm.io/gorm"
+)
+
+// Parámetros TOTP compatibles con Google Authenticator, Authy, etc. (RFC 6238)
+const (
+	totpPeriodo                 = 30
+	totpDigitos                 = 6
+	totpVentana                 = 1 // Pasos de tolerancia antes y después del actual
+	cantidadCodigosRecuperacion = 10
+)
+
+// GenerarSecretoTOTP genera un secreto aleatorio de 160 bits codificado en base32
+func GenerarSecretoTOTP() (string, error) {
+	b := make([]byte, 20)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(b), nil
+}
+
+// URIProvisionamientoTOTP construye la URI otpauth:// que las aplicaciones leen desde un código QR
+func URIProvisionamientoTOTP(secreto, email string) string {
+	emisor := os.Getenv("TOTP_ISSUER")
+	if emisor == "" {
+		emisor = "Casas Emilia"
+	}
+
+	v := url.Values{}
+	v.Set("secret", secreto)
+	v.Set("issuer", emisor)
+	v.Set("algorithm", "SHA1")
+	v.Set("digits", fmt.Sprint(totpDigitos))
+	v.Set("period", fmt.Sprint(totpPeriodo))
+
+	etiqueta := url.PathEscape(emisor + ":" + email)
+	return "otpauth://totp/" + etiqueta + "?" + v.Encode()
+}
+
+// codigoTOTP calcula el código para un paso de tiempo
+func codigoTOTP(secreto []byte, paso int64) string {
+	msg := make([]byte, 8)
+	binary.BigEndian.PutUint64(msg, uint64(paso))
+
+	mac := hmac.New(sha1.New, secreto)
+	mac.Write(msg)
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	valor := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	return fmt.Sprintf("%0*d", totpDigitos, valor%1000000)
+}
+
+// VerificarCodigoTOTP valida un código de 6 dígitos y devuelve el paso de tiempo utilizado.
+// Se rechazan los códigos de pasos iguales o anteriores a ultimoPaso para impedir su reutilización.
+func VerificarCodigoTOTP(secreto, codigo string, ultimoPaso int64) (int64, bool) {
+	clave, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(strings.ToUpper(secreto))
+	if err != nil || len(codigo) != totpDigitos {
+		return 0, false
+	}
+
+	actual := time.Now().Unix() / totpPeriodo
+	for paso := actual - totpVentana; paso <= actual+totpVentana; paso++ {
+		if paso <= ultimoPaso {
+			continue
+		}
+		if subtle.ConstantTimeCompare([]byte(codigoTOTP(clave, paso)), []byte(codigo)) == 1 {
+			return paso, true
+		}
+	}
+	return 0, false
+}
+
+// GenerarCodigosRecuperacion reemplaza los códigos de recuperación de la credencial y los devuelve en texto plano
+func GenerarCodigosRecuperacion(tx *gorm.DB, credencialID uint) ([]string, error) {
+	if err := tx.Where("credencial_id = ?", credencialID).Delete(&models.Codigo_recuperacion{}).Error; err != nil {
+		return nil, err
+	}
+
+	codigos := make([]string, 0, cantidadCodigosRecuperacion)
+	for i := 0; i < cantidadCodigosRecuperacion; i++ {
+		b := make([]byte, 5)
+		if _, err := rand.Read(b); err != nil {
+			return nil, err
+		}
+		h := hex.EncodeToString(b)
+		codigo := h[:5] + "-" + h[5:]
+
+		if err := tx.Create(&models.Codigo_recuperacion{
+			CodigoHash:   HashToken(codigo),
+			CredencialID: credencialID,
+		}).Error; err != nil {
+			return nil, err
+		}
+		codigos = append(codigos, codigo)
+	}
+
+	return codigos, nil
+}
+
+// UsarCodigoRecuperacion marca como usado un código de recuperación válido. Devuelve false si no existe o ya fue usado.
+func UsarCodigoRecuperacion(credencialID uint, codigo string) (bool, error) {
+	result := configs.DB.Model(&models.Codigo_recuperacion{}).
+		Where("credencial_id = ? AND codigo_hash = ? AND usado_at IS NULL", credencialID, HashToken(strings.ToLower(strings.TrimSpace(codigo)))).
+		Update("usado_at", time.Now())
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// VerificarSegundoFactor acepta un código TOTP o un código de recuperación y registra su uso
+func VerificarSegundoFactor(credencial *models.Credencial, codigo string) (bool, error) {
+	if paso, ok := VerificarCodigoTOTP(credencial.TOTPSecreto, codigo, credencial.TOTPUltimoPaso); ok {
+		// Actualización condicional para que dos requests simultáneos no acepten el mismo código
+		result := configs.DB.Model(&models.Credencial{}).
+			Where("id = ? AND totp_ultimo_paso < ?", credencial.ID, paso).
+			Update("totp_ultimo_paso", paso)
+		if result.Error != nil {
+			return false, result.Error
+		}
+		credencial.TOTPUltimoPaso = paso
+		return result.RowsAffected > 0, nil
+	}
+
+	return UsarCodigoRecuperacion(credencial.ID, codigo)
+}
@@ -9,6 +9,27 @@ import (
 
 // EnviarEmailRecuperacion envía un email de recuperación de contraseña
 func EnviarEmailRecuperacion(email, link string) error {
+	subject := "Recuperación de contraseña"
+	body := fmt.Sprintf("Hola,\n\nHaz clic en el siguiente enlace para recuperar tu contraseña:\n\n%s\n\nSi no solicitaste esto, ignora este mensaje.", link)
+	return enviarEmail(email, subject, body)
+}
+
+// EnviarEmailInvitacion envía la invitación para que un nuevo usuario confirme su email y defina su contraseña
+func EnviarEmailInvitacion(email, link string) error {
+	subject := "Invitación para acceder al sistema"
+	body := fmt.Sprintf("Hola,\n\nSe creó una cuenta para ti. Haz clic en el siguiente enlace para confirmar tu email y definir tu contraseña:\n\n%s\n\nEl enlace caduca en unos días. Si no esperabas esta invitación, ignora este mensaje.", link)
+	return enviarEmail(email, subject, body)
+}
+
+// EnviarEmailVerificacion envía el enlace para confirmar una nueva dirección de email
+func EnviarEmailVerificacion(email, link string) error {
+	subject := "Confirma tu nuevo email"
+	body := fmt.Sprintf("Hola,\n\nSolicitaste usar esta dirección para acceder al sistema. Haz clic en el siguiente enlace para confirmarla:\n\n%s\n\nSi no solicitaste este cambio, ignora este mensaje.", link)
+	return enviarEmail(email, subject, body)
+}
+
+// enviarEmail envía un email de texto plano usando el servidor SMTP configurado
+func enviarEmail(email, subject, body string) error {
 	// Configuración del servidor SMTP
 	smtpHost := "smtp.gmail.com" // Cambia esto por el host de tu proveedor SMTP (por ejemplo, smtp.gmail.com)
 	smtpPort := "587"            // Puerto estándar para conexiones TLS
@@ -23,8 +44,6 @@ func EnviarEmailRecuperacion(email, link string) error {
 	}
 
 	// Construir el mensaje del email
-	subject := "Recuperación de contraseña"
-	body := fmt.Sprintf("Hola,\n\nHaz clic en el siguiente enlace para recuperar tu contraseña:\n\n%s\n\nSi no solicitaste esto, ignora este mensaje.", link)
 	message := fmt.Sprintf("From: %s\nTo: %s\nSubject: %s\n\n%s", from, email, subject, body)
 
 	// Dirección del servidor SMTP
@@ -0,0 +1,454 @@
+package utils
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrHojaCalculoInvalida     = errors.New("el archivo no es una hoja de cálculo válida")
+	ErrHojaCalculoExcedeLimite = errors.New("la hoja de cálculo supera el tamaño permitido")
+)
+
+// Límites de lectura de una hoja de cálculo. Se revisan mientras se lee, para no reservar
+// memoria según lo que declare el archivo.
+type LimitesHojaCalculo struct {
+	Filas    int   // Cantidad máxima de filas, contando el encabezado
+	Columnas int   // Cantidad máxima de columnas
+	Bytes    int64 // Tamaño máximo descomprimido de cada parte de un XLSX
+}
+
+// Error para una celda con datos fuera de los límites, o nil si está dentro. Las celdas vacías
+// fuera de los límites se descartan sin error (Excel suele guardar celdas con formato pero sin valor).
+func (l LimitesHojaCalculo) revisarCelda(numero, columna int, texto string) error {
+	if strings.TrimSpace(texto) == "" {
+		return nil
+	}
+	if numero > l.Filas {
+		return fmt.Errorf("%w: tiene más de %d filas", ErrHojaCalculoExcedeLimite, l.Filas)
+	}
+	if columna >= l.Columnas {
+		return fmt.Errorf("%w: la fila %d tiene más de %d columnas", ErrHojaCalculoExcedeLimite, numero, l.Columnas)
+	}
+	return nil
+}
+
+// Función para leer todas las filas de un CSV. El separador puede ser coma o punto y coma
+// (Excel usa punto y coma en configuraciones regionales en español) y se detecta en la primera línea.
+// La fila i del resultado corresponde a la línea i+1 del archivo.
+func LeerCSV(r io.Reader, limites LimitesHojaCalculo) ([][]string, error) {
+	lector := bufio.NewReader(r)
+	primeraLinea, err := lector.Peek(4096)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, bufio.ErrBufferFull) {
+		return nil, err
+	}
+	if i := bytes.IndexByte(primeraLinea, '\n'); i >= 0 {
+		primeraLinea = primeraLinea[:i]
+	}
+
+	// Quitar la marca de orden de bytes que agrega Excel al guardar como CSV UTF-8
+	if bom, _ := lector.Peek(3); bytes.Equal(bom, []byte("\xef\xbb\xbf")) {
+		lector.Discard(3)
+	}
+
+	csvReader := csv.NewReader(lector)
+	csvReader.FieldsPerRecord = -1
+	csvReader.LazyQuotes = true
+	if bytes.Count(primeraLinea, []byte(";")) > bytes.Count(primeraLinea, []byte(",")) {
+		csvReader.Comma = ';'
+	}
+
+	var filas [][]string
+	for {
+		fila, err := csvReader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrHojaCalculoInvalida, err)
+		}
+		numero := len(filas) + 1
+		for columna, texto := range fila {
+			if numero > limites.Filas || columna >= limites.Columnas {
+				if err := limites.revisarCelda(numero, columna, texto); err != nil {
+					return nil, err
+				}
+			}
+		}
+		if numero > limites.Filas {
+			continue
+		}
+		if len(fila) > limites.Columnas {
+			fila = fila[:limites.Columnas]
+		}
+		filas = append(filas, fila)
+	}
+	return filas, nil
+}
+
+// Estructuras mínimas del formato XLSX (Office Open XML) necesarias para leer la primera hoja
+type xlsxLibro struct {
+	Hojas []struct {
+		ID string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+	} `xml:"sheets>sheet"`
+}
+
+type xlsxRelaciones struct {
+	Relaciones []struct {
+		ID     string `xml:"Id,attr"`
+		Target string `xml:"Target,attr"`
+	} `xml:"Relationship"`
+}
+
+type xlsxTextoEnriquecido struct {
+	T      string `xml:"t"`
+	Partes []struct {
+		T string `xml:"t"`
+	} `xml:"r"`
+}
+
+func (t xlsxTextoEnriquecido) texto() string {
+	if len(t.Partes) == 0 {
+		return t.T
+	}
+	var b strings.Builder
+	for _, parte := range t.Partes {
+		b.WriteString(parte.T)
+	}
+	return b.String()
+}
+
+type xlsxTextosCompartidos struct {
+	Textos []xlsxTextoEnriquecido `xml:"si"`
+}
+
+type xlsxFila struct {
+	R      int `xml:"r,attr"`
+	Celdas []struct {
+		R      string               `xml:"r,attr"`
+		T      string               `xml:"t,attr"`
+		V      string               `xml:"v"`
+		Inline xlsxTextoEnriquecido `xml:"is"`
+	} `xml:"c"`
+}
+
+// Función para leer todas las filas de la primera hoja de un archivo XLSX.
+// La fila i del resultado corresponde a la fila i+1 de la hoja (las filas vacías se devuelven vacías).
+func LeerXLSX(r io.ReaderAt, size int64, limites LimitesHojaCalculo) ([][]string, error) {
+	archivo, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrHojaCalculoInvalida, err)
+	}
+	partes := map[string]*zip.File{}
+	for _, f := range archivo.File {
+		partes[f.Name] = f
+	}
+
+	// Ubicar la primera hoja del libro
+	var libro xlsxLibro
+	var relaciones xlsxRelaciones
+	if err := leerParteXLSX(partes, limites.Bytes, "xl/workbook.xml", &libro); err != nil {
+		return nil, err
+	}
+	if err := leerParteXLSX(partes, limites.Bytes, "xl/_rels/workbook.xml.rels", &relaciones); err != nil {
+		return nil, err
+	}
+	if len(libro.Hojas) == 0 {
+		return nil, fmt.Errorf("%w: el libro no tiene hojas", ErrHojaCalculoInvalida)
+	}
+	rutaHoja := ""
+	for _, relacion := range relaciones.Relaciones {
+		if relacion.ID == libro.Hojas[0].ID {
+			rutaHoja = relacion.Target
+		}
+	}
+	if strings.HasPrefix(rutaHoja, "/") {
+		rutaHoja = strings.TrimPrefix(rutaHoja, "/")
+	} else {
+		rutaHoja = path.Join("xl", rutaHoja)
+	}
+
+	// Los textos suelen guardarse una sola vez en sharedStrings.xml (puede no existir)
+	var compartidos xlsxTextosCompartidos
+	if _, ok := partes["xl/sharedStrings.xml"]; ok {
+		if err := leerParteXLSX(partes, limites.Bytes, "xl/sharedStrings.xml", &compartidos); err != nil {
+			return nil, err
+		}
+	}
+
+	// La hoja se lee fila por fila, para cortar apenas se supere un límite
+	var filas [][]string
+	err = recorrerParteXLSX(partes, limites.Bytes, rutaHoja, func(decoder *xml.Decoder, inicio xml.StartElement) error {
+		if inicio.Name.Local != "row" {
+			return nil
+		}
+		var fila xlsxFila
+		if err := decoder.DecodeElement(&fila, &inicio); err != nil {
+			return err
+		}
+
+		numero := fila.R
+		if numero < 0 {
+			return fmt.Errorf("%w: número de fila inválido %d", ErrHojaCalculoInvalida, numero)
+		}
+		if numero == 0 {
+			numero = len(filas) + 1
+		}
+
+		var valores []string
+		for i, celda := range fila.Celdas {
+			columna := i
+			if celda.R != "" {
+				columna = columnaXLSX(celda.R)
+			}
+			if columna < 0 {
+				return fmt.Errorf("%w: referencia de celda inválida %s", ErrHojaCalculoInvalida, celda.R)
+			}
+
+			var texto string
+			switch celda.T {
+			case "s":
+				indice, err := strconv.Atoi(celda.V)
+				if err != nil || indice < 0 || indice >= len(compartidos.Textos) {
+					return fmt.Errorf("%w: texto compartido inexistente en %s", ErrHojaCalculoInvalida, celda.R)
+				}
+				texto = compartidos.Textos[indice].texto()
+			case "inlineStr":
+				texto = celda.Inline.texto()
+			case "b":
+				texto = map[string]string{"1": "true", "0": "false"}[celda.V]
+			default:
+				texto = celda.V
+			}
+
+			if numero > limites.Filas || columna >= limites.Columnas {
+				if err := limites.revisarCelda(numero, columna, texto); err != nil {
+					return err
+				}
+				continue
+			}
+			for len(valores) <= columna {
+				valores = append(valores, "")
+			}
+			valores[columna] = texto
+		}
+		if numero > limites.Filas {
+			return nil
+		}
+
+		for len(filas) < numero {
+			filas = append(filas, nil)
+		}
+		filas[numero-1] = valores
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return filas, nil
+}
+
+// Abrir una parte del XLSX para leer como máximo tamanoMaximo bytes descomprimidos, aunque el archivo declare otro tamaño
+func abrirParteXLSX(partes map[string]*zip.File, tamanoMaximo int64, nombre string) (io.ReadCloser, *io.LimitedReader, error) {
+	parte, ok := partes[nombre]
+	if !ok {
+		return nil, nil, fmt.Errorf("%w: falta %s", ErrHojaCalculoInvalida, nombre)
+	}
+	if parte.UncompressedSize64 > uint64(tamanoMaximo) {
+		return nil, nil, fmt.Errorf("%w: %s supera los %d bytes", ErrHojaCalculoExcedeLimite, nombre, tamanoMaximo)
+	}
+	contenido, err := parte.Open()
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrHojaCalculoInvalida, err)
+	}
+	return contenido, &io.LimitedReader{R: contenido, N: tamanoMaximo + 1}, nil
+}
+
+// Error al decodificar una parte: si se alcanzó el límite se informa como tal y no como un XML inválido
+func errorParteXLSX(lector *io.LimitedReader, tamanoMaximo int64, nombre string, err error) error {
+	if lector.N <= 0 {
+		return fmt.Errorf("%w: %s supera los %d bytes", ErrHojaCalculoExcedeLimite, nombre, tamanoMaximo)
+	}
+	if errors.Is(err, ErrHojaCalculoInvalida) || errors.Is(err, ErrHojaCalculoExcedeLimite) {
+		return err
+	}
+	return fmt.Errorf("%w: %s: %v", ErrHojaCalculoInvalida, nombre, err)
+}
+
+func leerParteXLSX(partes map[string]*zip.File, tamanoMaximo int64, nombre string, destino interface{}) error {
+	contenido, lector, err := abrirParteXLSX(partes, tamanoMaximo, nombre)
+	if err != nil {
+		return err
+	}
+	defer contenido.Close()
+	if err := xml.NewDecoder(lector).Decode(destino); err != nil {
+		return errorParteXLSX(lector, tamanoMaximo, nombre, err)
+	}
+	return nil
+}
+
+// Recorrer los elementos de una parte del XLSX sin decodificarla completa en memoria
+func recorrerParteXLSX(partes map[string]*zip.File, tamanoMaximo int64, nombre string, elemento func(*xml.Decoder, xml.StartElement) error) error {
+	contenido, lector, err := abrirParteXLSX(partes, tamanoMaximo, nombre)
+	if err != nil {
+		return err
+	}
+	defer contenido.Close()
+	decoder := xml.NewDecoder(lector)
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return errorParteXLSX(lector, tamanoMaximo, nombre, err)
+		}
+		if inicio, ok := token.(xml.StartElement); ok {
+			if err := elemento(decoder, inicio); err != nil {
+				return errorParteXLSX(lector, tamanoMaximo, nombre, err)
+			}
+		}
+	}
+}
+
+// Cantidad de columnas de una hoja de Excel (hasta la columna XFD)
+const columnasMaximasXLSX = 16384
+
+// Índice (desde 0) de la columna de una referencia de celda ("A1" -> 0, "AB12" -> 27), o -1 si no es válida
+func columnaXLSX(referencia string) int {
+	columna := 0
+	for _, r := range referencia {
+		if r < 'A' || r > 'Z' {
+			break
+		}
+		columna = columna*26 + int(r-'A'+1)
+		if columna > columnasMaximasXLSX {
+			return -1
+		}
+	}
+	return columna - 1
+}
+
+// Partes fijas de un libro XLSX con una sola hoja
+var partesFijasXLSX = []struct{ nombre, contenido string }{
+	{"[Content_Types].xml", xml.Header + `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+		`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+		`<Default Extension="xml" ContentType="application/xml"/>` +
+		`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+		`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+		`<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>` +
+		`</Types>`},
+	{"_rels/.rels", xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+		`</Relationships>`},
+	{"xl/_rels/workbook.xml.rels", xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>` +
+		`<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>` +
+		`</Relationships>`},
+	{"xl/styles.xml", xml.Header + `<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+		`<fonts count="1"><font><sz val="11"/><name val="Calibri"/></font></fonts>` +
+		`<fills count="1"><fill><patternFill patternType="none"/></fill></fills>` +
+		`<borders count="1"><border/></borders>` +
+		`<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>` +
+		`<cellXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/></cellXfs>` +
+		`</styleSheet>`},
+}
+
+// Escritor de un XLSX de una sola hoja que escribe las filas a medida que se agregan,
+// sin mantener el libro en memoria. Debe cerrarse con Cerrar para completar el archivo.
+type EscritorXLSX struct {
+	archivo *zip.Writer
+	hoja    *bufio.Writer
+	filas   int
+}
+
+func NuevoEscritorXLSX(w io.Writer, nombreHoja string) (*EscritorXLSX, error) {
+	archivo := zip.NewWriter(w)
+	partes := append(partesFijasXLSX, struct{ nombre, contenido string }{
+		"xl/workbook.xml", xml.Header + `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" ` +
+			`xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+			`<sheets><sheet name="` + textoXML(nombreHoja) + `" sheetId="1" r:id="rId1"/></sheets></workbook>`,
+	})
+	for _, parte := range partes {
+		destino, err := archivo.Create(parte.nombre)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := io.WriteString(destino, parte.contenido); err != nil {
+			return nil, err
+		}
+	}
+
+	// La hoja es la última parte del archivo, así las filas se escriben directamente en la salida
+	destino, err := archivo.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return nil, err
+	}
+	hoja := bufio.NewWriter(destino)
+	hoja.WriteString(xml.Header + `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+	return &EscritorXLSX{archivo: archivo, hoja: hoja}, nil
+}
+
+// Agregar una fila. Los números se guardan como celdas numéricas y todo lo demás como texto.
+func (e *EscritorXLSX) EscribirFila(valores ...interface{}) error {
+	e.filas++
+	fmt.Fprintf(e.hoja, `<row r="%d">`, e.filas)
+	for i, valor := range valores {
+		referencia := referenciaXLSX(i, e.filas)
+		switch v := valor.(type) {
+		case int, int64, uint, uint64:
+			fmt.Fprintf(e.hoja, `<c r="%s"><v>%d</v></c>`, referencia, v)
+		case float64:
+			fmt.Fprintf(e.hoja, `<c r="%s"><v>%s</v></c>`, referencia, strconv.FormatFloat(v, 'f', -1, 64))
+		case nil:
+		default:
+			fmt.Fprintf(e.hoja, `<c r="%s" t="inlineStr"><is><t xml:space="preserve">%s</t></is></c>`, referencia, textoXML(fmt.Sprint(v)))
+		}
+	}
+	_, err := e.hoja.WriteString(`</row>`)
+	return err
+}
+
+// Enviar a la salida las filas pendientes (para ir entregando el archivo mientras se genera)
+func (e *EscritorXLSX) Flush() error {
+	if err := e.hoja.Flush(); err != nil {
+		return err
+	}
+	return e.archivo.Flush()
+}
+
+// Cerrar la hoja y escribir el índice del archivo ZIP
+func (e *EscritorXLSX) Cerrar() error {
+	if _, err := e.hoja.WriteString(`</sheetData></worksheet>`); err != nil {
+		return err
+	}
+	if err := e.hoja.Flush(); err != nil {
+		return err
+	}
+	return e.archivo.Close()
+}
+
+// Referencia de una celda a partir de su columna (desde 0) y su fila (desde 1): (27, 12) -> "AB12"
+func referenciaXLSX(columna, fila int) string {
+	letras := ""
+	for columna++; columna > 0; columna = (columna - 1) / 26 {
+		letras = string(rune('A'+(columna-1)%26)) + letras
+	}
+	return letras + strconv.Itoa(fila)
+}
+
+// Escapar un texto para XML (los caracteres no permitidos se reemplazan)
+func textoXML(texto string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(texto))
+	return b.String()
+}
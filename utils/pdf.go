@@ -0,0 +1,308 @@
+package utils
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	_ "image/gif"
+	"image/jpeg"
+	_ "image/png"
+	"strings"
+	"unicode"
+)
+
+// Tamaño de página A4 en puntos
+const (
+	AnchoPaginaPDF = 595.28
+	AltoPaginaPDF  = 841.89
+)
+
+// Documento PDF simple con las fuentes estándar Helvetica y Helvetica-Bold (codificación WinAnsi,
+// que cubre los caracteres del español) e imágenes JPEG. Las coordenadas se indican en puntos
+// desde la esquina superior izquierda de la página.
+type DocumentoPDF struct {
+	Titulo   string
+	paginas  []*bytes.Buffer
+	actual   *bytes.Buffer
+	imagenes []ImagenPDF
+}
+
+// Cantidad máxima de píxeles de una imagen para incluirla en un PDF. Convertirla a JPEG requiere
+// decodificarla completa en memoria (4 bytes por píxel), así que se revisa antes de decodificar.
+const pixelesMaximosImagenPDF = 25_000_000
+
+// Imagen JPEG lista para incluir en un PDF
+type ImagenPDF struct {
+	datos       []byte
+	Ancho       int
+	Alto        int
+	componentes int
+}
+
+func NuevoDocumentoPDF(titulo string) *DocumentoPDF {
+	d := &DocumentoPDF{Titulo: titulo}
+	d.NuevaPagina()
+	return d
+}
+
+func (d *DocumentoPDF) NuevaPagina() {
+	d.actual = new(bytes.Buffer)
+	d.paginas = append(d.paginas, d.actual)
+}
+
+func (d *DocumentoPDF) CantidadPaginas() int {
+	return len(d.paginas)
+}
+
+// Seguir dibujando en una página ya creada (desde 0), por ejemplo para agregar el pie con el total de páginas
+func (d *DocumentoPDF) IrAPagina(i int) {
+	d.actual = d.paginas[i]
+}
+
+// Escribir un texto en una línea con su base en y
+func (d *DocumentoPDF) Texto(x, y, tamano float64, negrita bool, c color.Color, texto string) {
+	fuente := "F1"
+	if negrita {
+		fuente = "F2"
+	}
+	fmt.Fprintf(d.actual, "BT %s rg /%s %s Tf %s Td (%s) Tj ET\n",
+		numerosPDF(componentesColor(c)...), fuente, numeroPDF(tamano), numerosPDF(x, AltoPaginaPDF-y), textoPDF(texto))
+}
+
+// Dibujar un rectángulo relleno con su esquina superior izquierda en (x, y)
+func (d *DocumentoPDF) Rectangulo(x, y, ancho, alto float64, c color.Color) {
+	fmt.Fprintf(d.actual, "%s rg %s re f\n", numerosPDF(componentesColor(c)...), numerosPDF(x, AltoPaginaPDF-y-alto, ancho, alto))
+}
+
+// Dibujar una línea recta
+func (d *DocumentoPDF) Linea(x1, y1, x2, y2, grosor float64, c color.Color) {
+	fmt.Fprintf(d.actual, "%s RG %s w %s m %s l S\n", numerosPDF(componentesColor(c)...), numeroPDF(grosor),
+		numerosPDF(x1, AltoPaginaPDF-y1), numerosPDF(x2, AltoPaginaPDF-y2))
+}
+
+// Dibujar una imagen con su esquina superior izquierda en (x, y), escalada al ancho y alto indicados
+func (d *DocumentoPDF) Imagen(imagen ImagenPDF, x, y, ancho, alto float64) {
+	d.imagenes = append(d.imagenes, imagen)
+	fmt.Fprintf(d.actual, "q %s 0 0 %s %s cm /Im%d Do Q\n", numeroPDF(ancho), numeroPDF(alto),
+		numerosPDF(x, AltoPaginaPDF-y-alto), len(d.imagenes))
+}
+
+// Función para preparar una imagen (JPEG, PNG o GIF) para incluirla en un PDF. Los JPEG en RGB o escala
+// de grises se incluyen tal cual; el resto se convierte a JPEG sobre fondo blanco.
+func PrepararImagenPDF(datos []byte) (ImagenPDF, error) {
+	configuracion, formato, err := image.DecodeConfig(bytes.NewReader(datos))
+	if err != nil {
+		return ImagenPDF{}, err
+	}
+	if configuracion.Width <= 0 || configuracion.Height <= 0 {
+		return ImagenPDF{}, fmt.Errorf("la imagen no tiene dimensiones válidas (%dx%d)", configuracion.Width, configuracion.Height)
+	}
+	if int64(configuracion.Width)*int64(configuracion.Height) > pixelesMaximosImagenPDF {
+		return ImagenPDF{}, fmt.Errorf("la imagen de %dx%d supera los %d píxeles permitidos", configuracion.Width, configuracion.Height, pixelesMaximosImagenPDF)
+	}
+	imagen := ImagenPDF{datos: datos, Ancho: configuracion.Width, Alto: configuracion.Height, componentes: 3}
+	if formato == "jpeg" {
+		switch configuracion.ColorModel {
+		case color.YCbCrModel:
+			return imagen, nil
+		case color.GrayModel:
+			imagen.componentes = 1
+			return imagen, nil
+		}
+	}
+
+	original, _, err := image.Decode(bytes.NewReader(datos))
+	if err != nil {
+		return ImagenPDF{}, err
+	}
+	lienzo := image.NewRGBA(original.Bounds())
+	draw.Draw(lienzo, lienzo.Bounds(), image.White, image.Point{}, draw.Src)
+	draw.Draw(lienzo, lienzo.Bounds(), original, original.Bounds().Min, draw.Over)
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, lienzo, &jpeg.Options{Quality: 85}); err != nil {
+		return ImagenPDF{}, err
+	}
+	imagen.datos = buf.Bytes()
+	return imagen, nil
+}
+
+// Generar el archivo PDF
+func (d *DocumentoPDF) Bytes() ([]byte, error) {
+	var salida bytes.Buffer
+	var posiciones []int
+	objeto := func(contenido string, flujo []byte) {
+		posiciones = append(posiciones, salida.Len())
+		fmt.Fprintf(&salida, "%d 0 obj\n%s", len(posiciones), contenido)
+		if flujo != nil {
+			salida.WriteString("\nstream\n")
+			salida.Write(flujo)
+			salida.WriteString("\nendstream")
+		}
+		salida.WriteString("\nendobj\n")
+	}
+
+	// Objetos: 1 catálogo, 2 árbol de páginas, 3 información, 4 y 5 fuentes, luego imágenes y por cada página su contenido y la página
+	primeraImagen := 6
+	primeraPagina := primeraImagen + len(d.imagenes)
+	var paginas, imagenes []string
+	for i := range d.paginas {
+		paginas = append(paginas, fmt.Sprintf("%d 0 R", primeraPagina+2*i+1))
+	}
+	for i := range d.imagenes {
+		imagenes = append(imagenes, fmt.Sprintf("/Im%d %d 0 R", i+1, primeraImagen+i))
+	}
+
+	salida.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+	objeto("<< /Type /Catalog /Pages 2 0 R >>", nil)
+	objeto(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(paginas, " "), len(d.paginas)), nil)
+	objeto(fmt.Sprintf("<< /Title (%s) >>", textoPDF(d.Titulo)), nil)
+	objeto("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>", nil)
+	objeto("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>", nil)
+
+	for _, imagen := range d.imagenes {
+		espacioColor := "/DeviceRGB"
+		if imagen.componentes == 1 {
+			espacioColor = "/DeviceGray"
+		}
+		objeto(fmt.Sprintf("<< /Type /XObject /Subtype /Image /Width %d /Height %d /ColorSpace %s /BitsPerComponent 8 /Filter /DCTDecode /Length %d >>",
+			imagen.Ancho, imagen.Alto, espacioColor, len(imagen.datos)), imagen.datos)
+	}
+
+	recursos := fmt.Sprintf("<< /Font << /F1 4 0 R /F2 5 0 R >> /XObject << %s >> >>", strings.Join(imagenes, " "))
+	for i, pagina := range d.paginas {
+		var comprimido bytes.Buffer
+		zw := zlib.NewWriter(&comprimido)
+		if _, err := zw.Write(pagina.Bytes()); err != nil {
+			return nil, err
+		}
+		if err := zw.Close(); err != nil {
+			return nil, err
+		}
+		objeto(fmt.Sprintf("<< /Filter /FlateDecode /Length %d >>", comprimido.Len()), comprimido.Bytes())
+		objeto(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %s] /Resources %s /Contents %d 0 R >>",
+			numerosPDF(AnchoPaginaPDF, AltoPaginaPDF), recursos, primeraPagina+2*i), nil)
+	}
+
+	inicioXref := salida.Len()
+	fmt.Fprintf(&salida, "xref\n0 %d\n0000000000 65535 f \n", len(posiciones)+1)
+	for _, posicion := range posiciones {
+		fmt.Fprintf(&salida, "%010d 00000 n \n", posicion)
+	}
+	fmt.Fprintf(&salida, "trailer\n<< /Size %d /Root 1 0 R /Info 3 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(posiciones)+1, inicioXref)
+	return salida.Bytes(), nil
+}
+
+// Ancho en puntos de un texto escrito con Helvetica (o Helvetica-Bold) en el tamaño indicado
+func AnchoTextoPDF(texto string, tamano float64, negrita bool) float64 {
+	anchos := &anchosHelvetica
+	if negrita {
+		anchos = &anchosHelveticaNegrita
+	}
+	total := 0
+	for _, r := range texto {
+		// Las letras con tilde tienen el ancho de la letra sin tilde
+		if base, ok := equivalenciasTildes[unicode.ToLower(r)]; ok {
+			if unicode.IsUpper(r) {
+				base = unicode.ToUpper(base)
+			}
+			r = base
+		}
+		if r >= 32 && r <= 126 {
+			total += anchos[r-32]
+		} else {
+			total += 556
+		}
+	}
+	return float64(total) * tamano / 1000
+}
+
+// Dividir un texto en líneas que no superen el ancho indicado, respetando los saltos de línea
+func DividirLineasPDF(texto string, ancho, tamano float64, negrita bool) []string {
+	var lineas []string
+	for _, parrafo := range strings.Split(strings.ReplaceAll(texto, "\r\n", "\n"), "\n") {
+		linea := ""
+		for _, palabra := range strings.Fields(parrafo) {
+			candidata := palabra
+			if linea != "" {
+				candidata = linea + " " + palabra
+			}
+			if linea != "" && AnchoTextoPDF(candidata, tamano, negrita) > ancho {
+				lineas = append(lineas, linea)
+				candidata = palabra
+			}
+			linea = candidata
+		}
+		lineas = append(lineas, linea)
+	}
+	return lineas
+}
+
+// Texto literal de PDF en codificación WinAnsi, con los paréntesis y barras escapados
+func textoPDF(texto string) string {
+	especiales := map[rune]byte{
+		'€': 0x80, '…': 0x85, '‘': 0x91, '’': 0x92, '“': 0x93, '”': 0x94, '•': 0x95, '–': 0x96, '—': 0x97,
+	}
+	var b strings.Builder
+	for _, r := range texto {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r == '\t':
+			b.WriteByte(' ')
+		case r >= 32 && r <= 126:
+			b.WriteRune(r)
+		case r >= 0xA0 && r <= 0xFF:
+			fmt.Fprintf(&b, "\\%03o", r)
+		case especiales[r] != 0:
+			fmt.Fprintf(&b, "\\%03o", especiales[r])
+		default:
+			b.WriteByte('?')
+		}
+	}
+	return b.String()
+}
+
+func componentesColor(c color.Color) []float64 {
+	r, g, b, _ := c.RGBA()
+	return []float64{float64(r) / 0xffff, float64(g) / 0xffff, float64(b) / 0xffff}
+}
+
+func numeroPDF(n float64) string {
+	texto := strings.TrimRight(strings.TrimRight(fmt.Sprintf("%.3f", n), "0"), ".")
+	if texto == "-0" {
+		return "0"
+	}
+	return texto
+}
+
+func numerosPDF(numeros ...float64) string {
+	textos := make([]string, len(numeros))
+	for i, n := range numeros {
+		textos[i] = numeroPDF(n)
+	}
+	return strings.Join(textos, " ")
+}
+
+// Anchos de los caracteres 32 a 126 de Helvetica y Helvetica-Bold (métricas AFM, en milésimas del tamaño)
+var anchosHelvetica = [95]int{
+	278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+	1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+	333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+	556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584,
+}
+
+var anchosHelveticaNegrita = [95]int{
+	278, 333, 474, 556, 556, 889, 722, 238, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 333, 333, 584, 584, 584, 611,
+	975, 722, 722, 722, 722, 667, 611, 778, 722, 278, 556, 722, 611, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 333, 278, 333, 584, 556,
+	333, 556, 611, 556, 611, 556, 333, 611, 611, 278, 278, 556, 278, 889, 611, 611,
+	611, 611, 389, 556, 333, 611, 556, 778, 556, 556, 500, 389, 280, 389, 584,
+}
@@ -0,0 +1,151 @@
+package utils
+
+import (
+	"html"
+	"strings"
+	"unicode"
+)
+
+// Equivalencias para quitar tildes y diéresis del español
+var equivalenciasTildes = map[rune]rune{
+	'á': 'a', 'à': 'a', 'ä': 'a', 'â': 'a',
+	'é': 'e', 'è': 'e', 'ë': 'e', 'ê': 'e',
+	'í': 'i', 'ì': 'i', 'ï': 'i', 'î': 'i',
+	'ó': 'o', 'ò': 'o', 'ö': 'o', 'ô': 'o',
+	'ú': 'u', 'ù': 'u', 'ü': 'u', 'û': 'u',
+	'ñ': 'n', 'ç': 'c',
+}
+
+// Convertir una letra a minúscula y sin tilde. Siempre devuelve una sola letra
+// para que las posiciones del texto normalizado coincidan con las del original
+func normalizarLetra(r rune) rune {
+	r = unicode.ToLower(r)
+	if equivalente, ok := equivalenciasTildes[r]; ok {
+		return equivalente
+	}
+	return r
+}
+
+// Función para normalizar un texto en minúsculas y sin tildes ("Mediterránea" -> "mediterranea")
+func NormalizarTexto(texto string) string {
+	return strings.Map(normalizarLetra, texto)
+}
+
+// Función para separar un texto en palabras normalizadas, descartando signos de puntuación
+func PalabrasNormalizadas(texto string) []string {
+	return strings.FieldsFunc(NormalizarTexto(texto), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+}
+
+// Función para generar el slug de un texto para URLs: minúsculas, sin tildes y con guiones
+// en lugar de espacios y signos ("Casa Mediterránea 2 Pisos" -> "casa-mediterranea-2-pisos").
+// Se limita a largoMaximo caracteres sin dejar un guion al final.
+func GenerarSlug(texto string, largoMaximo int) string {
+	var slug strings.Builder
+	guion := false
+	for _, r := range NormalizarTexto(texto) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			if guion && slug.Len() > 0 {
+				slug.WriteByte('-')
+			}
+			slug.WriteRune(r)
+			guion = false
+		} else {
+			guion = true
+		}
+	}
+
+	resultado := slug.String()
+	if largoMaximo > 0 && len(resultado) > largoMaximo {
+		resultado = strings.TrimRight(resultado[:largoMaximo], "-")
+	}
+	return resultado
+}
+
+// Función para resaltar con <mark> las palabras del texto que comienzan con alguno de los términos
+// (ya normalizados), sin distinguir mayúsculas ni tildes. El texto se escapa como HTML.
+// Si largo > 0 y el texto lo supera, se devuelve un fragmento de ese largo en torno a la primera coincidencia.
+// Devuelve false si no hubo coincidencias.
+func ResaltarCoincidencias(texto string, terminos []string, largo int) (string, bool) {
+	original := []rune(texto)
+	normalizado := make([]rune, len(original))
+	for i, r := range original {
+		normalizado[i] = normalizarLetra(r)
+	}
+
+	// Buscar las coincidencias al inicio de cada palabra
+	type coincidencia struct{ inicio, fin int }
+	var coincidencias []coincidencia
+	for i := 0; i < len(normalizado); i++ {
+		if !esLetraONumero(normalizado[i]) || (i > 0 && esLetraONumero(normalizado[i-1])) {
+			continue
+		}
+		fin := 0
+		for _, termino := range terminos {
+			t := []rune(termino)
+			if len(t) > fin && i+len(t) <= len(normalizado) && string(normalizado[i:i+len(t)]) == termino {
+				fin = len(t)
+			}
+		}
+		if fin > 0 {
+			// Marcar la palabra completa aunque el término sea solo su inicio
+			fin += i
+			for fin < len(normalizado) && esLetraONumero(normalizado[fin]) {
+				fin++
+			}
+			coincidencias = append(coincidencias, coincidencia{i, fin})
+			i = fin - 1
+		}
+	}
+	if len(coincidencias) == 0 {
+		return "", false
+	}
+
+	// Recortar el texto en torno a la primera coincidencia, sin cortar palabras
+	desde, hasta := 0, len(original)
+	if largo > 0 && len(original) > largo {
+		desde = coincidencias[0].inicio - largo/4
+		if desde < 0 {
+			desde = 0
+		}
+		hasta = desde + largo
+		if hasta > len(original) {
+			hasta = len(original)
+			desde = hasta - largo
+		}
+		for desde > 0 && esLetraONumero(normalizado[desde-1]) && desde < coincidencias[0].inicio {
+			desde++
+		}
+		for hasta < len(original) && hasta > coincidencias[0].fin && esLetraONumero(normalizado[hasta-1]) && esLetraONumero(normalizado[hasta]) {
+			hasta--
+		}
+	}
+
+	var fragmento strings.Builder
+	posicion := desde
+	for _, co := range coincidencias {
+		if co.inicio < desde || co.fin > hasta {
+			continue
+		}
+		fragmento.WriteString(html.EscapeString(string(original[posicion:co.inicio])))
+		fragmento.WriteString("<mark>")
+		fragmento.WriteString(html.EscapeString(string(original[co.inicio:co.fin])))
+		fragmento.WriteString("</mark>")
+		posicion = co.fin
+	}
+	fragmento.WriteString(html.EscapeString(string(original[posicion:hasta])))
+
+	resultado := strings.TrimSpace(fragmento.String())
+	if desde > 0 {
+		resultado = "…" + resultado
+	}
+	if hasta < len(original) {
+		resultado += "…"
+	}
+	return resultado, true
+}
+
+func esLetraONumero(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsNumber(r)
+}